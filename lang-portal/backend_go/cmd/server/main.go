@@ -1,76 +1,293 @@
 package main
 
 import (
-	"database/sql"
-	"log"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	_ "github.com/mattn/go-sqlite3"
+	"lang-portal/backend_go/internal/config"
 	"lang-portal/backend_go/internal/handlers"
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository/sqlstore"
+	"lang-portal/backend_go/internal/service"
 )
 
 func main() {
+	cfg, flags, err := config.Load(os.Args[0], os.Args[1:], os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if flags.PrintConfig {
+		if err := cfg.Write(os.Stdout); err != nil {
+			fatal("Failed to print configuration", err)
+		}
+		return
+	}
+
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: cfg.Level()})))
+	if cfg.Level() <= slog.LevelDebug {
+		gin.SetMode(gin.DebugMode)
+	} else {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
 	// Connect to database
-	db, err := sql.Open("sqlite3", "words.db")
+	db, store, err := sqlstore.Open(cfg.Database.Driver, cfg.Database.DSN)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		fatal("Failed to connect to database", err)
 	}
 	defer db.Close()
 
-	// Initialize router
-	r := gin.Default()
+	if err := db.Ping(); err != nil {
+		fatal("Failed to connect to database", err)
+	}
 
-	// CORS middleware
-	r.Use(func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+	// Commands that run instead of the server
+	if len(flags.Args) > 0 {
+		if flags.Args[0] != "migrate" {
+			fmt.Fprintf(os.Stderr, "unknown command %q\n%v\n", flags.Args[0], errUsage)
+			os.Exit(2)
+		}
+		err := runMigrate(context.Background(), store, flags.Args[1:], os.Stdout)
+		if errors.Is(err, errUsage) {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		if err != nil {
+			fatal("Migration failed", err)
+		}
+		return
+	}
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
+	if cfg.Database.MigrateOnStart {
+		applied, err := store.Migrate(context.Background())
+		if err != nil {
+			fatal("Migration failed", err)
 		}
+		slog.Info("Applied migrations", "count", len(applied))
+	}
+
+	svc := service.New(store)
+	svc.Auth.TokenTTL = time.Duration(cfg.Auth.TokenTTL)
+	svc.Auth.LaunchTokenTTL = time.Duration(cfg.Auth.LaunchTokenTTL)
+	if cfg.Auth.LaunchSecret != "" {
+		svc.Auth.LaunchKey = []byte(cfg.Auth.LaunchSecret)
+	}
+	svc.XAPI.BaseIRI = cfg.XAPI.BaseIRI
+
+	if cfg.Activities.ManifestDir != "" {
+		activities, err := svc.Activities.LoadManifests(context.Background(), cfg.Activities.ManifestDir)
+		if err != nil {
+			fatal("Failed to load activity manifests", err)
+		}
+		slog.Info("Loaded activity manifests", "dir", cfg.Activities.ManifestDir, "count", len(activities))
+	}
+
+	// Stop on Ctrl-C or when the process supervisor asks
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Close study sessions that have been idle for too long
+	go closeIdleSessions(ctx, svc.Sessions, time.Duration(cfg.Sessions.IdleTimeout))
 
-		c.Next()
-	})
+	// Initialize router
+	r := gin.New()
+	r.Use(gin.Recovery())
+	if cfg.Level() <= slog.LevelInfo {
+		r.Use(gin.Logger())
+	}
+	r.Use(handlers.CORS(cfg.Server.CORSAllowedOrigins))
+
+	// Probes for the process supervisor
+	r.GET("/healthz", handlers.Healthz())
+	r.GET("/readyz", handlers.Readyz(func(ctx context.Context) error {
+		return store.Ready(ctx)
+	}))
 
 	// API routes
+	pages := handlers.PageLimits{Default: cfg.API.PageSize, Max: cfg.API.MaxPageSize}
+	r.POST("/api/auth/login", handlers.Login(svc.Auth))
+
 	api := r.Group("/api")
+	api.Use(handlers.Authenticate(svc.Auth))
 	{
+		api.POST("/auth/logout", handlers.Logout(svc.Auth))
+
+		// User endpoints
+		api.GET("/users/me", handlers.GetCurrentUser())
+
 		// Dashboard endpoints
-		api.GET("/dashboard/last-study-session", handlers.GetLastStudySession(db))
-		api.GET("/dashboard/study-progress", handlers.GetStudyProgress(db))
-		api.GET("/dashboard/quick-stats", handlers.GetQuickStats(db))
+		api.GET("/dashboard/last-study-session", handlers.GetLastStudySession(svc.Dashboard))
+		api.GET("/dashboard/study-progress", handlers.GetStudyProgress(svc.Dashboard))
+		api.GET("/dashboard/quick-stats", handlers.GetQuickStats(svc.Dashboard))
 
 		// Study activities endpoints
-		api.GET("/study-activities", handlers.GetStudyActivities(db))
-		api.GET("/study-activity/:id", handlers.GetStudyActivity(db))
-		api.GET("/study-activity/:id/study-sessions", handlers.GetStudyActivitySessions(db))
-		api.POST("/study-activities", handlers.CreateStudyActivity(db))
+		api.GET("/study-activities", handlers.GetStudyActivities(svc.Activities))
+		api.GET("/study-activity/:id", handlers.GetStudyActivity(svc.Activities))
+		api.GET("/study-activity/:id/study-sessions", handlers.GetStudyActivitySessions(svc.Activities, pages))
+		api.POST("/study-activities/:id/launch", handlers.LaunchStudyActivity(svc.Activities))
 
 		// Words endpoints
-		api.GET("/words", handlers.GetWords(db))
-		api.GET("/words/:id", handlers.GetWord(db))
+		api.GET("/words", handlers.GetWords(svc.Words, pages))
+		api.GET("/words/:id", handlers.GetWord(svc.Words))
+		api.GET("/words/:id/reviews", handlers.GetWordReviews(svc.Words, pages))
+
+		// Kanji endpoints
+		api.GET("/kanji/:char", handlers.GetKanji(svc.Kanji))
 
 		// Groups endpoints
-		api.GET("/groups", handlers.GetGroups(db))
-		api.GET("/groups/:id", handlers.GetGroup(db))
-		api.GET("/groups/:id/words", handlers.GetGroupWords(db))
-		api.GET("/groups/:id/study-sessions", handlers.GetGroupStudySessions(db))
+		api.GET("/groups", handlers.GetGroups(svc.Groups, pages))
+		api.GET("/groups/:id", handlers.GetGroup(svc.Groups))
+		api.GET("/groups/:id/study-sessions", handlers.GetGroupStudySessions(svc.Groups, pages))
+		api.GET("/groups/:id/export", handlers.ExportGroup(svc.Groups, svc.Words))
+
+		// Export endpoints
+		api.GET("/export", handlers.ExportLibrary(svc.Words))
 
 		// Study sessions endpoints
-		api.GET("/study-sessions", handlers.GetStudySessions(db))
-		api.GET("/study-sessions/:id", handlers.GetStudySession(db))
-		api.GET("/study-sessions/:id/words", handlers.GetStudySessionWords(db))
-		api.POST("/study-sessions/:id/words/:word_id/review", handlers.CreateWordReview(db))
+		api.GET("/study-sessions", handlers.GetStudySessions(svc.Sessions, pages))
+		api.POST("/study-sessions", handlers.StartStudySession(svc.Sessions))
+		api.GET("/study-sessions/:id", handlers.GetStudySession(svc.Sessions))
+		api.GET("/study-sessions/:id/words", handlers.GetStudySessionWords(svc.Sessions, pages))
+		api.POST("/study-sessions/:id/end", handlers.EndStudySession(svc.Sessions))
+
+		// Review scheduling endpoints
+		api.GET("/review/due", handlers.GetDueWords(svc.Reviews))
+	}
+
+	// Activity apps may also call these with the launch token of a session
+	launched := r.Group("/api")
+	{
+		launched.GET("/groups/:id/words", handlers.AuthenticateLaunch(svc.Auth, handlers.GroupScope), handlers.GetGroupWords(svc.Groups, pages))
+		launched.POST("/study-sessions/:id/words/:word_id/review", handlers.AuthenticateLaunch(svc.Auth, handlers.SessionScope), handlers.CreateWordReview(svc.Reviews))
+		launched.POST("/study-sessions/:id/words/:word_id/answer", handlers.AuthenticateLaunch(svc.Auth, handlers.SessionScope), handlers.CheckWordAnswer(svc.Reviews))
+	}
+
+	// Vocabulary editing is reserved for teachers
+	teacher := api.Group("", handlers.RequireRole(models.RoleTeacher))
+	{
+		teacher.POST("/words", handlers.CreateWord(svc.Words))
+		teacher.PUT("/words/:id", handlers.UpdateWord(svc.Words))
+		teacher.PATCH("/words/:id", handlers.UpdateWord(svc.Words))
+		teacher.DELETE("/words/:id", handlers.DeleteWord(svc.Words))
+
+		teacher.POST("/groups", handlers.CreateGroup(svc.Groups))
+		teacher.PATCH("/groups/:id", handlers.UpdateGroup(svc.Groups))
+		teacher.DELETE("/groups/:id", handlers.DeleteGroup(svc.Groups))
+		teacher.POST("/groups/:id/words", handlers.AddGroupWords(svc.Groups))
+		teacher.DELETE("/groups/:id/words", handlers.RemoveGroupWords(svc.Groups))
+		teacher.POST("/groups/:id/import", handlers.ImportGroupWords(svc.Groups))
+	}
+
+	admin := api.Group("", handlers.RequireRole(models.RoleAdmin))
+	{
+		// User management endpoints
+		admin.GET("/users", handlers.GetUsers(svc.Users))
+		admin.POST("/users", handlers.CreateUser(svc.Users))
+		admin.PATCH("/users/:id", handlers.UpdateUser(svc.Users))
+
+		// Study activity registry endpoints
+		admin.POST("/study-activities", handlers.CreateStudyActivity(svc.Activities))
+		admin.PATCH("/study-activities/:id", handlers.UpdateStudyActivity(svc.Activities))
+		admin.DELETE("/study-activities/:id", handlers.DeleteStudyActivity(svc.Activities))
 
 		// Settings endpoints
-		api.POST("/settings/reset-history", handlers.ResetHistory(db))
-		api.POST("/settings/full-reset", handlers.FullReset(db))
+		admin.POST("/settings/reset-history", handlers.ResetHistory(svc.Settings, svc.Users))
+		admin.POST("/settings/full-reset", handlers.FullReset(svc.Settings))
+	}
+
+	// xAPI Learning Record Store
+	r.GET("/xapi/about", handlers.GetXAPIAbout())
+	lrs := r.Group("/xapi", handlers.XAPIVersion(), handlers.Authenticate(svc.Auth))
+	{
+		lrs.POST("/statements", handlers.PostStatements(svc.XAPI))
+		lrs.PUT("/statements", handlers.PutStatement(svc.XAPI))
+		lrs.GET("/statements", handlers.GetStatements(svc.XAPI, pages))
+
+		lrs.GET("/activities/state", handlers.GetActivityState(svc.XAPI))
+		lrs.PUT("/activities/state", handlers.SaveActivityState(svc.XAPI, false))
+		lrs.POST("/activities/state", handlers.SaveActivityState(svc.XAPI, true))
+		lrs.DELETE("/activities/state", handlers.DeleteActivityState(svc.XAPI))
 	}
 
 	// Start server
-	if err := r.Run(":4000"); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	srv := &http.Server{
+		Addr:              cfg.Server.Addr,
+		Handler:           r,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("Starting server", "addr", cfg.Server.Addr, "tls", cfg.TLS())
+		if cfg.TLS() {
+			serverErr <- srv.ListenAndServeTLS(cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile)
+		} else {
+			serverErr <- srv.ListenAndServe()
+		}
+	}()
+
+	select {
+	case err := <-serverErr:
+		fatal("Failed to start server", err)
+	case <-ctx.Done():
+	}
+
+	// Stop accepting connections and let in-flight requests finish before
+	// the database is closed
+	stop()
+	slog.Info("Shutting down", "timeout", time.Duration(cfg.Server.ShutdownTimeout))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.ShutdownTimeout))
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Failed to finish in-flight requests", "error", err)
+	}
+	slog.Info("Server stopped")
+}
+
+// fatal logs err and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// closeIdleSessions periodically marks sessions without activity for longer
+// than timeout as abandoned, until ctx is done.
+func closeIdleSessions(ctx context.Context, sessions *service.SessionService, timeout time.Duration) {
+	interval := time.Minute
+	if timeout < 2*interval {
+		interval = timeout / 2
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		closed, err := sessions.CloseIdle(ctx, timeout, time.Now())
+		if err != nil {
+			slog.Error("Failed to close idle study sessions", "error", err)
+			continue
+		}
+		if closed > 0 {
+			slog.Info("Closed idle study sessions", "count", closed)
+		}
 	}
-}
\ No newline at end of file
+}
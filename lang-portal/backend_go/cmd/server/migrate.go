@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"lang-portal/backend_go/internal/repository/sqlstore"
+)
+
+// errUsage reports a malformed migrate command line.
+var errUsage = errors.New("usage: server [flags] migrate [up | down [steps] | status]")
+
+// runMigrate implements the migrate subcommand. Without arguments it
+// applies the pending migrations.
+func runMigrate(ctx context.Context, store *sqlstore.Store, args []string, out io.Writer) error {
+	command := "up"
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	switch {
+	case command == "up" && len(args) == 0:
+		applied, err := store.Migrate(ctx)
+		for _, name := range applied {
+			fmt.Fprintf(out, "Applied %s\n", name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Fprintln(out, "Nothing to apply")
+		}
+		return err
+
+	case command == "down" && len(args) <= 1:
+		steps := 1
+		if len(args) == 1 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid number of steps %q\n%w", args[0], errUsage)
+			}
+			steps = n
+		}
+
+		reverted, err := store.Rollback(ctx, steps)
+		for _, name := range reverted {
+			fmt.Fprintf(out, "Reverted %s\n", name)
+		}
+		if err == nil && len(reverted) == 0 {
+			fmt.Fprintln(out, "Nothing to revert")
+		}
+		return err
+
+	case command == "status" && len(args) == 0:
+		status, err := store.MigrationStatus(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "MIGRATION\tAPPLIED AT")
+		for _, m := range status {
+			appliedAt := "pending"
+			if m.Applied {
+				appliedAt = m.AppliedAt.UTC().Format(time.DateTime)
+			}
+			fmt.Fprintf(w, "%s\t%s\n", m.Name, appliedAt)
+		}
+		return w.Flush()
+	}
+
+	return errUsage
+}
@@ -0,0 +1 @@
+-- Nothing to revert, foreign key enforcement is a per-connection setting
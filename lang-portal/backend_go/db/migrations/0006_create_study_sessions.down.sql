@@ -0,0 +1 @@
+DROP TABLE study_sessions;
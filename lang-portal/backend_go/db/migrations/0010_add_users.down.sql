@@ -0,0 +1,31 @@
+-- Only the default user's history can be kept without accounts
+DELETE FROM word_review_items WHERE user_id <> 1;
+DELETE FROM study_sessions WHERE user_id <> 1;
+
+CREATE TABLE word_schedules_old (
+    word_id INTEGER PRIMARY KEY,
+    ease_factor REAL NOT NULL DEFAULT 2.5,
+    interval_days INTEGER NOT NULL DEFAULT 0,
+    repetitions INTEGER NOT NULL DEFAULT 0,
+    due_at DATETIME NOT NULL,
+    last_reviewed_at DATETIME NOT NULL,
+    FOREIGN KEY (word_id) REFERENCES words(id) ON DELETE CASCADE
+);
+
+INSERT INTO word_schedules_old (word_id, ease_factor, interval_days, repetitions, due_at, last_reviewed_at)
+SELECT word_id, ease_factor, interval_days, repetitions, due_at, last_reviewed_at
+FROM word_schedules
+WHERE user_id = 1;
+
+DROP TABLE word_schedules;
+ALTER TABLE word_schedules_old RENAME TO word_schedules;
+
+CREATE INDEX idx_word_schedules_due_at ON word_schedules(due_at);
+
+DROP INDEX idx_study_sessions_user_id;
+DROP INDEX idx_word_review_items_user_id;
+
+ALTER TABLE study_sessions DROP COLUMN user_id;
+ALTER TABLE word_review_items DROP COLUMN user_id;
+
+DROP TABLE users;
@@ -0,0 +1,37 @@
+CREATE TABLE users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- History recorded before accounts existed belongs to the default user
+INSERT INTO users (id, name) VALUES (1, 'default');
+
+ALTER TABLE study_sessions ADD COLUMN user_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE word_review_items ADD COLUMN user_id INTEGER NOT NULL DEFAULT 1;
+
+CREATE INDEX idx_study_sessions_user_id ON study_sessions(user_id);
+CREATE INDEX idx_word_review_items_user_id ON word_review_items(user_id);
+
+-- Schedules become per user, SQLite cannot change a primary key in place
+CREATE TABLE word_schedules_new (
+    user_id INTEGER NOT NULL,
+    word_id INTEGER NOT NULL,
+    ease_factor REAL NOT NULL DEFAULT 2.5,
+    interval_days INTEGER NOT NULL DEFAULT 0,
+    repetitions INTEGER NOT NULL DEFAULT 0,
+    due_at DATETIME NOT NULL,
+    last_reviewed_at DATETIME NOT NULL,
+    PRIMARY KEY (user_id, word_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (word_id) REFERENCES words(id) ON DELETE CASCADE
+);
+
+INSERT INTO word_schedules_new (user_id, word_id, ease_factor, interval_days, repetitions, due_at, last_reviewed_at)
+SELECT 1, word_id, ease_factor, interval_days, repetitions, due_at, last_reviewed_at
+FROM word_schedules;
+
+DROP TABLE word_schedules;
+ALTER TABLE word_schedules_new RENAME TO word_schedules;
+
+CREATE INDEX idx_word_schedules_due_at ON word_schedules(user_id, due_at);
@@ -0,0 +1,15 @@
+ALTER TABLE users ADD COLUMN password_hash TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'student';
+
+-- The default user ran the portal before accounts existed
+UPDATE users SET role = 'admin' WHERE id = 1;
+
+CREATE TABLE auth_tokens (
+    hash TEXT PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    expires_at DATETIME NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_auth_tokens_expires_at ON auth_tokens(expires_at);
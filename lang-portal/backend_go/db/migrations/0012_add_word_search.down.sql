@@ -0,0 +1,4 @@
+DROP TRIGGER word_search_delete;
+DROP TRIGGER word_search_update;
+DROP TRIGGER word_search_insert;
+DROP TABLE word_search;
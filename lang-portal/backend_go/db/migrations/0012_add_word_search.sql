@@ -0,0 +1,27 @@
+-- Full-text index over the search keys of each word, see internal/textnorm.
+-- The search_* functions are registered by the store's SQLite driver.
+CREATE VIRTUAL TABLE word_search USING fts4(japanese, romaji, english, tokenize=simple);
+
+INSERT INTO word_search (docid, japanese, romaji, english)
+SELECT id, search_japanese(japanese), search_romaji(romaji), search_english(english)
+FROM words;
+
+CREATE TRIGGER word_search_insert AFTER INSERT ON words
+BEGIN
+    INSERT INTO word_search (docid, japanese, romaji, english)
+    VALUES (new.id, search_japanese(new.japanese), search_romaji(new.romaji), search_english(new.english));
+END;
+
+CREATE TRIGGER word_search_update AFTER UPDATE OF japanese, romaji, english ON words
+BEGIN
+    UPDATE word_search
+    SET japanese = search_japanese(new.japanese),
+        romaji = search_romaji(new.romaji),
+        english = search_english(new.english)
+    WHERE docid = old.id;
+END;
+
+CREATE TRIGGER word_search_delete AFTER DELETE ON words
+BEGIN
+    DELETE FROM word_search WHERE docid = old.id;
+END;
@@ -0,0 +1,4 @@
+-- Cursor pagination walks the history in (created_at, id) order
+CREATE INDEX idx_study_sessions_user_created_at ON study_sessions(user_id, created_at, id);
+CREATE INDEX idx_word_review_items_session_created_at ON word_review_items(study_session_id, created_at, id);
+CREATE INDEX idx_word_review_items_user_word_created_at ON word_review_items(user_id, word_id, created_at, id);
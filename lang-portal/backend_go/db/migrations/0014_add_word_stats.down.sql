@@ -0,0 +1,4 @@
+DROP TRIGGER word_stats_update;
+DROP TRIGGER word_stats_delete;
+DROP TRIGGER word_stats_insert;
+DROP TABLE word_stats;
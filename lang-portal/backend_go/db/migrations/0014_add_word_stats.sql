@@ -0,0 +1,90 @@
+-- Each user's review counts per word, kept up to date by triggers so that
+-- word lists need not count every review
+CREATE TABLE word_stats (
+    user_id INTEGER NOT NULL,
+    word_id INTEGER NOT NULL,
+    correct_count INTEGER NOT NULL DEFAULT 0,
+    wrong_count INTEGER NOT NULL DEFAULT 0,
+    last_reviewed_at DATETIME,
+    PRIMARY KEY (user_id, word_id),
+    FOREIGN KEY (word_id) REFERENCES words(id) ON DELETE CASCADE
+);
+
+INSERT INTO word_stats (user_id, word_id, correct_count, wrong_count, last_reviewed_at)
+SELECT
+    user_id,
+    word_id,
+    SUM(CASE WHEN correct THEN 1 ELSE 0 END),
+    SUM(CASE WHEN correct THEN 0 ELSE 1 END),
+    MAX(created_at)
+FROM word_review_items
+GROUP BY user_id, word_id;
+
+CREATE TRIGGER word_stats_insert AFTER INSERT ON word_review_items
+BEGIN
+    INSERT INTO word_stats (user_id, word_id, correct_count, wrong_count, last_reviewed_at)
+    VALUES (
+        new.user_id,
+        new.word_id,
+        CASE WHEN new.correct THEN 1 ELSE 0 END,
+        CASE WHEN new.correct THEN 0 ELSE 1 END,
+        new.created_at
+    )
+    ON CONFLICT (user_id, word_id) DO UPDATE
+    SET correct_count = correct_count + excluded.correct_count,
+        wrong_count = wrong_count + excluded.wrong_count,
+        last_reviewed_at = (
+            SELECT MAX(created_at) FROM word_review_items
+            WHERE user_id = new.user_id AND word_id = new.word_id
+        );
+END;
+
+-- Removing a review takes it out of the counts and drops stats left empty
+CREATE TRIGGER word_stats_delete AFTER DELETE ON word_review_items
+BEGIN
+    UPDATE word_stats
+    SET correct_count = correct_count - CASE WHEN old.correct THEN 1 ELSE 0 END,
+        wrong_count = wrong_count - CASE WHEN old.correct THEN 0 ELSE 1 END,
+        last_reviewed_at = COALESCE((
+            SELECT MAX(created_at) FROM word_review_items
+            WHERE user_id = old.user_id AND word_id = old.word_id
+        ), last_reviewed_at)
+    WHERE user_id = old.user_id AND word_id = old.word_id;
+
+    DELETE FROM word_stats
+    WHERE user_id = old.user_id AND word_id = old.word_id
+    AND correct_count = 0 AND wrong_count = 0;
+END;
+
+-- A changed review counts as removing the old one and adding the new one
+CREATE TRIGGER word_stats_update AFTER UPDATE OF user_id, word_id, correct, created_at ON word_review_items
+BEGIN
+    UPDATE word_stats
+    SET correct_count = correct_count - CASE WHEN old.correct THEN 1 ELSE 0 END,
+        wrong_count = wrong_count - CASE WHEN old.correct THEN 0 ELSE 1 END,
+        last_reviewed_at = COALESCE((
+            SELECT MAX(created_at) FROM word_review_items
+            WHERE user_id = old.user_id AND word_id = old.word_id
+        ), last_reviewed_at)
+    WHERE user_id = old.user_id AND word_id = old.word_id;
+
+    DELETE FROM word_stats
+    WHERE user_id = old.user_id AND word_id = old.word_id
+    AND correct_count = 0 AND wrong_count = 0;
+
+    INSERT INTO word_stats (user_id, word_id, correct_count, wrong_count, last_reviewed_at)
+    VALUES (
+        new.user_id,
+        new.word_id,
+        CASE WHEN new.correct THEN 1 ELSE 0 END,
+        CASE WHEN new.correct THEN 0 ELSE 1 END,
+        new.created_at
+    )
+    ON CONFLICT (user_id, word_id) DO UPDATE
+    SET correct_count = correct_count + excluded.correct_count,
+        wrong_count = wrong_count + excluded.wrong_count,
+        last_reviewed_at = (
+            SELECT MAX(created_at) FROM word_review_items
+            WHERE user_id = new.user_id AND word_id = new.word_id
+        );
+END;
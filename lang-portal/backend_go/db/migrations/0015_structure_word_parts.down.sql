@@ -0,0 +1,5 @@
+-- Words that only have segments go back to the vocabulary importer's array.
+-- The other fields keep their new shape, which older code reads as well.
+UPDATE words SET parts = json_extract(parts, '$.segments')
+WHERE json_type(parts, '$.segments') = 'array'
+AND (SELECT COUNT(*) FROM json_each(words.parts)) = 1;
@@ -0,0 +1,34 @@
+-- Rewrite every word's parts in the shape of models.Parts: an object with
+-- optional type, formality, category, value and segments. Malformed parts
+-- become an empty object.
+UPDATE words SET parts = '{}'
+WHERE NOT json_valid(parts) OR json_type(parts) NOT IN ('object', 'array');
+
+-- The vocabulary importer stored its segments as a bare array
+UPDATE words SET parts = json_object('segments', json(parts))
+WHERE json_type(parts) = 'array';
+
+-- Keep the known fields of the right type, taking formality from the seed
+-- files' politeness and joining readings split into several romaji. Null
+-- members are dropped by json_patch.
+UPDATE words SET parts = json_patch('{}', json_object(
+    'type', CASE json_type(parts, '$.type') WHEN 'text' THEN json_extract(parts, '$.type') END,
+    'formality', CASE
+        WHEN json_type(parts, '$.formality') = 'text' THEN json_extract(parts, '$.formality')
+        WHEN json_type(parts, '$.politeness') = 'text' THEN json_extract(parts, '$.politeness')
+    END,
+    'category', CASE json_type(parts, '$.category') WHEN 'text' THEN json_extract(parts, '$.category') END,
+    'value', CASE json_type(parts, '$.value') WHEN 'integer' THEN json_extract(parts, '$.value') END,
+    'segments', CASE WHEN json_type(parts, '$.segments') = 'array' AND json_array_length(parts, '$.segments') > 0 THEN json((
+        SELECT json_group_array(json_object(
+            'kanji', CASE json_type(s.value, '$.kanji') WHEN 'text' THEN json_extract(s.value, '$.kanji') ELSE '' END,
+            'romaji', CASE json_type(s.value, '$.romaji')
+                WHEN 'text' THEN json_extract(s.value, '$.romaji')
+                WHEN 'array' THEN (SELECT group_concat(r.value, '') FROM json_each(s.value, '$.romaji') r)
+                ELSE ''
+            END
+        ))
+        FROM json_each(parts, '$.segments') s
+        WHERE s.type = 'object'
+    )) END
+));
@@ -0,0 +1,6 @@
+DROP TRIGGER word_kanji_kanji_update;
+DROP TRIGGER word_kanji_kanji_insert;
+DROP TRIGGER word_kanji_word_update;
+DROP TRIGGER word_kanji_word_insert;
+DROP TABLE word_kanji;
+DROP TABLE kanji;
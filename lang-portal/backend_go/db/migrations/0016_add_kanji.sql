@@ -0,0 +1,48 @@
+-- Kanji with their readings and meanings, the lists stored as JSON arrays
+CREATE TABLE kanji (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    literal TEXT NOT NULL UNIQUE,
+    on_readings TEXT NOT NULL DEFAULT '[]',
+    kun_readings TEXT NOT NULL DEFAULT '[]',
+    meanings TEXT NOT NULL DEFAULT '[]',
+    stroke_count INTEGER NOT NULL,
+    jlpt_level INTEGER
+);
+
+-- The kanji each word is written with, kept up to date by triggers as words
+-- and kanji are stored
+CREATE TABLE word_kanji (
+    word_id INTEGER NOT NULL,
+    kanji_id INTEGER NOT NULL,
+    PRIMARY KEY (word_id, kanji_id),
+    FOREIGN KEY (word_id) REFERENCES words(id) ON DELETE CASCADE,
+    FOREIGN KEY (kanji_id) REFERENCES kanji(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_word_kanji_kanji_id ON word_kanji(kanji_id);
+
+CREATE TRIGGER word_kanji_word_insert AFTER INSERT ON words
+BEGIN
+    INSERT INTO word_kanji (word_id, kanji_id)
+    SELECT new.id, id FROM kanji WHERE instr(new.japanese, literal) > 0;
+END;
+
+CREATE TRIGGER word_kanji_word_update AFTER UPDATE OF japanese ON words
+BEGIN
+    DELETE FROM word_kanji WHERE word_id = old.id;
+    INSERT INTO word_kanji (word_id, kanji_id)
+    SELECT new.id, id FROM kanji WHERE instr(new.japanese, literal) > 0;
+END;
+
+CREATE TRIGGER word_kanji_kanji_insert AFTER INSERT ON kanji
+BEGIN
+    INSERT INTO word_kanji (word_id, kanji_id)
+    SELECT id, new.id FROM words WHERE instr(japanese, new.literal) > 0;
+END;
+
+CREATE TRIGGER word_kanji_kanji_update AFTER UPDATE OF literal ON kanji
+BEGIN
+    DELETE FROM word_kanji WHERE kanji_id = old.id;
+    INSERT INTO word_kanji (word_id, kanji_id)
+    SELECT id, new.id FROM words WHERE instr(japanese, new.literal) > 0;
+END;
@@ -0,0 +1,31 @@
+-- Statements received or emitted by the xAPI Learning Record Store. The
+-- statement is kept as JSON, with the fields it is queried by alongside.
+CREATE TABLE xapi_statements (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    statement_id TEXT NOT NULL UNIQUE,
+    user_id INTEGER NOT NULL,
+    actor_key TEXT NOT NULL,
+    verb_id TEXT NOT NULL,
+    object_id TEXT NOT NULL,
+    registration TEXT NOT NULL DEFAULT '',
+    voided BOOLEAN NOT NULL DEFAULT FALSE,
+    stored DATETIME NOT NULL,
+    statement TEXT NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_xapi_statements_user_id_stored ON xapi_statements(user_id, stored);
+
+-- Documents activities keep per agent, activity and registration
+CREATE TABLE xapi_states (
+    user_id INTEGER NOT NULL,
+    activity_id TEXT NOT NULL,
+    agent_key TEXT NOT NULL,
+    registration TEXT NOT NULL DEFAULT '',
+    state_id TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    document BLOB NOT NULL,
+    updated_at DATETIME NOT NULL,
+    PRIMARY KEY (user_id, activity_id, agent_key, registration, state_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1 @@
+ALTER TABLE study_activities DROP COLUMN launch_url;
@@ -0,0 +1,5 @@
+ALTER TABLE word_review_items DROP COLUMN hints_used;
+ALTER TABLE word_review_items DROP COLUMN direction;
+ALTER TABLE word_review_items DROP COLUMN quality;
+ALTER TABLE word_review_items DROP COLUMN response_ms;
+ALTER TABLE word_review_items DROP COLUMN answer_given;
@@ -0,0 +1,10 @@
+-- What the learner answered and how: the answer itself, how long it took,
+-- the SM-2 grade, which way the word was asked and the hints shown. Earlier
+-- reviews get the grade their correct flag stands for.
+ALTER TABLE word_review_items ADD COLUMN answer_given TEXT NOT NULL DEFAULT '';
+ALTER TABLE word_review_items ADD COLUMN response_ms INTEGER;
+ALTER TABLE word_review_items ADD COLUMN quality INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE word_review_items ADD COLUMN direction TEXT NOT NULL DEFAULT '';
+ALTER TABLE word_review_items ADD COLUMN hints_used INTEGER NOT NULL DEFAULT 0;
+
+UPDATE word_review_items SET quality = CASE WHEN correct THEN 4 ELSE 1 END;
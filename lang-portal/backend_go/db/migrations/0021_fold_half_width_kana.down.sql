@@ -0,0 +1,7 @@
+-- The search_* functions are the server's, so the keys are rebuilt with
+-- whatever they fold.
+DELETE FROM word_search;
+
+INSERT INTO word_search (docid, japanese, romaji, english)
+SELECT id, search_japanese(japanese), search_romaji(romaji), search_english(english)
+FROM words;
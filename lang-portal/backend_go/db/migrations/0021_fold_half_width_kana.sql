@@ -0,0 +1,8 @@
+-- Half-width katakana fold into hiragana like full-width katakana, see
+-- internal/textnorm. The search_* functions registered by the store's SQLite
+-- driver fold them already, the keys stored before are rebuilt.
+DELETE FROM word_search;
+
+INSERT INTO word_search (docid, japanese, romaji, english)
+SELECT id, search_japanese(japanese), search_romaji(romaji), search_english(english)
+FROM words;
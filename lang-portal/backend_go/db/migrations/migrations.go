@@ -0,0 +1,10 @@
+// Package migrations embeds the schema migrations so that the server binary
+// can apply them without the source tree. SQLite migrations sit at the top
+// level and PostgreSQL ones in postgres/. A migration NNNN_name.sql may be
+// reverted by an NNNN_name.down.sql next to it.
+package migrations
+
+import "embed"
+
+//go:embed *.sql postgres/*.sql
+var FS embed.FS
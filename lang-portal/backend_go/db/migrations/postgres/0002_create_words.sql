@@ -0,0 +1,7 @@
+CREATE TABLE words (
+    id SERIAL PRIMARY KEY,
+    japanese TEXT NOT NULL,
+    romaji TEXT NOT NULL,
+    english TEXT NOT NULL,
+    parts TEXT NOT NULL -- This will store JSON data
+);
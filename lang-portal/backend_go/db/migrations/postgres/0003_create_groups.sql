@@ -0,0 +1,4 @@
+CREATE TABLE groups (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE
+);
@@ -0,0 +1 @@
+DROP TABLE word_groups;
@@ -0,0 +1,8 @@
+CREATE TABLE word_groups (
+    id SERIAL PRIMARY KEY,
+    word_id INTEGER NOT NULL,
+    group_id INTEGER NOT NULL,
+    FOREIGN KEY (word_id) REFERENCES words(id) ON DELETE CASCADE,
+    FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE,
+    UNIQUE(word_id, group_id)
+);
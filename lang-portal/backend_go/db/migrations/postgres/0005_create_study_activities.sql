@@ -0,0 +1,8 @@
+-- Timestamps are stored in UTC without a time zone, like SQLite's CURRENT_TIMESTAMP
+CREATE TABLE study_activities (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    thumbnail_url TEXT,
+    description TEXT,
+    created_at TIMESTAMP DEFAULT (now() AT TIME ZONE 'UTC')
+);
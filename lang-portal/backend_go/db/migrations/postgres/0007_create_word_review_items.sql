@@ -0,0 +1,14 @@
+CREATE TABLE word_review_items (
+    id SERIAL PRIMARY KEY,
+    word_id INTEGER NOT NULL,
+    study_session_id INTEGER NOT NULL,
+    correct BOOLEAN NOT NULL,
+    created_at TIMESTAMP DEFAULT (now() AT TIME ZONE 'UTC'),
+    FOREIGN KEY (word_id) REFERENCES words(id) ON DELETE CASCADE,
+    FOREIGN KEY (study_session_id) REFERENCES study_sessions(id) ON DELETE CASCADE
+);
+
+-- Create indexes for better query performance
+CREATE INDEX idx_word_review_items_word_id ON word_review_items(word_id);
+CREATE INDEX idx_word_review_items_study_session_id ON word_review_items(study_session_id);
+CREATE INDEX idx_word_review_items_created_at ON word_review_items(created_at);
@@ -0,0 +1,4 @@
+DROP INDEX idx_study_sessions_status;
+
+ALTER TABLE study_sessions DROP COLUMN status;
+ALTER TABLE study_sessions DROP COLUMN ended_at;
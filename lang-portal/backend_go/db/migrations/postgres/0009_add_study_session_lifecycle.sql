@@ -0,0 +1,13 @@
+ALTER TABLE study_sessions ADD COLUMN ended_at TIMESTAMP;
+ALTER TABLE study_sessions ADD COLUMN status TEXT NOT NULL DEFAULT 'active';
+
+-- Sessions recorded before sessions could be ended count as completed at their last review
+UPDATE study_sessions
+SET
+    status = 'completed',
+    ended_at = COALESCE(
+        (SELECT MAX(created_at) FROM word_review_items WHERE study_session_id = study_sessions.id),
+        created_at
+    );
+
+CREATE INDEX idx_study_sessions_status ON study_sessions(status);
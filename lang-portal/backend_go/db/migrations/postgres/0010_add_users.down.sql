@@ -0,0 +1,18 @@
+-- Only the default user's history can be kept without accounts
+DELETE FROM word_review_items WHERE user_id <> 1;
+DELETE FROM study_sessions WHERE user_id <> 1;
+DELETE FROM word_schedules WHERE user_id <> 1;
+
+DROP INDEX idx_word_schedules_due_at;
+ALTER TABLE word_schedules DROP CONSTRAINT word_schedules_pkey;
+ALTER TABLE word_schedules DROP COLUMN user_id;
+ALTER TABLE word_schedules ADD PRIMARY KEY (word_id);
+CREATE INDEX idx_word_schedules_due_at ON word_schedules(due_at);
+
+DROP INDEX idx_study_sessions_user_id;
+DROP INDEX idx_word_review_items_user_id;
+
+ALTER TABLE study_sessions DROP COLUMN user_id;
+ALTER TABLE word_review_items DROP COLUMN user_id;
+
+DROP TABLE users;
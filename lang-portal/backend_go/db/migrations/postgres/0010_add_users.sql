@@ -0,0 +1,23 @@
+CREATE TABLE users (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT (now() AT TIME ZONE 'UTC')
+);
+
+-- History recorded before accounts existed belongs to the default user
+INSERT INTO users (id, name) VALUES (1, 'default');
+SELECT setval('users_id_seq', (SELECT MAX(id) FROM users));
+
+ALTER TABLE study_sessions ADD COLUMN user_id INTEGER NOT NULL DEFAULT 1 REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE word_review_items ADD COLUMN user_id INTEGER NOT NULL DEFAULT 1 REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_study_sessions_user_id ON study_sessions(user_id);
+CREATE INDEX idx_word_review_items_user_id ON word_review_items(user_id);
+
+-- Schedules become per user
+ALTER TABLE word_schedules ADD COLUMN user_id INTEGER NOT NULL DEFAULT 1 REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE word_schedules DROP CONSTRAINT word_schedules_pkey;
+ALTER TABLE word_schedules ADD PRIMARY KEY (user_id, word_id);
+
+DROP INDEX idx_word_schedules_due_at;
+CREATE INDEX idx_word_schedules_due_at ON word_schedules(user_id, due_at);
@@ -0,0 +1,4 @@
+DROP TABLE auth_tokens;
+
+ALTER TABLE users DROP COLUMN role;
+ALTER TABLE users DROP COLUMN password_hash;
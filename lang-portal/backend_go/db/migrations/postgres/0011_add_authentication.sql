@@ -0,0 +1,14 @@
+ALTER TABLE users ADD COLUMN password_hash TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'student';
+
+-- The default user ran the portal before accounts existed
+UPDATE users SET role = 'admin' WHERE id = 1;
+
+CREATE TABLE auth_tokens (
+    hash TEXT PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT (now() AT TIME ZONE 'UTC'),
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_auth_tokens_expires_at ON auth_tokens(expires_at);
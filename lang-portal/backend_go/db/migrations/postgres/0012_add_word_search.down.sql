@@ -0,0 +1,8 @@
+DROP TRIGGER word_search_sync ON words;
+DROP FUNCTION word_search_sync();
+DROP TABLE word_search;
+DROP FUNCTION search_english(TEXT);
+DROP FUNCTION search_romaji(TEXT);
+DROP FUNCTION search_japanese(TEXT);
+DROP FUNCTION search_tokens(TEXT);
+DROP FUNCTION search_fold(TEXT);
@@ -0,0 +1,56 @@
+-- Search keys of each word, see internal/textnorm. PostgreSQL cannot call
+-- into Go, so the search_* functions port textnorm.Field.Key to SQL and
+-- TestSearchKeys checks they agree.
+
+-- Full-width ASCII to ASCII, katakana to hiragana, then lower case
+CREATE FUNCTION search_fold(value TEXT) RETURNS TEXT AS $$
+    SELECT lower(translate(value,
+        '！＂＃＄％＆＇（）＊＋，－．／０１２３４５６７８９：；＜＝＞？＠ＡＢＣＤＥＦＧＨＩＪＫＬＭＮＯＰＱＲＳＴＵＶＷＸＹＺ［＼］＾＿｀ａｂｃｄｅｆｇｈｉｊｋｌｍｎｏｐｑｒｓｔｕｖｗｘｙｚ｛｜｝～　ァアィイゥウェエォオカガキギクグケゲコゴサザシジスズセゼソゾタダチヂッツヅテデトドナニヌネノハバパヒビピフブプヘベペホボポマミムメモャヤュユョヨラリルレロヮワヰヱヲンヴヵヶヽヾ',
+        '!"#$%&''()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\]^_`abcdefghijklmnopqrstuvwxyz{|}~ ぁあぃいぅうぇえぉおかがきぎくぐけげこごさざしじすずせぜそぞただちぢっつづてでとどなにぬねのはばぱひびぴふぶぷへべぺほぼぽまみむめもゃやゅゆょよらりるれろゎわゐゑをんゔゕゖゝゞ'))
+$$ LANGUAGE SQL IMMUTABLE;
+
+-- Tokens are runs of ASCII letters and digits or non-ASCII characters
+CREATE FUNCTION search_tokens(value TEXT) RETURNS TEXT AS $$
+    SELECT trim(regexp_replace(value, '[^0-9a-z\u0080-\U0010FFFF]+', ' ', 'g'))
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE FUNCTION search_japanese(value TEXT) RETURNS TEXT AS $$
+    SELECT array_to_string(regexp_split_to_array(
+        regexp_replace(search_fold(value), '[^0-9a-z\u0080-\U0010FFFF]+', '', 'g'), ''), ' ')
+$$ LANGUAGE SQL IMMUTABLE;
+
+-- Long vowels written with a macron or circumflex, doubled or as "ou" become
+-- a single vowel
+CREATE FUNCTION search_romaji(value TEXT) RETURNS TEXT AS $$
+    SELECT search_tokens(regexp_replace(regexp_replace(
+        search_fold(translate(value, 'āīūēōâîûêôĀĪŪĒŌÂÎÛÊÔ', 'aiueoaiueoaiueoaiueo')),
+        '([aiue])\1+', '\1', 'g'), 'o[ou]+', 'o', 'g'))
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE FUNCTION search_english(value TEXT) RETURNS TEXT AS $$
+    SELECT search_tokens(search_fold(value))
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE TABLE word_search (
+    word_id INTEGER PRIMARY KEY REFERENCES words(id) ON DELETE CASCADE,
+    japanese TEXT NOT NULL,
+    romaji TEXT NOT NULL,
+    english TEXT NOT NULL
+);
+
+INSERT INTO word_search (word_id, japanese, romaji, english)
+SELECT id, search_japanese(japanese), search_romaji(romaji), search_english(english)
+FROM words;
+
+CREATE FUNCTION word_search_sync() RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO word_search (word_id, japanese, romaji, english)
+    VALUES (NEW.id, search_japanese(NEW.japanese), search_romaji(NEW.romaji), search_english(NEW.english))
+    ON CONFLICT (word_id) DO UPDATE
+    SET japanese = EXCLUDED.japanese, romaji = EXCLUDED.romaji, english = EXCLUDED.english;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER word_search_sync AFTER INSERT OR UPDATE OF japanese, romaji, english ON words
+FOR EACH ROW EXECUTE FUNCTION word_search_sync();
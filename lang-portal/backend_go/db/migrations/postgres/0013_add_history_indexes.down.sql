@@ -0,0 +1,3 @@
+DROP INDEX idx_study_sessions_user_created_at;
+DROP INDEX idx_word_review_items_session_created_at;
+DROP INDEX idx_word_review_items_user_word_created_at;
@@ -0,0 +1,3 @@
+DROP TRIGGER word_stats_sync ON word_review_items;
+DROP FUNCTION word_stats_sync();
+DROP TABLE word_stats;
@@ -0,0 +1,64 @@
+-- Each user's review counts per word, kept up to date by a trigger so that
+-- word lists need not count every review
+CREATE TABLE word_stats (
+    user_id INTEGER NOT NULL,
+    word_id INTEGER NOT NULL REFERENCES words(id) ON DELETE CASCADE,
+    correct_count INTEGER NOT NULL DEFAULT 0,
+    wrong_count INTEGER NOT NULL DEFAULT 0,
+    last_reviewed_at TIMESTAMP,
+    PRIMARY KEY (user_id, word_id)
+);
+
+INSERT INTO word_stats (user_id, word_id, correct_count, wrong_count, last_reviewed_at)
+SELECT
+    user_id,
+    word_id,
+    COUNT(*) FILTER (WHERE correct),
+    COUNT(*) FILTER (WHERE NOT correct),
+    MAX(created_at)
+FROM word_review_items
+GROUP BY user_id, word_id;
+
+-- A changed review counts as removing the old one and adding the new one.
+-- Stats left empty are dropped.
+CREATE FUNCTION word_stats_sync() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('DELETE', 'UPDATE') THEN
+        UPDATE word_stats
+        SET correct_count = correct_count - CASE WHEN OLD.correct THEN 1 ELSE 0 END,
+            wrong_count = wrong_count - CASE WHEN OLD.correct THEN 0 ELSE 1 END,
+            last_reviewed_at = COALESCE((
+                SELECT MAX(created_at) FROM word_review_items
+                WHERE user_id = OLD.user_id AND word_id = OLD.word_id
+            ), last_reviewed_at)
+        WHERE user_id = OLD.user_id AND word_id = OLD.word_id;
+
+        DELETE FROM word_stats
+        WHERE user_id = OLD.user_id AND word_id = OLD.word_id
+        AND correct_count = 0 AND wrong_count = 0;
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        INSERT INTO word_stats (user_id, word_id, correct_count, wrong_count, last_reviewed_at)
+        VALUES (
+            NEW.user_id,
+            NEW.word_id,
+            CASE WHEN NEW.correct THEN 1 ELSE 0 END,
+            CASE WHEN NEW.correct THEN 0 ELSE 1 END,
+            NEW.created_at
+        )
+        ON CONFLICT (user_id, word_id) DO UPDATE
+        SET correct_count = word_stats.correct_count + EXCLUDED.correct_count,
+            wrong_count = word_stats.wrong_count + EXCLUDED.wrong_count,
+            last_reviewed_at = (
+                SELECT MAX(created_at) FROM word_review_items
+                WHERE user_id = NEW.user_id AND word_id = NEW.word_id
+            );
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER word_stats_sync AFTER INSERT OR DELETE OR UPDATE OF user_id, word_id, correct, created_at ON word_review_items
+FOR EACH ROW EXECUTE FUNCTION word_stats_sync();
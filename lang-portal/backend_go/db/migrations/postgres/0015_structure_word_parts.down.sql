@@ -0,0 +1,5 @@
+-- Words that only have segments go back to the vocabulary importer's array.
+-- The other fields keep their new shape, which older code reads as well.
+UPDATE words SET parts = (parts::jsonb->'segments')::text
+WHERE jsonb_typeof(parts::jsonb->'segments') = 'array'
+AND parts::jsonb - 'segments' = '{}'::jsonb;
@@ -0,0 +1,56 @@
+-- Rewrite every word's parts in the shape of models.Parts: an object with
+-- optional type, formality, category, value and segments. Malformed parts
+-- become an empty object.
+CREATE FUNCTION structure_word_parts(parts TEXT) RETURNS TEXT AS $$
+DECLARE
+    parsed JSONB;
+BEGIN
+    BEGIN
+        parsed := parts::jsonb;
+    EXCEPTION WHEN others THEN
+        RETURN '{}';
+    END;
+
+    -- The vocabulary importer stored its segments as a bare array
+    IF jsonb_typeof(parsed) = 'array' THEN
+        parsed := jsonb_build_object('segments', parsed);
+    ELSIF jsonb_typeof(parsed) IS DISTINCT FROM 'object' THEN
+        RETURN '{}';
+    END IF;
+
+    -- Keep the known fields of the right type, taking formality from the
+    -- seed files' politeness and joining readings split into several romaji
+    RETURN jsonb_strip_nulls(jsonb_build_object(
+        'type', CASE WHEN jsonb_typeof(parsed->'type') = 'string' THEN parsed->'type' END,
+        'formality', CASE
+            WHEN jsonb_typeof(parsed->'formality') = 'string' THEN parsed->'formality'
+            WHEN jsonb_typeof(parsed->'politeness') = 'string' THEN parsed->'politeness'
+        END,
+        'category', CASE WHEN jsonb_typeof(parsed->'category') = 'string' THEN parsed->'category' END,
+        'value', CASE
+            WHEN jsonb_typeof(parsed->'value') = 'number'
+            AND trunc((parsed->>'value')::numeric) = (parsed->>'value')::numeric
+            THEN parsed->'value'
+        END,
+        'segments', CASE WHEN jsonb_typeof(parsed->'segments') = 'array' THEN (
+            SELECT jsonb_agg(jsonb_build_object(
+                'kanji', CASE WHEN jsonb_typeof(s.value->'kanji') = 'string' THEN s.value->>'kanji' ELSE '' END,
+                'romaji', CASE jsonb_typeof(s.value->'romaji')
+                    WHEN 'string' THEN s.value->>'romaji'
+                    WHEN 'array' THEN (
+                        SELECT string_agg(r.value, '' ORDER BY r.n)
+                        FROM jsonb_array_elements_text(s.value->'romaji') WITH ORDINALITY AS r(value, n)
+                    )
+                    ELSE ''
+                END
+            ) ORDER BY s.n)
+            FROM jsonb_array_elements(parsed->'segments') WITH ORDINALITY AS s(value, n)
+            WHERE jsonb_typeof(s.value) = 'object'
+        ) END
+    ))::text;
+END;
+$$ LANGUAGE plpgsql;
+
+UPDATE words SET parts = structure_word_parts(parts);
+
+DROP FUNCTION structure_word_parts(TEXT);
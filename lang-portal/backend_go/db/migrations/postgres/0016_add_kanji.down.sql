@@ -0,0 +1,6 @@
+DROP TRIGGER word_kanji_link_kanji ON kanji;
+DROP FUNCTION word_kanji_link_kanji();
+DROP TRIGGER word_kanji_link_word ON words;
+DROP FUNCTION word_kanji_link_word();
+DROP TABLE word_kanji;
+DROP TABLE kanji;
@@ -0,0 +1,52 @@
+-- Kanji with their readings and meanings, the lists stored as JSON arrays
+CREATE TABLE kanji (
+    id SERIAL PRIMARY KEY,
+    literal TEXT NOT NULL UNIQUE,
+    on_readings TEXT NOT NULL DEFAULT '[]',
+    kun_readings TEXT NOT NULL DEFAULT '[]',
+    meanings TEXT NOT NULL DEFAULT '[]',
+    stroke_count INTEGER NOT NULL,
+    jlpt_level INTEGER
+);
+
+-- The kanji each word is written with, kept up to date by triggers as words
+-- and kanji are stored
+CREATE TABLE word_kanji (
+    word_id INTEGER NOT NULL REFERENCES words(id) ON DELETE CASCADE,
+    kanji_id INTEGER NOT NULL REFERENCES kanji(id) ON DELETE CASCADE,
+    PRIMARY KEY (word_id, kanji_id)
+);
+
+CREATE INDEX idx_word_kanji_kanji_id ON word_kanji(kanji_id);
+
+CREATE FUNCTION word_kanji_link_word() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        DELETE FROM word_kanji WHERE word_id = OLD.id;
+    END IF;
+
+    INSERT INTO word_kanji (word_id, kanji_id)
+    SELECT NEW.id, id FROM kanji WHERE strpos(NEW.japanese, literal) > 0;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER word_kanji_link_word AFTER INSERT OR UPDATE OF japanese ON words
+FOR EACH ROW EXECUTE FUNCTION word_kanji_link_word();
+
+CREATE FUNCTION word_kanji_link_kanji() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        DELETE FROM word_kanji WHERE kanji_id = OLD.id;
+    END IF;
+
+    INSERT INTO word_kanji (word_id, kanji_id)
+    SELECT id, NEW.id FROM words WHERE strpos(japanese, NEW.literal) > 0;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER word_kanji_link_kanji AFTER INSERT OR UPDATE OF literal ON kanji
+FOR EACH ROW EXECUTE FUNCTION word_kanji_link_kanji();
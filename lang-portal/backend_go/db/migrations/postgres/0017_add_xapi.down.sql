@@ -0,0 +1,2 @@
+DROP TABLE xapi_states;
+DROP TABLE xapi_statements;
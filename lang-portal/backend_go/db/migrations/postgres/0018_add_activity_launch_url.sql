@@ -0,0 +1,2 @@
+-- The address of the app an activity runs in, with placeholders filled in on launch
+ALTER TABLE study_activities ADD COLUMN launch_url TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,5 @@
+DROP INDEX idx_study_activities_slug;
+
+ALTER TABLE study_activities DROP COLUMN required_word_fields;
+ALTER TABLE study_activities DROP COLUMN supported_group_types;
+ALTER TABLE study_activities DROP COLUMN slug;
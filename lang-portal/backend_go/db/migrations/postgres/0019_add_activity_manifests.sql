@@ -0,0 +1,9 @@
+-- Activities registered through the API or manifests are known by a slug.
+-- Those from before slugs are named after their ID.
+ALTER TABLE study_activities ADD COLUMN slug TEXT NOT NULL DEFAULT '';
+ALTER TABLE study_activities ADD COLUMN supported_group_types TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE study_activities ADD COLUMN required_word_fields TEXT NOT NULL DEFAULT '[]';
+
+UPDATE study_activities SET slug = 'activity-' || id;
+
+CREATE UNIQUE INDEX idx_study_activities_slug ON study_activities(slug) WHERE slug <> '';
@@ -0,0 +1,34 @@
+-- Half-width katakana fold into hiragana like full-width katakana, see
+-- internal/textnorm. search_fold learns them and the index is rebuilt.
+
+-- Kana followed by a voiced sound mark, as half-width katakana write them,
+-- become the voiced kana
+CREATE FUNCTION search_voice(value TEXT) RETURNS TEXT AS $$
+DECLARE
+    plain TEXT := 'かきくけこさしすせそたちつてとはひふへほう';
+    voiced TEXT := 'がぎぐげござじずぜぞだぢづでどばびぶべぼゔ';
+BEGIN
+    FOR i IN 1..length(plain) LOOP
+        value := replace(value, substr(plain, i, 1) || '゛', substr(voiced, i, 1));
+    END LOOP;
+    FOR i IN 1..5 LOOP
+        value := replace(value, substr('はひふへほ', i, 1) || '゜', substr('ぱぴぷぺぽ', i, 1));
+    END LOOP;
+    RETURN value;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- Full-width ASCII to ASCII, katakana of either width to hiragana, voiced
+-- sound marks joined to their kana, then lower case
+CREATE OR REPLACE FUNCTION search_fold(value TEXT) RETURNS TEXT AS $$
+    SELECT lower(search_voice(translate(value,
+        '！＂＃＄％＆＇（）＊＋，－．／０１２３４５６７８９：；＜＝＞？＠ＡＢＣＤＥＦＧＨＩＪＫＬＭＮＯＰＱＲＳＴＵＶＷＸＹＺ［＼］＾＿｀ａｂｃｄｅｆｇｈｉｊｋｌｍｎｏｐｑｒｓｔｕｖｗｘｙｚ｛｜｝～　ァアィイゥウェエォオカガキギクグケゲコゴサザシジスズセゼソゾタダチヂッツヅテデトドナニヌネノハバパヒビピフブプヘベペホボポマミムメモャヤュユョヨラリルレロヮワヰヱヲンヴヵヶヽヾ｡｢｣､･ｦｧｨｩｪｫｬｭｮｯｰｱｲｳｴｵｶｷｸｹｺｻｼｽｾｿﾀﾁﾂﾃﾄﾅﾆﾇﾈﾉﾊﾋﾌﾍﾎﾏﾐﾑﾒﾓﾔﾕﾖﾗﾘﾙﾚﾛﾜﾝﾞﾟ',
+        '!"#$%&''()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\]^_`abcdefghijklmnopqrstuvwxyz{|}~ ぁあぃいぅうぇえぉおかがきぎくぐけげこごさざしじすずせぜそぞただちぢっつづてでとどなにぬねのはばぱひびぴふぶぷへべぺほぼぽまみむめもゃやゅゆょよらりるれろゎわゐゑをんゔゕゖゝゞ。「」、・をぁぃぅぇぉゃゅょっーあいうえおかきくけこさしすせそたちつてとなにぬねのはひふへほまみむめもやゆよらりるれろわん゛゜')))
+$$ LANGUAGE SQL IMMUTABLE;
+
+UPDATE word_search s
+SET japanese = search_japanese(w.japanese),
+    romaji = search_romaji(w.romaji),
+    english = search_english(w.english)
+FROM words w
+WHERE w.id = s.word_id;
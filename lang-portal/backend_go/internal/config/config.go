@@ -0,0 +1,466 @@
+// Package config loads the server configuration. Settings are read, each
+// overriding the previous, from built-in defaults, an optional YAML or TOML
+// file, environment variables and command line flags.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"lang-portal/backend_go/internal/repository/sqlstore"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	Server     Server     `yaml:"server" toml:"server"`
+	Database   Database   `yaml:"database" toml:"database"`
+	API        API        `yaml:"api" toml:"api"`
+	Auth       Auth       `yaml:"auth" toml:"auth"`
+	Sessions   Sessions   `yaml:"sessions" toml:"sessions"`
+	Activities Activities `yaml:"activities" toml:"activities"`
+	XAPI       XAPI       `yaml:"xapi" toml:"xapi"`
+	// LogLevel is one of debug, info, warn or error. At debug the HTTP
+	// router also runs in debug mode.
+	LogLevel string `yaml:"log_level" toml:"log_level"`
+}
+
+type Server struct {
+	Addr string `yaml:"addr" toml:"addr"`
+	// TLSCertFile and TLSKeyFile enable HTTPS when both are set.
+	TLSCertFile string `yaml:"tls_cert_file" toml:"tls_cert_file"`
+	TLSKeyFile  string `yaml:"tls_key_file" toml:"tls_key_file"`
+	// CORSAllowedOrigins lists the origins browsers may call the API from,
+	// or "*" for any.
+	CORSAllowedOrigins []string `yaml:"cors_allowed_origins" toml:"cors_allowed_origins"`
+	// ShutdownTimeout is how long in-flight requests may take to finish
+	// once the server is asked to stop.
+	ShutdownTimeout Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+}
+
+type Database struct {
+	// Driver is sqlite or postgres.
+	Driver string `yaml:"driver" toml:"driver"`
+	// DSN is the SQLite file path or the PostgreSQL connection string.
+	DSN string `yaml:"dsn" toml:"dsn"`
+	// MigrateOnStart applies pending migrations before the server starts.
+	MigrateOnStart bool `yaml:"migrate_on_start" toml:"migrate_on_start"`
+}
+
+type API struct {
+	// PageSize is the number of items per page of list endpoints.
+	PageSize int `yaml:"page_size" toml:"page_size"`
+	// MaxPageSize is the most items per page a client may ask for.
+	MaxPageSize int `yaml:"max_page_size" toml:"max_page_size"`
+}
+
+type Auth struct {
+	TokenTTL Duration `yaml:"token_ttl" toml:"token_ttl"`
+	// LaunchSecret signs the tokens activity apps are launched with. If it
+	// is empty a random one is made at startup, and launch tokens do not
+	// survive a restart.
+	LaunchSecret string `yaml:"launch_secret" toml:"launch_secret"`
+	// LaunchTokenTTL is how long launch tokens stay valid.
+	LaunchTokenTTL Duration `yaml:"launch_token_ttl" toml:"launch_token_ttl"`
+}
+
+type Sessions struct {
+	// IdleTimeout is how long a study session may go without activity
+	// before it is closed as abandoned, at least a second.
+	IdleTimeout Duration `yaml:"idle_timeout" toml:"idle_timeout"`
+}
+
+type Activities struct {
+	// ManifestDir holds the .json manifests of study activities to register
+	// at startup. Empty registers none.
+	ManifestDir string `yaml:"manifest_dir" toml:"manifest_dir"`
+}
+
+type XAPI struct {
+	// BaseIRI is the address clients reach the portal at, such as
+	// https://portal.example.com. The IRIs of the activities in xAPI
+	// statements are built on it.
+	BaseIRI string `yaml:"base_iri" toml:"base_iri"`
+}
+
+// Duration is a time.Duration written as a string such as "30m" in config
+// files.
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Default returns the configuration used when nothing is set.
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Addr:               ":4000",
+			CORSAllowedOrigins: []string{"*"},
+			ShutdownTimeout:    Duration(15 * time.Second),
+		},
+		Database: Database{
+			Driver: sqlstore.SQLite.Name,
+			DSN:    "words.db",
+		},
+		API: API{
+			PageSize:    100,
+			MaxPageSize: 500,
+		},
+		Auth: Auth{
+			TokenTTL:       Duration(7 * 24 * time.Hour),
+			LaunchTokenTTL: Duration(2 * time.Hour),
+		},
+		Sessions: Sessions{
+			IdleTimeout: Duration(30 * time.Minute),
+		},
+		XAPI: XAPI{
+			BaseIRI: "http://localhost:4000",
+		},
+		LogLevel: "info",
+	}
+}
+
+// Flags holds the command line options that are not settings.
+type Flags struct {
+	ConfigFile  string
+	PrintConfig bool
+	// Args are the arguments left after the flags.
+	Args []string
+}
+
+// setting is a value that can be set from the environment or a flag.
+type setting struct {
+	flag, env, usage string
+	set              func(c *Config, value string) error
+}
+
+// boolFlags may be given without a value.
+var boolFlags = map[string]bool{"migrate": true}
+
+var settings = []setting{
+	{"addr", "LISTEN_ADDR", "address to listen on, such as :4000", func(c *Config, v string) error {
+		c.Server.Addr = v
+		return nil
+	}},
+	{"tls-cert", "TLS_CERT_FILE", "TLS certificate file, enables HTTPS together with -tls-key", func(c *Config, v string) error {
+		c.Server.TLSCertFile = v
+		return nil
+	}},
+	{"tls-key", "TLS_KEY_FILE", "TLS private key file", func(c *Config, v string) error {
+		c.Server.TLSKeyFile = v
+		return nil
+	}},
+	{"cors-origins", "CORS_ALLOWED_ORIGINS", "comma-separated origins allowed to call the API, or *", func(c *Config, v string) error {
+		c.Server.CORSAllowedOrigins = splitList(v)
+		return nil
+	}},
+	{"shutdown-timeout", "SHUTDOWN_TIMEOUT", "time in-flight requests get to finish on shutdown, such as 15s", func(c *Config, v string) error {
+		return c.Server.ShutdownTimeout.UnmarshalText([]byte(v))
+	}},
+	{"db-driver", "DB_DRIVER", "storage backend, sqlite or postgres", func(c *Config, v string) error {
+		c.Database.Driver = v
+		return nil
+	}},
+	{"database-url", "DATABASE_URL", "SQLite database path or PostgreSQL connection string", func(c *Config, v string) error {
+		c.Database.DSN = v
+		return nil
+	}},
+	{"migrate", "MIGRATE_ON_START", "apply pending migrations before starting", func(c *Config, v string) error {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("not a boolean: %q", v)
+		}
+		c.Database.MigrateOnStart = b
+		return nil
+	}},
+	{"page-size", "PAGE_SIZE", "items per page of list endpoints", func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("not a number: %q", v)
+		}
+		c.API.PageSize = n
+		return nil
+	}},
+	{"max-page-size", "MAX_PAGE_SIZE", "most items per page clients may ask for", func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("not a number: %q", v)
+		}
+		c.API.MaxPageSize = n
+		return nil
+	}},
+	{"token-ttl", "TOKEN_TTL", "lifetime of login tokens, such as 168h", func(c *Config, v string) error {
+		return c.Auth.TokenTTL.UnmarshalText([]byte(v))
+	}},
+	{"launch-secret", "LAUNCH_SECRET", "secret of at least 32 bytes that signs activity launch tokens", func(c *Config, v string) error {
+		c.Auth.LaunchSecret = v
+		return nil
+	}},
+	{"launch-token-ttl", "LAUNCH_TOKEN_TTL", "lifetime of activity launch tokens, such as 2h", func(c *Config, v string) error {
+		return c.Auth.LaunchTokenTTL.UnmarshalText([]byte(v))
+	}},
+	{"session-idle-timeout", "SESSION_IDLE_TIMEOUT", "idle time after which study sessions are closed, such as 30m", func(c *Config, v string) error {
+		return c.Sessions.IdleTimeout.UnmarshalText([]byte(v))
+	}},
+	{"activity-manifests", "ACTIVITY_MANIFEST_DIR", "directory of study activity manifests to register at startup", func(c *Config, v string) error {
+		c.Activities.ManifestDir = v
+		return nil
+	}},
+	{"xapi-base-iri", "XAPI_BASE_IRI", "address clients reach the portal at, the base of xAPI activity IRIs", func(c *Config, v string) error {
+		c.XAPI.BaseIRI = v
+		return nil
+	}},
+	{"log-level", "LOG_LEVEL", "debug, info, warn or error", func(c *Config, v string) error {
+		c.LogLevel = v
+		return nil
+	}},
+}
+
+// Load builds the configuration from the command line arguments of the
+// named program (without the program name itself) and the environment, and
+// validates it.
+func Load(name string, args []string, getenv func(string) string) (*Config, Flags, error) {
+	var flags Flags
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(&flags.ConfigFile, "config", getenv("CONFIG_FILE"), "YAML or TOML config file")
+	fs.BoolVar(&flags.PrintConfig, "print-config", false, "print the effective configuration and exit")
+
+	// Flags are applied after the file and the environment, in the order
+	// they were given
+	type flagValue struct {
+		setting setting
+		value   string
+	}
+	var flagged []flagValue
+	for _, s := range settings {
+		s := s
+		record := func(value string) error {
+			flagged = append(flagged, flagValue{s, value})
+			return nil
+		}
+		if boolFlags[s.flag] {
+			fs.BoolFunc(s.flag, s.usage+" (env "+s.env+")", record)
+		} else {
+			fs.Func(s.flag, s.usage+" (env "+s.env+")", record)
+		}
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, flags, err
+	}
+	flags.Args = fs.Args()
+
+	cfg := Default()
+	if flags.ConfigFile != "" {
+		if err := cfg.readFile(flags.ConfigFile); err != nil {
+			return nil, flags, err
+		}
+	}
+
+	for _, s := range settings {
+		if value := getenv(s.env); value != "" {
+			if err := s.set(cfg, value); err != nil {
+				return nil, flags, fmt.Errorf("invalid %s: %v", s.env, err)
+			}
+		}
+	}
+	for _, f := range flagged {
+		if err := f.setting.set(cfg, f.value); err != nil {
+			return nil, flags, fmt.Errorf("invalid -%s: %v", f.setting.flag, err)
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, flags, err
+	}
+	return cfg, flags, nil
+}
+
+// readFile overlays the settings in a YAML or TOML file, chosen by its
+// extension. Unknown keys are an error so that typos do not go unnoticed.
+func (c *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading config file: %v", err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(c)
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+	case ".toml":
+		err = toml.NewDecoder(bytes.NewReader(data)).DisallowUnknownFields().Decode(c)
+	default:
+		return fmt.Errorf("config file %s: unsupported format %q, use .yaml, .yml or .toml", path, ext)
+	}
+	if err != nil {
+		return fmt.Errorf("error parsing config file %s: %v", path, err)
+	}
+	return nil
+}
+
+var logLevels = map[string]slog.Level{
+	"debug": slog.LevelDebug,
+	"info":  slog.LevelInfo,
+	"warn":  slog.LevelWarn,
+	"error": slog.LevelError,
+}
+
+// Validate reports every invalid setting at once.
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if _, _, err := net.SplitHostPort(c.Server.Addr); err != nil {
+		fail("server.addr %q: must be host:port or :port", c.Server.Addr)
+	}
+	if (c.Server.TLSCertFile == "") != (c.Server.TLSKeyFile == "") {
+		fail("server.tls_cert_file and server.tls_key_file must be set together")
+	}
+	for _, path := range []string{c.Server.TLSCertFile, c.Server.TLSKeyFile} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			fail("TLS file: %v", err)
+		}
+	}
+	if len(c.Server.CORSAllowedOrigins) == 0 {
+		fail("server.cors_allowed_origins must not be empty, use * to allow any origin")
+	}
+	for _, origin := range c.Server.CORSAllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			fail("server.cors_allowed_origins: %q is not an origin such as https://portal.example.com", origin)
+		}
+	}
+
+	if c.Server.ShutdownTimeout <= 0 {
+		fail("server.shutdown_timeout must be positive")
+	}
+
+	if _, err := sqlstore.DialectFor(c.Database.Driver); err != nil {
+		fail("database.driver: %v", err)
+	}
+	if c.Database.DSN == "" {
+		fail("database.dsn must not be empty")
+	}
+
+	if c.API.PageSize < 1 || c.API.PageSize > 1000 {
+		fail("api.page_size %d: must be between 1 and 1000", c.API.PageSize)
+	}
+	if c.API.MaxPageSize < c.API.PageSize || c.API.MaxPageSize > 1000 {
+		fail("api.max_page_size %d: must be between api.page_size and 1000", c.API.MaxPageSize)
+	}
+	if c.Auth.TokenTTL <= 0 {
+		fail("auth.token_ttl must be positive")
+	}
+	if c.Auth.LaunchSecret != "" && len(c.Auth.LaunchSecret) < 32 {
+		fail("auth.launch_secret must be at least 32 bytes long")
+	}
+	if c.Auth.LaunchTokenTTL <= 0 {
+		fail("auth.launch_token_ttl must be positive")
+	}
+	// Idle sessions are looked for every half timeout, see cmd/server
+	if c.Sessions.IdleTimeout < Duration(time.Second) {
+		fail("sessions.idle_timeout must be at least 1s")
+	}
+	if c.Activities.ManifestDir != "" {
+		if info, err := os.Stat(c.Activities.ManifestDir); err != nil {
+			fail("activities.manifest_dir: %v", err)
+		} else if !info.IsDir() {
+			fail("activities.manifest_dir %q: not a directory", c.Activities.ManifestDir)
+		}
+	}
+	if u, err := url.Parse(c.XAPI.BaseIRI); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		fail("xapi.base_iri %q: must be an address such as https://portal.example.com", c.XAPI.BaseIRI)
+	}
+	if _, ok := logLevels[c.LogLevel]; !ok {
+		fail("log_level %q: must be debug, info, warn or error", c.LogLevel)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Level returns the log level as a slog.Level.
+func (c *Config) Level() slog.Level {
+	return logLevels[c.LogLevel]
+}
+
+// TLS reports whether the server should serve HTTPS.
+func (c *Config) TLS() bool {
+	return c.Server.TLSCertFile != ""
+}
+
+var dsnPassword = regexp.MustCompile(`(password=)('[^']*'|\S*)`)
+
+// Write prints the configuration as YAML with the database password and
+// the launch secret hidden.
+func (c *Config) Write(w io.Writer) error {
+	redacted := *c
+	redacted.Database.DSN = redactDSN(c.Database.DSN)
+	if redacted.Auth.LaunchSecret != "" {
+		redacted.Auth.LaunchSecret = "xxxxx"
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&redacted); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// redactDSN hides the password of a postgres:// URL or a key=value
+// connection string.
+func redactDSN(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.User != nil {
+		return u.Redacted()
+	}
+	return dsnPassword.ReplaceAllString(dsn, "${1}xxxxx")
+}
+
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
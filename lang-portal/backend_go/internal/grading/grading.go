@@ -0,0 +1,273 @@
+// Package grading checks a learner's answer to a word. Both the answer and
+// the word are normalized before they are compared: white space,
+// punctuation, full-width letters, half-width kana and case are folded as
+// in textnorm, Japanese is compared by its romaji reading so that kana and
+// romaji answers in Hepburn or Kunrei spelling match, and long vowels
+// written ō, ou, oo or with a long vowel mark count as one. English
+// answers may give any of a word's glosses. Answers a few typos away from
+// one are accepted as close.
+package grading
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/textnorm"
+)
+
+// Result is how an answer compares with the accepted ones.
+type Result string
+
+const (
+	// Exact answers match an accepted answer once normalized.
+	Exact Result = "exact"
+	// Close answers are within the typo allowance of one, see Allowance.
+	Close Result = "close"
+	Wrong Result = "wrong"
+)
+
+// Verdict is the grade of an answer.
+type Verdict struct {
+	Result  Result `json:"result"`
+	Correct bool   `json:"correct"`
+	// Expected is the accepted answer closest to the one given, as written
+	// on the word.
+	Expected string   `json:"expected"`
+	Accepted []string `json:"accepted"`
+	// Distance is the edit distance between the normalized answer and
+	// Expected, and Diff the edits that turn one into the other.
+	Distance int    `json:"distance"`
+	Diff     []Edit `json:"diff"`
+}
+
+// Edit operations of a diff, from the answer given to the expected one.
+const (
+	Equal  = "equal"
+	Insert = "insert"
+	Delete = "delete"
+)
+
+// Edit is a run of text the answer given shares with the expected answer,
+// misses (Insert) or has in excess (Delete).
+type Edit struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// candidate is an accepted answer and its normal form.
+type candidate struct {
+	text string
+	key  string
+	// normalize brings the answer given into the form of key
+	normalize func(string) string
+	// fuzzy candidates accept answers within the typo allowance
+	fuzzy bool
+}
+
+// Check grades an answer to a word asked in direction, one of
+// models.Directions. Words asked from Japanese are answered in English,
+// the others in Japanese.
+func Check(word *models.Word, direction, answer string) Verdict {
+	var candidates []candidate
+	var accepted []string
+	if direction == models.DirectionJapaneseToEnglish {
+		for _, gloss := range Glosses(word.English) {
+			candidates = append(candidates, candidate{gloss, englishKey(gloss), englishKey, true})
+			accepted = append(accepted, gloss)
+		}
+	} else {
+		if word.Japanese != "" {
+			candidates = append(candidates, candidate{word.Japanese, surfaceKey(word.Japanese), surfaceKey, false})
+			accepted = append(accepted, word.Japanese)
+			if reading := readingKey(word.Japanese); isASCII(reading) {
+				candidates = append(candidates, candidate{word.Japanese, reading, readingKey, true})
+			}
+		}
+		if word.Romaji != "" {
+			candidates = append(candidates, candidate{word.Japanese, readingKey(word.Romaji), readingKey, true})
+			accepted = append(accepted, word.Romaji)
+		}
+	}
+
+	verdict := Verdict{Result: Wrong, Accepted: accepted, Diff: []Edit{}}
+	if len(candidates) == 0 {
+		return verdict
+	}
+
+	best, bestGiven, bestDistance := candidates[0], "", -1
+	for _, c := range candidates {
+		given := c.normalize(answer)
+		if d := distance(given, c.key); bestDistance < 0 || d < bestDistance {
+			best, bestGiven, bestDistance = c, given, d
+		}
+	}
+
+	verdict.Expected = best.text
+	verdict.Distance = bestDistance
+	verdict.Diff = diff(bestGiven, best.key)
+	switch {
+	case bestGiven == "":
+		// An empty answer is never close
+	case bestDistance == 0:
+		verdict.Result = Exact
+	case best.fuzzy && bestDistance <= Allowance(best.key):
+		verdict.Result = Close
+	}
+	verdict.Correct = verdict.Result != Wrong
+	return verdict
+}
+
+// Allowance returns how many typos an answer to key may have and still be
+// close: none for up to 3 characters, one for up to 7 and two beyond.
+func Allowance(key string) int {
+	switch n := utf8.RuneCountInString(key); {
+	case n <= 3:
+		return 0
+	case n <= 7:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Glosses splits English text into the glosses it lists, separated by ";",
+// "," or "/".
+func Glosses(english string) []string {
+	glosses := []string{}
+	for _, gloss := range glossSeparator.Split(english, -1) {
+		if gloss = strings.TrimSpace(gloss); gloss != "" {
+			glosses = append(glosses, gloss)
+		}
+	}
+	return glosses
+}
+
+var (
+	glossSeparator = regexp.MustCompile(`[;,/]`)
+	parenthetical  = regexp.MustCompile(`\([^)]*\)`)
+	leadingWord    = regexp.MustCompile(`^(to|a|an|the) `)
+)
+
+// englishKey folds English as textnorm does, leaving out notes in
+// parentheses and a leading "to" or article, so that "eat" answers "to eat".
+func englishKey(s string) string {
+	key := textnorm.English.Key(parenthetical.ReplaceAllString(s, " "))
+	if trimmed := leadingWord.ReplaceAllString(key, ""); trimmed != "" {
+		return trimmed
+	}
+	return key
+}
+
+// surfaceKey folds Japanese as written, kanji and all, without spaces.
+func surfaceKey(s string) string {
+	return strings.Join(strings.Fields(textnorm.Japanese.Key(fold(s))), "")
+}
+
+// readingKey returns the romaji reading of kana or romaji, with long vowels
+// folded and without spaces.
+func readingKey(s string) string {
+	return strings.Join(strings.Fields(textnorm.Romaji.Key(textnorm.Romanize(toHiragana(fold(s))))), "")
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// distance returns the Levenshtein distance between a and b in runes.
+func distance(a, b string) int {
+	return editTable(a, b)[len([]rune(a))][len([]rune(b))]
+}
+
+// editTable returns the table of edit distances between the prefixes of a
+// and b.
+func editTable(a, b string) [][]int {
+	ra, rb := []rune(a), []rune(b)
+	table := make([][]int, len(ra)+1)
+	for i := range table {
+		table[i] = make([]int, len(rb)+1)
+		table[i][0] = i
+	}
+	for j := range table[0] {
+		table[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			table[i][j] = min(table[i-1][j]+1, table[i][j-1]+1, table[i-1][j-1]+cost)
+		}
+	}
+	return table
+}
+
+// diff returns the edits that turn given into expected. Between the runs
+// both share, what given has in excess comes before what it misses.
+func diff(given, expected string) []Edit {
+	ra, rb := []rune(given), []rune(expected)
+	table := editTable(given, expected)
+
+	// Walk back from the end, then reverse
+	var reversed []Edit
+	i, j := len(ra), len(rb)
+	for i > 0 || j > 0 {
+		switch {
+		case i > 0 && j > 0 && ra[i-1] == rb[j-1] && table[i][j] == table[i-1][j-1]:
+			reversed = append(reversed, Edit{Equal, string(ra[i-1])})
+			i, j = i-1, j-1
+		case j > 0 && (i == 0 || table[i][j] == table[i][j-1]+1):
+			reversed = append(reversed, Edit{Insert, string(rb[j-1])})
+			j--
+		case i > 0 && j > 0 && table[i][j] == table[i-1][j-1]+1:
+			// A substitution is the given rune deleted and the expected one
+			// inserted
+			reversed = append(reversed, Edit{Insert, string(rb[j-1])}, Edit{Delete, string(ra[i-1])})
+			i, j = i-1, j-1
+		default:
+			reversed = append(reversed, Edit{Delete, string(ra[i-1])})
+			i--
+		}
+	}
+
+	edits := []Edit{}
+	var equal, deleted, inserted strings.Builder
+	flush := func() {
+		for _, edit := range []Edit{{Equal, equal.String()}, {Delete, deleted.String()}, {Insert, inserted.String()}} {
+			if edit.Text != "" {
+				edits = append(edits, edit)
+			}
+		}
+		equal.Reset()
+		deleted.Reset()
+		inserted.Reset()
+	}
+	for k := len(reversed) - 1; k >= 0; k-- {
+		switch edit := reversed[k]; edit.Op {
+		case Equal:
+			if deleted.Len() > 0 || inserted.Len() > 0 {
+				flush()
+			}
+			equal.WriteString(edit.Text)
+		case Delete:
+			if equal.Len() > 0 {
+				flush()
+			}
+			deleted.WriteString(edit.Text)
+		default:
+			if equal.Len() > 0 {
+				flush()
+			}
+			inserted.WriteString(edit.Text)
+		}
+	}
+	flush()
+	return edits
+}
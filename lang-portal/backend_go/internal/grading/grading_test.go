@@ -0,0 +1,113 @@
+package grading
+
+import (
+	"testing"
+
+	"lang-portal/backend_go/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReading(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"taberu", "taberu"},
+		{"たべる", "taberu"},
+		{"タベル", "taberu"},
+		{"ｔａｂｅｒｕ", "taberu"},
+		{"Tōkyō", "tokyo"},
+		{"toukyou", "tokyo"},
+		{"とうきょう", "tokyo"},
+		{"コーヒー", "kohi"},
+		{"ｺｰﾋｰ", "kohi"},
+		{"ｶﾞｯｺｳ", "gakko"},
+		{"kōhī", "kohi"},
+		{"si ti tu", "shichitsu"},
+		{"shichitsu", "shichitsu"},
+		{"syasin", "shashin"},
+		{"matcha", "matcha"},
+		{"まっちゃ", "matcha"},
+		{"kitte", "kitte"},
+		{"きって", "kitte"},
+		{"konnichiwa", "konnichiwa"},
+		{"こんにちわ。", "konnichiwa"},
+		{"hon'ya", "honya"},
+		{"ほんや", "honya"},
+		{"食べる", "食beru"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			assert.Equal(t, tt.want, readingKey(tt.text))
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	eat := &models.Word{Japanese: "食べる", Romaji: "taberu", English: "to eat; to have a meal"}
+	coffee := &models.Word{Japanese: "コーヒー", Romaji: "kōhī", English: "coffee"}
+	hello := &models.Word{Japanese: "こんにちは", Romaji: "konnichiwa", English: "hello, good afternoon"}
+
+	tests := []struct {
+		name      string
+		word      *models.Word
+		direction string
+		answer    string
+		want      Result
+		expected  string
+	}{
+		{"gloss", eat, models.DirectionJapaneseToEnglish, "to eat", Exact, "to eat"},
+		{"gloss without to", eat, models.DirectionJapaneseToEnglish, "Eat!", Exact, "to eat"},
+		{"second gloss", eat, models.DirectionJapaneseToEnglish, "have a meal", Exact, "to have a meal"},
+		{"typo", eat, models.DirectionJapaneseToEnglish, "to have a mael", Close, "to have a meal"},
+		{"wrong gloss", eat, models.DirectionJapaneseToEnglish, "to drink", Wrong, "to eat"},
+		{"full-width gloss", hello, models.DirectionJapaneseToEnglish, "ｇｏｏｄ　ａｆｔｅｒｎｏｏｎ", Exact, "good afternoon"},
+		{"short gloss typo", &models.Word{English: "cat"}, models.DirectionJapaneseToEnglish, "car", Wrong, "cat"},
+		{"kanji", eat, models.DirectionEnglishToJapanese, "食べる", Exact, "食べる"},
+		{"kana", eat, models.DirectionEnglishToJapanese, "たべる", Exact, "食べる"},
+		{"katakana", eat, models.DirectionAudioToJapanese, "タベル", Exact, "食べる"},
+		{"romaji", eat, models.DirectionEnglishToJapanese, " TABERU ", Exact, "食べる"},
+		{"kana typo", eat, models.DirectionEnglishToJapanese, "たべろ", Close, "食べる"},
+		{"long vowels", coffee, models.DirectionEnglishToJapanese, "koohii", Exact, "コーヒー"},
+		{"hiragana for katakana", coffee, models.DirectionEnglishToJapanese, "こおひい", Exact, "コーヒー"},
+		{"half-width katakana", coffee, models.DirectionEnglishToJapanese, "ｺｰﾋｰ", Exact, "コーヒー"},
+		{"kana read as written", hello, models.DirectionEnglishToJapanese, "konnichiha", Exact, "こんにちは"},
+		{"missing kana", hello, models.DirectionEnglishToJapanese, "konichiwa", Close, "こんにちは"},
+		{"wrong word", eat, models.DirectionEnglishToJapanese, "nomu", Wrong, "食べる"},
+		{"empty", eat, models.DirectionEnglishToJapanese, "", Wrong, "食べる"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict := Check(tt.word, tt.direction, tt.answer)
+			assert.Equal(t, tt.want, verdict.Result)
+			assert.Equal(t, tt.want != Wrong, verdict.Correct)
+			assert.Equal(t, tt.expected, verdict.Expected)
+		})
+	}
+
+	verdict := Check(eat, models.DirectionJapaneseToEnglish, "")
+	assert.Equal(t, []string{"to eat", "to have a meal"}, verdict.Accepted)
+}
+
+func TestDiff(t *testing.T) {
+	verdict := Check(&models.Word{English: "good afternoon"}, models.DirectionJapaneseToEnglish, "good aftrenoon")
+	assert.Equal(t, 2, verdict.Distance)
+	assert.Equal(t, Close, verdict.Result)
+	assert.Equal(t, []Edit{
+		{Equal, "good aft"},
+		{Delete, "r"},
+		{Equal, "e"},
+		{Insert, "r"},
+		{Equal, "noon"},
+	}, verdict.Diff)
+
+	verdict = Check(&models.Word{English: "afternoon"}, models.DirectionJapaneseToEnglish, "aftrenon")
+	assert.Equal(t, 3, verdict.Distance)
+	assert.Equal(t, Wrong, verdict.Result)
+
+	assert.Equal(t, []Edit{{Delete, "ab"}, {Insert, "cd"}}, diff("ab", "cd"))
+	assert.Equal(t, []Edit{}, diff("", ""))
+}
@@ -0,0 +1,108 @@
+package grading
+
+import (
+	"strings"
+	"unicode"
+
+	"lang-portal/backend_go/internal/textnorm"
+)
+
+// spellings maps the Kunrei and Nihon-shiki spellings learners type, and
+// the Hepburn ones of textnorm.Syllables, to hiragana. Where two kana share a
+// spelling, such as "ji", the common one wins.
+var spellings = map[string]string{
+	"si": "し", "zi": "じ", "ti": "ち", "tu": "つ", "hu": "ふ", "di": "ぢ", "du": "づ",
+	"sya": "しゃ", "syu": "しゅ", "syo": "しょ",
+	"zya": "じゃ", "zyu": "じゅ", "zyo": "じょ",
+	"jya": "じゃ", "jyu": "じゅ", "jyo": "じょ",
+	"tya": "ちゃ", "tyu": "ちゅ", "tyo": "ちょ",
+	"cya": "ちゃ", "cyu": "ちゅ", "cyo": "ちょ",
+	"wo": "を",
+}
+
+func init() {
+	for _, s := range textnorm.Syllables {
+		if _, ok := spellings[s[1]]; !ok {
+			spellings[s[1]] = s[0]
+		}
+	}
+}
+
+// The most runes a spelling takes.
+const longestSpelling = 3
+
+// fold folds text as textnorm.Fold does, and vowels marked long in romaji
+// to doubled ones. Punctuation other than ASCII, such as "。", is dropped.
+func fold(s string) string {
+	var b strings.Builder
+	for _, r := range textnorm.Fold(s) {
+		if vowel, ok := markedVowels[r]; ok {
+			b.WriteRune(vowel)
+			b.WriteRune(vowel)
+			continue
+		}
+		if r >= 0x80 && (unicode.IsPunct(r) || unicode.IsSymbol(r)) {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+var markedVowels = map[rune]rune{
+	'ā': 'a', 'ī': 'i', 'ū': 'u', 'ē': 'e', 'ō': 'o',
+	'â': 'a', 'î': 'i', 'û': 'u', 'ê': 'e', 'ô': 'o',
+}
+
+// toHiragana spells the romaji in folded text in hiragana. Letters that
+// spell no kana are kept.
+func toHiragana(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		next := rune(0)
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+
+		switch {
+		case r == 'n' && next == '\'':
+			b.WriteString("ん")
+			i += 2
+			continue
+		case r == 'n' && !isVowel(next) && next != 'y':
+			b.WriteString("ん")
+			i++
+			continue
+		case isConsonant(r) && (next == r || r == 't' && next == 'c'):
+			// A doubled consonant, or the t of "tch", is a small tsu
+			b.WriteString("っ")
+			i++
+			continue
+		}
+
+		matched := false
+		for n := min(longestSpelling, len(runes)-i); n > 0; n-- {
+			if kana, ok := spellings[string(runes[i:i+n])]; ok {
+				b.WriteString(kana)
+				i += n
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			b.WriteRune(r)
+			i++
+		}
+	}
+	return b.String()
+}
+
+func isVowel(r rune) bool {
+	return strings.ContainsRune("aiueo", r)
+}
+
+func isConsonant(r rune) bool {
+	return r >= 'a' && r <= 'z' && r != 'n' && !isVowel(r)
+}
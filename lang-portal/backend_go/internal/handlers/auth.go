@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	userKey   = "user"
+	launchKey = "launch"
+)
+
+// Authenticate resolves the bearer token in the Authorization header and
+// stores its user on the context for the handlers. Requests without a valid
+// token are rejected.
+func Authenticate(auth *service.AuthService) gin.HandlerFunc {
+	return authenticate(auth, nil)
+}
+
+// LaunchScope picks the ID from the claims of a launch token that the :id
+// parameter of a route must match.
+type LaunchScope func(*service.LaunchClaims) int64
+
+var (
+	// SessionScope admits launch tokens on routes of their study session.
+	SessionScope LaunchScope = func(claims *service.LaunchClaims) int64 { return claims.SessionID }
+	// GroupScope admits launch tokens on routes of their session's group.
+	GroupScope LaunchScope = func(claims *service.LaunchClaims) int64 { return claims.GroupID }
+)
+
+// AuthenticateLaunch is Authenticate for the routes activity apps call. It
+// also accepts launch tokens, as long as the :id of the route is the one
+// scope picks from the token.
+func AuthenticateLaunch(auth *service.AuthService, scope LaunchScope) gin.HandlerFunc {
+	return authenticate(auth, scope)
+}
+
+func authenticate(auth *service.AuthService, scope LaunchScope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := bearerToken(c)
+		if !ok {
+			c.Header("WWW-Authenticate", "Bearer")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+			return
+		}
+
+		var user *models.User
+		var claims *service.LaunchClaims
+		var err error
+		if scope != nil && service.IsLaunchToken(token) {
+			user, claims, err = auth.AuthenticateLaunch(c.Request.Context(), token, time.Now())
+		} else {
+			user, err = auth.Authenticate(c.Request.Context(), token, time.Now())
+		}
+		if errors.Is(err, service.ErrInvalidToken) {
+			c.Header("WWW-Authenticate", "Bearer")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if claims != nil {
+			if c.Param("id") != strconv.FormatInt(scope(claims), 10) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Launch token does not cover this request"})
+				return
+			}
+			c.Set(launchKey, claims)
+		}
+
+		c.Set(userKey, user)
+		c.Next()
+	}
+}
+
+// RequireRole rejects requests from users whose role does not grant role.
+// It must run after Authenticate.
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !currentUser(c).HasRole(role) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Requires the " + role + " role"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// currentUser returns the user set by Authenticate.
+func currentUser(c *gin.Context) *models.User {
+	return c.MustGet(userKey).(*models.User)
+}
+
+// currentLaunch returns the claims of the launch token the request was made
+// with, or nil if it was made with a login token.
+func currentLaunch(c *gin.Context) *service.LaunchClaims {
+	claims, _ := c.Get(launchKey)
+	launch, _ := claims.(*service.LaunchClaims)
+	return launch
+}
+
+func bearerToken(c *gin.Context) (string, bool) {
+	scheme, token, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return token, true
+}
+
+func Login(auth *service.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request struct {
+			Name     string `json:"name" binding:"required"`
+			Password string `json:"password" binding:"required"`
+		}
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		login, err := auth.Login(c.Request.Context(), request.Name, request.Password, time.Now())
+		if errors.Is(err, service.ErrInvalidCredentials) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user name or password"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"token":      login.Token,
+			"token_type": "Bearer",
+			"expires_at": login.ExpiresAt,
+			"user":       login.User,
+		})
+	}
+}
+
+// Logout revokes the token the request was made with.
+func Logout(auth *service.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, _ := bearerToken(c)
+		if err := auth.Logout(c.Request.Context(), token); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"success": true})
+	}
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginAndLogout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	db := setupTestDB(t)
+	defer db.Close()
+	svc := newTestServices(db)
+	require.NoError(t, svc.Users.SetPassword(context.Background(), 2, "correct horse"))
+
+	r.POST("/api/auth/login", Login(svc.Auth))
+	api := r.Group("/api", Authenticate(svc.Auth))
+	api.GET("/users/me", GetCurrentUser())
+	api.POST("/auth/logout", Logout(svc.Auth))
+
+	tests := []struct {
+		name       string
+		payload    string
+		wantStatus int
+	}{
+		{
+			name:       "Wrong password",
+			payload:    `{"name": "hana", "password": "wrong horse"}`,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "Unknown user",
+			payload:    `{"name": "kenji", "password": "correct horse"}`,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "User without a password",
+			payload:    `{"name": "default", "password": "correct horse"}`,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "Missing password",
+			payload:    `{"name": "hana"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/auth/login", bytes.NewBufferString(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/auth/login", bytes.NewBufferString(`{"name": "hana", "password": "correct horse"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var login struct {
+		Token string `json:"token"`
+		User  struct {
+			Name string `json:"name"`
+			Role string `json:"role"`
+		} `json:"user"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &login))
+	assert.NotEmpty(t, login.Token)
+	assert.Equal(t, "hana", login.User.Name)
+	assert.Equal(t, "student", login.User.Role)
+	assert.NotContains(t, w.Body.String(), "password")
+
+	send := func(method, path, authorization string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, send("GET", "/api/users/me", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, send("GET", "/api/users/me", "Bearer not-a-token").Code)
+	assert.Equal(t, http.StatusUnauthorized, send("GET", "/api/users/me", login.Token).Code)
+
+	w = send("GET", "/api/users/me", "Bearer "+login.Token)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"hana"`)
+
+	// The token stops working once the user logs out
+	assert.Equal(t, http.StatusOK, send("POST", "/api/auth/logout", "Bearer "+login.Token).Code)
+	assert.Equal(t, http.StatusUnauthorized, send("GET", "/api/users/me", "Bearer "+login.Token).Code)
+}
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestDB(t)
+	defer db.Close()
+	svc := newTestServices(db)
+	_, err := svc.Users.Create(context.Background(), "sato", "correct horse", "teacher")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		userID     int64
+		role       string
+		wantStatus int
+	}{
+		{"Student needs teacher", 2, "teacher", http.StatusForbidden},
+		{"Teacher is a teacher", 3, "teacher", http.StatusOK},
+		{"Teacher needs admin", 3, "admin", http.StatusForbidden},
+		{"Admin is a teacher", 1, "teacher", http.StatusOK},
+		{"Admin is an admin", 1, "admin", http.StatusOK},
+		{"Anyone is a student", 2, "student", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(authenticateAs(svc.Users, tt.userID))
+			r.GET("/api/users/me", RequireRole(tt.role), GetCurrentUser())
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/users/me", nil)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
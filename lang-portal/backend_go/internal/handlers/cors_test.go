@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		origins    []string
+		origin     string
+		method     string
+		wantStatus int
+		wantOrigin string
+	}{
+		{
+			name:       "Any origin",
+			origins:    []string{"*"},
+			origin:     "http://localhost:5173",
+			method:     "GET",
+			wantStatus: http.StatusOK,
+			wantOrigin: "*",
+		},
+		{
+			name:       "Allowed origin",
+			origins:    []string{"https://portal.example.com", "http://localhost:5173"},
+			origin:     "http://localhost:5173",
+			method:     "GET",
+			wantStatus: http.StatusOK,
+			wantOrigin: "http://localhost:5173",
+		},
+		{
+			name:       "Other origin",
+			origins:    []string{"https://portal.example.com"},
+			origin:     "http://localhost:5173",
+			method:     "GET",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Preflight",
+			origins:    []string{"https://portal.example.com"},
+			origin:     "https://portal.example.com",
+			method:     "OPTIONS",
+			wantStatus: http.StatusNoContent,
+			wantOrigin: "https://portal.example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(CORS(tt.origins))
+			r.GET("/api/words", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, "/api/words", nil)
+			req.Header.Set("Origin", tt.origin)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantOrigin, w.Header().Get("Access-Control-Allow-Origin"))
+		})
+	}
+}
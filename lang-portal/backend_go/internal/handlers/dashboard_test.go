@@ -16,8 +16,10 @@ func TestGetLastStudySession(t *testing.T) {
 	r := gin.New()
 	db := setupTestDB(t)
 	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
 
-	r.GET("/api/dashboard/last-study-session", GetLastStudySession(db))
+	r.GET("/api/dashboard/last-study-session", GetLastStudySession(svc.Dashboard))
 
 	// Test cases
 	tests := []struct {
@@ -67,8 +69,10 @@ func TestGetStudyProgress(t *testing.T) {
 	r := gin.New()
 	db := setupTestDB(t)
 	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
 
-	r.GET("/api/dashboard/study-progress", GetStudyProgress(db))
+	r.GET("/api/dashboard/study-progress", GetStudyProgress(svc.Dashboard))
 
 	// Test case
 	w := httptest.NewRecorder()
@@ -96,8 +100,10 @@ func TestGetQuickStats(t *testing.T) {
 	r := gin.New()
 	db := setupTestDB(t)
 	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
 
-	r.GET("/api/dashboard/quick-stats", GetQuickStats(db))
+	r.GET("/api/dashboard/quick-stats", GetQuickStats(svc.Dashboard))
 
 	// Test case
 	w := httptest.NewRecorder()
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportedWord is a models.SeedWord with extra fields. Decoding an export
+// into models.SeedFile ignores the extras, so the JSON export can be fed back
+// into the seeder or the import endpoint.
+type exportedWord struct {
+	models.SeedWord
+	Groups []string          `json:"groups"`
+	Stats  *models.WordStats `json:"stats,omitempty"`
+}
+
+type exportedFile struct {
+	GroupName string         `json:"group_name"`
+	Words     []exportedWord `json:"words"`
+}
+
+var filenameUnsafe = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// ExportGroup exports the words of a single group.
+func ExportGroup(groups *service.GroupService, words *service.WordService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		groupID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+			return
+		}
+
+		group, _, err := groups.Get(c.Request.Context(), groupID)
+		if errors.Is(err, service.ErrGroupNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		writeExport(c, words, group.ID, group.Name)
+	}
+}
+
+// ExportLibrary exports every word in the database.
+func ExportLibrary(words *service.WordService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writeExport(c, words, 0, "")
+	}
+}
+
+func writeExport(c *gin.Context, exporter *service.WordService, groupID int64, groupName string) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" && format != "anki" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be one of json, csv, anki"})
+		return
+	}
+
+	withStats, err := strconv.ParseBool(c.DefaultQuery("stats", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "stats must be true or false"})
+		return
+	}
+
+	words, err := exporter.Export(c.Request.Context(), currentUser(c).ID, groupID, withStats)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	filename := "vocabulary"
+	if groupName != "" {
+		if slug := strings.Trim(filenameUnsafe.ReplaceAllString(groupName, "_"), "_"); slug != "" {
+			filename = slug
+		}
+	}
+
+	var (
+		body        []byte
+		contentType string
+		extension   string
+	)
+	switch format {
+	case "json":
+		body, err = exportJSON(groupName, words)
+		contentType, extension = "application/json; charset=utf-8", "json"
+	case "csv":
+		body, err = exportCSV(words, withStats)
+		contentType, extension = "text/csv; charset=utf-8", "csv"
+	case "anki":
+		body, err = exportAnki(words)
+		contentType, extension = "text/tab-separated-values; charset=utf-8", "txt"
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, filename, extension))
+	c.Data(http.StatusOK, contentType, body)
+}
+
+func exportJSON(groupName string, words []models.ExportWord) ([]byte, error) {
+	file := exportedFile{
+		GroupName: groupName,
+		Words:     make([]exportedWord, len(words)),
+	}
+	for i, word := range words {
+		file.Words[i] = exportedWord{
+			SeedWord: models.SeedWord{
+				Japanese: word.Japanese,
+				Romaji:   word.Romaji,
+				English:  word.English,
+				Parts:    word.Parts,
+			},
+			Groups: word.Groups,
+			Stats:  word.Stats,
+		}
+	}
+
+	return json.MarshalIndent(file, "", "  ")
+}
+
+func exportCSV(words []models.ExportWord, withStats bool) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	header := []string{"japanese", "romaji", "english", "parts", "groups"}
+	if withStats {
+		header = append(header, "correct_count", "wrong_count")
+	}
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	for _, word := range words {
+		parts, err := json.Marshal(word.Parts)
+		if err != nil {
+			return nil, err
+		}
+
+		record := []string{
+			word.Japanese,
+			word.Romaji,
+			word.English,
+			string(parts),
+			strings.Join(word.Groups, ";"),
+		}
+		if withStats {
+			record = append(record,
+				strconv.Itoa(word.Stats.CorrectCount),
+				strconv.Itoa(word.Stats.WrongCount),
+			)
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// exportAnki writes a plain-text note list that Anki 2.1.54+ imports without
+// any manual mapping: Front is the Japanese, Back the English with the romaji
+// reading, and each group becomes a tag.
+func exportAnki(words []models.ExportWord) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("#separator:tab\n")
+	buf.WriteString("#html:false\n")
+	buf.WriteString("#columns:Front\tBack\tTags\n")
+	buf.WriteString("#tags column:3\n")
+
+	for _, word := range words {
+		tags := make([]string, len(word.Groups))
+		for i, group := range word.Groups {
+			tags[i] = strings.Join(strings.Fields(group), "_")
+		}
+
+		fields := []string{
+			ankiField(word.Japanese),
+			ankiField(fmt.Sprintf("%s (%s)", word.English, word.Romaji)),
+			ankiField(strings.Join(tags, " ")),
+		}
+		buf.WriteString(strings.Join(fields, "\t"))
+		buf.WriteString("\n")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ankiField strips the characters that would break the tab-separated layout.
+func ankiField(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
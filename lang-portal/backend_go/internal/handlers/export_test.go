@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"lang-portal/backend_go/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	db := setupTestDB(t)
+	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
+
+	r.GET("/api/export", ExportLibrary(svc.Words))
+	r.GET("/api/groups/:id/export", ExportGroup(svc.Groups, svc.Words))
+
+	tests := []struct {
+		name            string
+		url             string
+		wantStatus      int
+		wantContentType string
+	}{
+		{
+			name:            "Group JSON",
+			url:             "/api/groups/1/export",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json; charset=utf-8",
+		},
+		{
+			name:            "Library CSV",
+			url:             "/api/export?format=csv",
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/csv; charset=utf-8",
+		},
+		{
+			name:            "Group Anki",
+			url:             "/api/groups/1/export?format=anki",
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/tab-separated-values; charset=utf-8",
+		},
+		{
+			name:       "Invalid format",
+			url:        "/api/export?format=xml",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid stats flag",
+			url:        "/api/export?stats=maybe",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid group",
+			url:        "/api/groups/999/export",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Invalid ID format",
+			url:        "/api/groups/abc/export",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, tt.wantContentType, w.Header().Get("Content-Type"))
+				assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+			}
+		})
+	}
+}
+
+func TestExportJSONRoundTrip(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	db := setupTestDB(t)
+	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
+
+	r.GET("/api/groups/:id/export", ExportGroup(svc.Groups, svc.Words))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/groups/1/export?stats=true", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// The export decodes as a seed file
+	var seedFile models.SeedFile
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &seedFile))
+	assert.Equal(t, "Basic Greetings", seedFile.GroupName)
+	assert.Len(t, seedFile.Words, 1)
+	assert.Equal(t, "こんにちは", seedFile.Words[0].Japanese)
+	assert.Equal(t, models.Parts{Type: "greeting"}, seedFile.Words[0].Parts)
+
+	// And carries membership and stats on top
+	var export struct {
+		Words []struct {
+			Groups []string `json:"groups"`
+			Stats  struct {
+				CorrectCount int `json:"correct_count"`
+				WrongCount   int `json:"wrong_count"`
+			} `json:"stats"`
+		} `json:"words"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &export))
+	assert.Equal(t, []string{"Basic Greetings"}, export.Words[0].Groups)
+	assert.Equal(t, 1, export.Words[0].Stats.CorrectCount)
+
+	// Which the importer accepts again
+	words, err := models.ParseImport(w.Body.Bytes())
+	assert.NoError(t, err)
+	assert.Len(t, words, 1)
+}
+
+func TestExportCSV(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	db := setupTestDB(t)
+	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
+
+	r.GET("/api/export", ExportLibrary(svc.Words))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/export?format=csv&stats=true", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	records, err := csv.NewReader(strings.NewReader(w.Body.String())).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, records, 4) // Header and three words
+	assert.Equal(t, []string{"japanese", "romaji", "english", "parts", "groups", "correct_count", "wrong_count"}, records[0])
+	assert.Equal(t, []string{"こんにちは", "konnichiwa", "hello", `{"type":"greeting"}`, "Basic Greetings", "1", "0"}, records[1])
+	assert.Equal(t, "", records[2][4])
+}
+
+func TestExportAnki(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	db := setupTestDB(t)
+	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
+
+	r.GET("/api/groups/:id/export", ExportGroup(svc.Groups, svc.Words))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/groups/1/export?format=anki", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Disposition"), `filename="Basic_Greetings.txt"`)
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	assert.Equal(t, "#separator:tab", lines[0])
+	assert.Equal(t, "こんにちは\thello (konnichiwa)\tBasic_Greetings", lines[len(lines)-1])
+}
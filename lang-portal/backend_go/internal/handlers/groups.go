@@ -1,194 +1,230 @@
 package handlers
 
 import (
-	"database/sql"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+	"lang-portal/backend_go/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
 
-func GetGroups(db *sql.DB) gin.HandlerFunc {
+func GetGroups(groups *service.GroupService, limits PageLimits) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-		perPage := 100
-		offset := (page - 1) * perPage
+		list, err := parseList(c, limits, repository.GroupSorts)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 
-		// Get total count
-		var total int
-		err := db.QueryRow("SELECT COUNT(*) FROM groups").Scan(&total)
+		items, total, err := groups.List(c.Request.Context(), list.opts)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
-		// Get paginated groups with word counts
-		rows, err := db.Query(`
-			SELECT 
-				g.id,
-				g.name,
-				COUNT(DISTINCT wg.word_id) as word_count
-			FROM groups g
-			LEFT JOIN word_groups wg ON wg.group_id = g.id
-			GROUP BY g.id
-			LIMIT ? OFFSET ?
-		`, perPage, offset)
+		c.JSON(http.StatusOK, gin.H{
+			"items":      items,
+			"pagination": list.pagination(total),
+		})
+	}
+}
+
+func GetGroup(groups *service.GroupService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+			return
+		}
+
+		group, stats, err := groups.Get(c.Request.Context(), id)
+		if errors.Is(err, service.ErrGroupNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+			return
+		}
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
-		defer rows.Close()
 
-		var groups []struct {
-			models.Group
-			WordCount int `json:"word_count"`
+		c.JSON(http.StatusOK, gin.H{
+			"id":    group.ID,
+			"name":  group.Name,
+			"stats": stats,
+		})
+	}
+}
+
+func GetGroupWords(groups *service.GroupService, limits PageLimits) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		groupID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+			return
+		}
+
+		list, err := parseList(c, limits, repository.WordSorts)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
 		}
 
-		for rows.Next() {
-			var group struct {
-				models.Group
-				WordCount int `json:"word_count"`
-			}
-			err := rows.Scan(&group.ID, &group.Name, &group.WordCount)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-				return
-			}
-			groups = append(groups, group)
+		words, total, err := groups.Words(c.Request.Context(), currentUser(c).ID, groupID, list.opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{
-			"items": groups,
-			"pagination": gin.H{
-				"current_page":   page,
-				"total_pages":    (total + perPage - 1) / perPage,
-				"total_items":    total,
-				"items_per_page": perPage,
-			},
+			"items":      words,
+			"pagination": list.pagination(total),
 		})
 	}
 }
 
-func GetGroup(db *sql.DB) gin.HandlerFunc {
+func GetGroupStudySessions(groups *service.GroupService, limits PageLimits) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		groupID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
 			return
 		}
 
-		group, err := models.GetGroup(db, id)
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		list, err := parseList(c, limits, repository.SessionSorts)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		filter, err := parseSessionFilter(c)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		stats, err := models.GetGroupStats(db, id)
+		sessions, total, err := groups.Sessions(c.Request.Context(), currentUser(c).ID, groupID, filter, list.opts)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{
-			"id":    group.ID,
-			"name":  group.Name,
-			"stats": stats,
+			"items":      sessions,
+			"pagination": list.pagination(total),
 		})
 	}
 }
 
-func GetGroupWords(db *sql.DB) gin.HandlerFunc {
+func CreateGroup(groups *service.GroupService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		groupID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		var request struct {
+			Name string `json:"name" binding:"required"`
+		}
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		group, err := groups.Create(c.Request.Context(), request.Name)
+		var invalid *service.ValidationError
+		if errors.As(err, &invalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": invalid.Message})
+			return
+		}
+		if errors.Is(err, models.ErrDuplicateGroupName) {
+			c.JSON(http.StatusConflict, gin.H{"error": "A group with this name already exists"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, group)
+	}
+}
+
+func UpdateGroup(groups *service.GroupService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
 			return
 		}
 
-		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-		perPage := 100
-		offset := (page - 1) * perPage
+		var request struct {
+			Name string `json:"name" binding:"required"`
+		}
 
-		// Get total count
-		var total int
-		err = db.QueryRow(`
-			SELECT COUNT(*) 
-			FROM word_groups 
-			WHERE group_id = ?
-		`, groupID).Scan(&total)
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		group, err := groups.Rename(c.Request.Context(), id, request.Name)
+		var invalid *service.ValidationError
+		if errors.As(err, &invalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": invalid.Message})
+			return
+		}
+		if errors.Is(err, service.ErrGroupNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+			return
+		}
+		if errors.Is(err, models.ErrDuplicateGroupName) {
+			c.JSON(http.StatusConflict, gin.H{"error": "A group with this name already exists"})
+			return
+		}
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
-		// Get paginated words with stats
-		rows, err := db.Query(`
-			SELECT 
-				w.id,
-				w.japanese,
-				w.romaji,
-				w.english,
-				COUNT(CASE WHEN wri.correct = 1 THEN 1 END) as correct_count,
-				COUNT(CASE WHEN wri.correct = 0 THEN 1 END) as wrong_count
-			FROM words w
-			JOIN word_groups wg ON wg.word_id = w.id
-			LEFT JOIN word_review_items wri ON wri.word_id = w.id
-			WHERE wg.group_id = ?
-			GROUP BY w.id
-			LIMIT ? OFFSET ?
-		`, groupID, perPage, offset)
+		c.JSON(http.StatusOK, group)
+	}
+}
+
+func DeleteGroup(groups *service.GroupService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+			return
+		}
+
+		err = groups.Delete(c.Request.Context(), id)
+		if errors.Is(err, service.ErrGroupNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+			return
+		}
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
-		defer rows.Close()
-
-		var words []struct {
-			models.Word
-			CorrectCount int `json:"correct_count"`
-			WrongCount   int `json:"wrong_count"`
-		}
-
-		for rows.Next() {
-			var word struct {
-				models.Word
-				CorrectCount int `json:"correct_count"`
-				WrongCount   int `json:"wrong_count"`
-			}
-			err := rows.Scan(
-				&word.ID,
-				&word.Japanese,
-				&word.Romaji,
-				&word.English,
-				&word.CorrectCount,
-				&word.WrongCount,
-			)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-				return
-			}
-			words = append(words, word)
-		}
 
 		c.JSON(http.StatusOK, gin.H{
-			"items": words,
-			"pagination": gin.H{
-				"current_page":   page,
-				"total_pages":    (total + perPage - 1) / perPage,
-				"total_items":    total,
-				"items_per_page": perPage,
-			},
+			"success": true,
+			"message": "Group deleted successfully",
 		})
 	}
 }
 
-func GetGroupStudySessions(db *sql.DB) gin.HandlerFunc {
+// AddGroupWords adds existing words to a group. Words that are already
+// members are left alone.
+func AddGroupWords(groups *service.GroupService) gin.HandlerFunc {
+	return updateGroupWords(groups, true)
+}
+
+// RemoveGroupWords removes words from a group without deleting them.
+func RemoveGroupWords(groups *service.GroupService) gin.HandlerFunc {
+	return updateGroupWords(groups, false)
+}
+
+func updateGroupWords(groups *service.GroupService, add bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		groupID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 		if err != nil {
@@ -196,72 +232,46 @@ func GetGroupStudySessions(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-		perPage := 100
-		offset := (page - 1) * perPage
+		var request struct {
+			WordIDs []int64 `json:"word_ids" binding:"required,min=1"`
+		}
 
-		// Get total count
-		var total int
-		err = db.QueryRow(`
-			SELECT COUNT(*) 
-			FROM study_sessions 
-			WHERE group_id = ?
-		`, groupID).Scan(&total)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Count error: " + err.Error()})
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		// Get paginated study sessions with simpler query first
-		rows, err := db.Query(`
-			SELECT 
-				ss.id,
-				sa.name,
-				g.name,
-				ss.created_at,
-				ss.created_at,
-				0
-			FROM study_sessions ss
-			JOIN study_activities sa ON sa.id = ss.study_activity_id
-			JOIN groups g ON g.id = ss.group_id
-			WHERE ss.group_id = ?
-			ORDER BY ss.created_at DESC
-			LIMIT ? OFFSET ?
-		`, groupID, perPage, offset)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Query error: " + err.Error()})
+		var changed int
+		if add {
+			changed, err = groups.AddWords(c.Request.Context(), groupID, request.WordIDs)
+		} else {
+			changed, err = groups.RemoveWords(c.Request.Context(), groupID, request.WordIDs)
+		}
+
+		var missing *service.MissingWordsError
+		if errors.Is(err, service.ErrGroupNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
 			return
 		}
-		defer rows.Close()
-
-		var sessions []models.StudySessionDetail
-		for rows.Next() {
-			var session models.StudySessionDetail
-			err := rows.Scan(
-				&session.ID,
-				&session.ActivityName,
-				&session.GroupName,
-				&session.StartTime,
-				&session.EndTime,
-				&session.ReviewItemCount,
-			)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Scan error: " + err.Error()})
-				return
-			}
-			sessions = append(sessions, session)
+		if errors.As(err, &missing) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":            "Words not found",
+				"missing_word_ids": missing.IDs,
+			})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
 		}
 
+		key := "removed"
+		if add {
+			key = "added"
+		}
 		c.JSON(http.StatusOK, gin.H{
-			"items": sessions,
-			"pagination": gin.H{
-				"current_page":   page,
-				"total_pages":    (total + perPage - 1) / perPage,
-				"total_items":    total,
-				"items_per_page": perPage,
-			},
+			"success": true,
+			key:       changed,
 		})
 	}
 }
-
-// ... continuing with more handlers ...
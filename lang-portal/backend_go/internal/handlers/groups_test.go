@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -16,8 +17,10 @@ func TestGetGroups(t *testing.T) {
 	r := gin.New()
 	db := setupTestDB(t)
 	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
 
-	r.GET("/api/groups", GetGroups(db))
+	r.GET("/api/groups", GetGroups(svc.Groups, testPages))
 
 	tests := []struct {
 		name       string
@@ -72,8 +75,10 @@ func TestGetGroup(t *testing.T) {
 	r := gin.New()
 	db := setupTestDB(t)
 	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
 
-	r.GET("/api/groups/:id", GetGroup(db))
+	r.GET("/api/groups/:id", GetGroup(svc.Groups))
 
 	tests := []struct {
 		name       string
@@ -130,8 +135,10 @@ func TestGetGroupWords(t *testing.T) {
 	r := gin.New()
 	db := setupTestDB(t)
 	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
 
-	r.GET("/api/groups/:id/words", GetGroupWords(db))
+	r.GET("/api/groups/:id/words", GetGroupWords(svc.Groups, testPages))
 
 	tests := []struct {
 		name       string
@@ -200,8 +207,10 @@ func TestGetGroupStudySessions(t *testing.T) {
 	r := gin.New()
 	db := setupTestDB(t)
 	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
 
-	r.GET("/api/groups/:id/study-sessions", GetGroupStudySessions(db))
+	r.GET("/api/groups/:id/study-sessions", GetGroupStudySessions(svc.Groups, testPages))
 
 	tests := []struct {
 		name       string
@@ -272,3 +281,264 @@ func TestGetGroupStudySessions(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateGroup(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	db := setupTestDB(t)
+	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
+
+	r.POST("/api/groups", CreateGroup(svc.Groups))
+
+	tests := []struct {
+		name       string
+		payload    string
+		wantStatus int
+	}{
+		{
+			name:       "Valid group",
+			payload:    `{"name":"Lesson 1"}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "Duplicate name",
+			payload:    `{"name":"Basic Greetings"}`,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "Blank name",
+			payload:    `{"name":"   "}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Missing name",
+			payload:    `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/groups", bytes.NewBufferString(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			if tt.wantStatus == http.StatusCreated {
+				var response struct {
+					ID   int64  `json:"id"`
+					Name string `json:"name"`
+				}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.NotZero(t, response.ID)
+				assert.Equal(t, "Lesson 1", response.Name)
+			}
+		})
+	}
+}
+
+func TestUpdateGroup(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	db := setupTestDB(t)
+	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
+
+	_, err := db.Exec("INSERT INTO groups (name) VALUES ('Numbers')")
+	assert.NoError(t, err)
+
+	r.PATCH("/api/groups/:id", UpdateGroup(svc.Groups))
+
+	tests := []struct {
+		name       string
+		groupID    string
+		payload    string
+		wantStatus int
+	}{
+		{
+			name:       "Rename",
+			groupID:    "1",
+			payload:    `{"name":"Greetings"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Name taken",
+			groupID:    "1",
+			payload:    `{"name":"Numbers"}`,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "Invalid group",
+			groupID:    "999",
+			payload:    `{"name":"Colors"}`,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Invalid ID format",
+			groupID:    "abc",
+			payload:    `{"name":"Colors"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("PATCH", fmt.Sprintf("/api/groups/%s", tt.groupID), bytes.NewBufferString(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestDeleteGroup(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	db := setupTestDB(t)
+	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
+
+	r.DELETE("/api/groups/:id", DeleteGroup(svc.Groups))
+
+	tests := []struct {
+		name       string
+		groupID    string
+		wantStatus int
+	}{
+		{
+			name:       "Valid group",
+			groupID:    "1",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Already deleted",
+			groupID:    "1",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Invalid ID format",
+			groupID:    "abc",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("DELETE", fmt.Sprintf("/api/groups/%s", tt.groupID), nil)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+
+	// Words survive, memberships and sessions do not
+	var words, memberships, sessions int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM words").Scan(&words))
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM word_groups").Scan(&memberships))
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM study_sessions").Scan(&sessions))
+	assert.Equal(t, 3, words)
+	assert.Zero(t, memberships)
+	assert.Zero(t, sessions)
+}
+
+func TestGroupWordMembership(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	db := setupTestDB(t)
+	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
+
+	r.POST("/api/groups/:id/words", AddGroupWords(svc.Groups))
+	r.DELETE("/api/groups/:id/words", RemoveGroupWords(svc.Groups))
+
+	tests := []struct {
+		name        string
+		method      string
+		groupID     string
+		payload     string
+		wantStatus  int
+		wantChanged int
+	}{
+		{
+			name:        "Add new and existing members",
+			method:      "POST",
+			groupID:     "1",
+			payload:     `{"word_ids":[1,2,3]}`,
+			wantStatus:  http.StatusOK,
+			wantChanged: 2,
+		},
+		{
+			name:       "Add unknown word",
+			method:     "POST",
+			groupID:    "1",
+			payload:    `{"word_ids":[2,999]}`,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Add to unknown group",
+			method:     "POST",
+			groupID:    "999",
+			payload:    `{"word_ids":[1]}`,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Empty word list",
+			method:     "POST",
+			groupID:    "1",
+			payload:    `{"word_ids":[]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "Remove members",
+			method:      "DELETE",
+			groupID:     "1",
+			payload:     `{"word_ids":[1,3,999]}`,
+			wantStatus:  http.StatusOK,
+			wantChanged: 2,
+		},
+		{
+			name:       "Remove from unknown group",
+			method:     "DELETE",
+			groupID:    "999",
+			payload:    `{"word_ids":[1]}`,
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			url := fmt.Sprintf("/api/groups/%s/words", tt.groupID)
+			req, _ := http.NewRequest(tt.method, url, bytes.NewBufferString(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			if tt.wantStatus == http.StatusOK {
+				var response struct {
+					Added   int `json:"added"`
+					Removed int `json:"removed"`
+				}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantChanged, response.Added+response.Removed)
+			}
+		})
+	}
+
+	var memberships int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM word_groups WHERE group_id = 1").Scan(&memberships))
+	assert.Equal(t, 1, memberships)
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImportGroupWords bulk-imports vocabulary into a group. The body may be the
+// output of the vocabulary-importer app or a seed file from db/seeds.
+func ImportGroupWords(groups *service.GroupService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		groupID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+			return
+		}
+
+		body, err := c.GetRawData()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		words, err := models.ParseImport(body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid import: " + err.Error()})
+			return
+		}
+		if len(words) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Import contains no words"})
+			return
+		}
+
+		results, err := groups.Import(c.Request.Context(), groupID, words)
+		if errors.Is(err, service.ErrGroupNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		summary := map[string]int{
+			models.ImportCreated: 0,
+			models.ImportLinked:  0,
+			models.ImportSkipped: 0,
+		}
+		for _, result := range results {
+			summary[result.Status]++
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"group_id": groupID,
+			"summary":  summary,
+			"items":    results,
+		})
+	}
+}
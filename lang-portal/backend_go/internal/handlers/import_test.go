@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportGroupWords(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	db := setupTestDB(t)
+	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
+
+	r.POST("/api/groups/:id/import", ImportGroupWords(svc.Groups))
+
+	importerPayload := `{
+		"vocabulary": [
+			{"kanji": "食べる", "romaji": "taberu", "english": "eat",
+			 "parts": [{"kanji": "食", "romaji": "ta"}, {"kanji": "べる", "romaji": "beru"}]},
+			{"kanji": "さようなら", "romaji": "sayounara", "english": "Goodbye", "parts": []},
+			{"kanji": "こんにちは", "romaji": "konnichiwa", "english": "hello", "parts": []},
+			{"kanji": "", "romaji": "nani", "english": "what", "parts": []}
+		]
+	}`
+
+	seedPayload := `{
+		"group_name": "Basic Greetings",
+		"words": [
+			{"japanese": "ありがとう", "romaji": "arigatou", "english": "thank you",
+			 "parts": {"type": "greeting", "politeness": "casual"}},
+			{"japanese": "食べる", "romaji": "taberu", "english": "eat", "parts": {}}
+		]
+	}`
+
+	type item struct {
+		Japanese string `json:"japanese"`
+		Status   string `json:"status"`
+		WordID   int64  `json:"word_id"`
+	}
+
+	tests := []struct {
+		name        string
+		groupID     string
+		payload     string
+		wantStatus  int
+		wantItems   []item
+		wantSummary map[string]int
+	}{
+		{
+			name:       "Importer format",
+			groupID:    "1",
+			payload:    importerPayload,
+			wantStatus: http.StatusOK,
+			wantItems: []item{
+				{Japanese: "食べる", Status: "created", WordID: 4},
+				{Japanese: "さようなら", Status: "linked", WordID: 2},
+				{Japanese: "こんにちは", Status: "skipped", WordID: 1},
+				{Japanese: "", Status: "skipped"},
+			},
+			wantSummary: map[string]int{"created": 1, "linked": 1, "skipped": 2},
+		},
+		{
+			name:       "Seed file format",
+			groupID:    "1",
+			payload:    seedPayload,
+			wantStatus: http.StatusOK,
+			wantItems: []item{
+				{Japanese: "ありがとう", Status: "linked", WordID: 3},
+				{Japanese: "食べる", Status: "skipped", WordID: 4},
+			},
+			wantSummary: map[string]int{"created": 0, "linked": 1, "skipped": 1},
+		},
+		{
+			name:       "Unknown format",
+			groupID:    "1",
+			payload:    `{"items": []}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Empty import",
+			groupID:    "1",
+			payload:    `{"vocabulary": []}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid JSON",
+			groupID:    "1",
+			payload:    `{"vocabulary": [`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "Segments with several romaji",
+			groupID:     "1",
+			payload:     `{"vocabulary": [{"kanji": "晴れ", "romaji": "hare", "english": "sunny", "parts": [{"kanji": "晴", "romaji": ["ha"]}, {"kanji": "れ", "romaji": ["r", "e"]}]}]}`,
+			wantStatus:  http.StatusOK,
+			wantItems:   []item{{Japanese: "晴れ", Status: "created", WordID: 5}},
+			wantSummary: map[string]int{"created": 1, "linked": 0, "skipped": 0},
+		},
+		{
+			name:       "Malformed parts",
+			groupID:    "1",
+			payload:    `{"vocabulary": [{"kanji": "雨", "romaji": "ame", "english": "rain", "parts": [{"kanji": "雨", "romaji": 5}]}]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid group",
+			groupID:    "999",
+			payload:    importerPayload,
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			url := fmt.Sprintf("/api/groups/%s/import", tt.groupID)
+			req, _ := http.NewRequest("POST", url, bytes.NewBufferString(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			if tt.wantStatus == http.StatusOK {
+				var response struct {
+					Summary map[string]int `json:"summary"`
+					Items   []item         `json:"items"`
+				}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantSummary, response.Summary)
+				assert.Equal(t, tt.wantItems, response.Items)
+			}
+		})
+	}
+
+	var words, memberships int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM words").Scan(&words))
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM word_groups WHERE group_id = 1").Scan(&memberships))
+	assert.Equal(t, 5, words)
+	assert.Equal(t, 5, memberships)
+
+	// The importer's segments are stored in the structured parts, with
+	// readings split into several romaji joined
+	var parts string
+	assert.NoError(t, db.QueryRow("SELECT parts FROM words WHERE id = 4").Scan(&parts))
+	assert.JSONEq(t, `{"segments":[{"kanji":"食","romaji":"ta"},{"kanji":"べる","romaji":"beru"}]}`, parts)
+	assert.NoError(t, db.QueryRow("SELECT parts FROM words WHERE id = 5").Scan(&parts))
+	assert.JSONEq(t, `{"segments":[{"kanji":"晴","romaji":"ha"},{"kanji":"れ","romaji":"re"}]}`, parts)
+}
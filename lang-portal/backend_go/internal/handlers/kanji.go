@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"unicode"
+	"unicode/utf8"
+
+	"lang-portal/backend_go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetKanji returns a kanji with the words written with it and the user's
+// review statistics over them.
+func GetKanji(kanji *service.KanjiService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		character := c.Param("char")
+		r, size := utf8.DecodeRuneInString(character)
+		if size != len(character) || !unicode.Is(unicode.Han, r) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid kanji, expected a single character"})
+			return
+		}
+
+		detail, err := kanji.Get(c.Request.Context(), currentUser(c).ID, character)
+		if errors.Is(err, service.ErrKanjiNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Kanji not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"id":           detail.ID,
+			"character":    detail.Character,
+			"on_readings":  detail.OnReadings,
+			"kun_readings": detail.KunReadings,
+			"meanings":     detail.Meanings,
+			"stroke_count": detail.StrokeCount,
+			"jlpt_level":   detail.JLPTLevel,
+			"words":        detail.Words,
+			"stats":        detail.Stats,
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetKanji(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	db := setupTestDB(t)
+	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
+
+	r.GET("/api/kanji/:char", GetKanji(svc.Kanji))
+
+	_, err := db.Exec(`INSERT INTO kanji (literal, on_readings, kun_readings, meanings, stroke_count, jlpt_level)
+		VALUES ('食', '["ショク","ジキ"]', '["た.べる","く.う"]', '["eat","food"]', 9, 5)`)
+	assert.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO words (japanese, romaji, english, parts) VALUES ('食べる', 'taberu', 'eat', '{}')`)
+	assert.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO word_review_items (word_id, study_session_id, correct) VALUES (4, 1, true), (4, 1, false)`)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		char       string
+		wantStatus int
+	}{
+		{"Known kanji", "食", http.StatusOK},
+		{"Unknown kanji", "水", http.StatusNotFound},
+		{"Kana", "た", http.StatusBadRequest},
+		{"Several characters", "食べ", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/kanji/"+tt.char, nil)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			if tt.wantStatus == http.StatusOK {
+				var response struct {
+					Character   string   `json:"character"`
+					OnReadings  []string `json:"on_readings"`
+					KunReadings []string `json:"kun_readings"`
+					Meanings    []string `json:"meanings"`
+					StrokeCount int      `json:"stroke_count"`
+					JLPTLevel   *int     `json:"jlpt_level"`
+					Words       []struct {
+						Japanese     string `json:"japanese"`
+						CorrectCount int    `json:"correct_count"`
+						WrongCount   int    `json:"wrong_count"`
+					} `json:"words"`
+					Stats struct {
+						CorrectCount int     `json:"correct_count"`
+						WrongCount   int     `json:"wrong_count"`
+						SuccessRate  float64 `json:"success_rate"`
+					} `json:"stats"`
+				}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, "食", response.Character)
+				assert.Equal(t, []string{"ショク", "ジキ"}, response.OnReadings)
+				assert.Equal(t, []string{"た.べる", "く.う"}, response.KunReadings)
+				assert.Equal(t, []string{"eat", "food"}, response.Meanings)
+				assert.Equal(t, 9, response.StrokeCount)
+				if assert.NotNil(t, response.JLPTLevel) {
+					assert.Equal(t, 5, *response.JLPTLevel)
+				}
+				if assert.Len(t, response.Words, 1) {
+					assert.Equal(t, "食べる", response.Words[0].Japanese)
+					assert.Equal(t, 1, response.Words[0].CorrectCount)
+				}
+				assert.Equal(t, float64(50), response.Stats.SuccessRate)
+			}
+		})
+	}
+}
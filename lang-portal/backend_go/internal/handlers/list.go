@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"lang-portal/backend_go/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PageLimits are the page size of list endpoints when the client does not
+// ask for one with per_page, and the largest it may ask for.
+type PageLimits struct {
+	Default int
+	Max     int
+}
+
+// listParams are the page, per_page, sort and order query parameters of a
+// list endpoint.
+type listParams struct {
+	page    int
+	perPage int
+	opts    repository.ListOptions
+}
+
+// parseList reads the list parameters of the request. sorts are the fields
+// the endpoint can be sorted by.
+func parseList(c *gin.Context, limits PageLimits, sorts []string) (listParams, error) {
+	p := listParams{page: 1, perPage: limits.Default}
+
+	if v, ok := c.GetQuery("page"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return p, errors.New("page must be a positive integer")
+		}
+		p.page = n
+	}
+
+	if v, ok := c.GetQuery("per_page"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > limits.Max {
+			return p, fmt.Errorf("per_page must be between 1 and %d", limits.Max)
+		}
+		p.perPage = n
+	}
+
+	if v, ok := c.GetQuery("sort"); ok {
+		if !slices.Contains(sorts, v) {
+			return p, fmt.Errorf("sort must be one of %s", strings.Join(sorts, ", "))
+		}
+		p.opts.Sort = v
+	}
+
+	if v, ok := c.GetQuery("order"); ok {
+		switch {
+		case v != "asc" && v != "desc":
+			return p, errors.New("order must be asc or desc")
+		case p.opts.Sort == "":
+			return p, errors.New("order needs sort")
+		}
+		p.opts.Desc = v == "desc"
+	}
+
+	// The offset of a page that far out would overflow
+	if p.page-1 > math.MaxInt/p.perPage {
+		return p, errors.New("page must be a positive integer")
+	}
+	p.opts.Offset = (p.page - 1) * p.perPage
+	p.opts.Limit = p.perPage
+	return p, nil
+}
+
+// pagination describes the page for the response.
+func (p listParams) pagination(total int) gin.H {
+	return gin.H{
+		"current_page":   p.page,
+		"total_pages":    (total + p.perPage - 1) / p.perPage,
+		"total_items":    total,
+		"items_per_page": p.perPage,
+	}
+}
+
+// parseKeyset reads the per_page and cursor query parameters of a list read
+// with cursors. Such lists have no page numbers and a fixed order.
+func parseKeyset(c *gin.Context, limits PageLimits) (repository.Keyset, error) {
+	keyset := repository.Keyset{Limit: limits.Default}
+
+	for _, name := range []string{"page", "sort", "order"} {
+		if _, ok := c.GetQuery(name); ok {
+			return keyset, fmt.Errorf("%s cannot be combined with cursor", name)
+		}
+	}
+
+	if v, ok := c.GetQuery("per_page"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > limits.Max {
+			return keyset, fmt.Errorf("per_page must be between 1 and %d", limits.Max)
+		}
+		keyset.Limit = n
+	}
+
+	if v := c.Query("cursor"); v != "" {
+		after, err := decodeCursor(v)
+		if err != nil {
+			return keyset, err
+		}
+		keyset.After = after
+	}
+	return keyset, nil
+}
+
+// nextCursor is the next_cursor of a response: the token of the next page,
+// or null on the last page.
+func nextCursor(next repository.Cursor) any {
+	if next.IsZero() {
+		return nil
+	}
+	return encodeCursor(next)
+}
+
+// Cursor tokens are opaque to clients. They hold the position's time in
+// Unix nanoseconds and its ID.
+
+func encodeCursor(cursor repository.Cursor) string {
+	raw := strconv.FormatInt(cursor.Time.UnixNano(), 10) + "." + strconv.FormatInt(cursor.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(token string) (repository.Cursor, error) {
+	invalid := errors.New("cursor is not valid")
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return repository.Cursor{}, invalid
+	}
+	at, id, ok := strings.Cut(string(raw), ".")
+	if !ok {
+		return repository.Cursor{}, invalid
+	}
+	nanos, err := strconv.ParseInt(at, 10, 64)
+	if err != nil {
+		return repository.Cursor{}, invalid
+	}
+	cursor := repository.Cursor{Time: time.Unix(0, nanos).UTC()}
+	if cursor.ID, err = strconv.ParseInt(id, 10, 64); err != nil || cursor.ID < 1 {
+		return repository.Cursor{}, invalid
+	}
+	return cursor, nil
+}
+
+// parseSessionFilter reads the from and to query parameters bounding the
+// start time of listed sessions. Both take a date, to inclusive, or an RFC
+// 3339 time, to exclusive.
+func parseSessionFilter(c *gin.Context) (repository.SessionFilter, error) {
+	var filter repository.SessionFilter
+	var err error
+
+	if v := c.Query("from"); v != "" {
+		if filter.From, err = parseBound(v, false); err != nil {
+			return filter, fmt.Errorf("from: %v", err)
+		}
+	}
+	if v := c.Query("to"); v != "" {
+		if filter.To, err = parseBound(v, true); err != nil {
+			return filter, fmt.Errorf("to: %v", err)
+		}
+	}
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return filter, errors.New("from must be before to")
+	}
+	return filter, nil
+}
+
+// parseBound parses a date or RFC 3339 time. A date as an upper bound
+// includes the whole day.
+func parseBound(v string, upper bool) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, v); err == nil {
+		if upper {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, errors.New("must be a date like 2024-03-10 or an RFC 3339 time")
+	}
+	return t.UTC(), nil
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListParams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	db := setupTestDB(t)
+	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
+
+	r.GET("/api/words", GetWords(svc.Words, PageLimits{Default: 2, Max: 50}))
+
+	tests := []struct {
+		name        string
+		query       string
+		wantStatus  int
+		wantEnglish []string
+		wantPerPage int
+	}{
+		{"default page size", "", http.StatusOK, []string{"hello", "goodbye"}, 2},
+		{"sorted", "?sort=english", http.StatusOK, []string{"goodbye", "hello"}, 2},
+		{"sorted descending", "?sort=english&order=desc&per_page=3", http.StatusOK, []string{"thank you", "hello", "goodbye"}, 3},
+		{"second page", "?sort=romaji&page=2", http.StatusOK, []string{"goodbye"}, 2},
+		{"sorted by stats", "?sort=correct_count&order=desc&per_page=1", http.StatusOK, []string{"hello"}, 1},
+		{"page zero", "?page=0", http.StatusBadRequest, nil, 0},
+		{"page not a number", "?page=abc", http.StatusBadRequest, nil, 0},
+		{"page past the last offset", "?page=9223372036854775807&per_page=2", http.StatusBadRequest, nil, 0},
+		{"per_page over the limit", "?per_page=51", http.StatusBadRequest, nil, 0},
+		{"unknown sort", "?sort=parts", http.StatusBadRequest, nil, 0},
+		{"unknown order", "?sort=english&order=up", http.StatusBadRequest, nil, 0},
+		{"order without sort", "?order=desc", http.StatusBadRequest, nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/words"+tt.query, nil)
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+
+			var response struct {
+				Items []struct {
+					English string `json:"english"`
+				} `json:"items"`
+				Pagination struct {
+					ItemsPerPage int `json:"items_per_page"`
+				} `json:"pagination"`
+				Error string `json:"error"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+			if tt.wantStatus != http.StatusOK {
+				assert.NotEmpty(t, response.Error)
+				return
+			}
+			var english []string
+			for _, item := range response.Items {
+				english = append(english, item.English)
+			}
+			assert.Equal(t, tt.wantEnglish, english)
+			assert.Equal(t, tt.wantPerPage, response.Pagination.ItemsPerPage)
+		})
+	}
+}
+
+func TestSessionDateFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	db := setupTestDB(t)
+	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
+
+	r.GET("/api/study-sessions", GetStudySessions(svc.Sessions, testPages))
+
+	// The test session starts now
+	today := time.Now().UTC().Format(time.DateOnly)
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format(time.DateOnly)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantItems  int
+	}{
+		{"whole day", "?from=" + today + "&to=" + today, http.StatusOK, 1},
+		{"before", "?to=" + yesterday, http.StatusOK, 0},
+		{"since", "?from=" + yesterday, http.StatusOK, 1},
+		{"time", "?from=" + time.Now().Add(time.Hour).UTC().Format(time.RFC3339), http.StatusOK, 0},
+		{"bad date", "?from=10/03/2024", http.StatusBadRequest, 0},
+		{"empty range", "?from=" + today + "&to=" + yesterday, http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/study-sessions"+tt.query, nil)
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Items []json.RawMessage `json:"items"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Len(t, response.Items, tt.wantItems)
+		})
+	}
+}
+
+func TestCursorPagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	db := setupTestDB(t)
+	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
+
+	r.GET("/api/words/:id/reviews", GetWordReviews(svc.Words, testPages))
+	r.GET("/api/study-sessions", GetStudySessions(svc.Sessions, testPages))
+	r.GET("/api/study-sessions/:id/words", GetStudySessionWords(svc.Sessions, testPages))
+
+	// Four more reviews of word 1, two of them in the same second
+	for _, at := range []string{"-3 minutes", "-2 minutes", "-1 minute", "-1 minute"} {
+		_, err := db.Exec(`INSERT INTO word_review_items (word_id, study_session_id, correct, created_at)
+			VALUES (1, 1, false, datetime('now', ?))`, at)
+		require.NoError(t, err)
+	}
+	for i := 0; i < 2; i++ {
+		_, err := db.Exec(`INSERT INTO study_sessions (group_id, study_activity_id, created_at)
+			VALUES (1, 1, datetime('now', '-1 day'))`)
+		require.NoError(t, err)
+	}
+
+	// walk follows next_cursor from url and returns the field of every item
+	walk := func(t *testing.T, url, field string) []int64 {
+		var ids []int64
+		cursor := ""
+		for pages := 0; pages < 10; pages++ {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", url+"&cursor="+cursor, nil)
+			r.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			var response struct {
+				Items      []map[string]any `json:"items"`
+				NextCursor *string          `json:"next_cursor"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.LessOrEqual(t, len(response.Items), 2)
+			for _, item := range response.Items {
+				ids = append(ids, int64(item[field].(float64)))
+			}
+
+			if response.NextCursor == nil {
+				return ids
+			}
+			cursor = *response.NextCursor
+		}
+		t.Fatal("next_cursor never ran out")
+		return nil
+	}
+
+	t.Run("word reviews", func(t *testing.T) {
+		// Newest first, the same second by ID
+		assert.Equal(t, []int64{1, 5, 4, 3, 2}, walk(t, "/api/words/1/reviews?per_page=2", "id"))
+	})
+
+	t.Run("session words", func(t *testing.T) {
+		// The review IDs in review order
+		assert.Equal(t, []int64{2, 3, 4, 5, 1}, walk(t, "/api/study-sessions/1/words?per_page=2", "review_id"))
+	})
+
+	t.Run("sessions", func(t *testing.T) {
+		assert.Equal(t, []int64{1, 3, 2}, walk(t, "/api/study-sessions?per_page=2", "id"))
+	})
+
+	tests := []struct {
+		name       string
+		url        string
+		wantStatus int
+	}{
+		{"unknown word", "/api/words/999/reviews", http.StatusNotFound},
+		{"invalid cursor", "/api/words/1/reviews?cursor=abc", http.StatusBadRequest},
+		{"page with cursor", "/api/study-sessions?cursor=&page=2", http.StatusBadRequest},
+		{"sort with cursor", "/api/study-sessions/1/words?sort=id", http.StatusBadRequest},
+		{"per_page over the limit", "/api/words/1/reviews?per_page=501", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+		})
+	}
+}
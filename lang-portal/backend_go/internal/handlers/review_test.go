@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type dueWordsResponse struct {
+	Items []struct {
+		ID       int64  `json:"id"`
+		Japanese string `json:"japanese"`
+		Schedule *struct {
+			EaseFactor   float64 `json:"ease_factor"`
+			IntervalDays int     `json:"interval_days"`
+			Repetitions  int     `json:"repetitions"`
+			DueAt        string  `json:"due_at"`
+		} `json:"schedule"`
+	} `json:"items"`
+}
+
+func TestGetDueWords(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	db := setupTestDB(t)
+	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
+
+	r.GET("/api/review/due", GetDueWords(svc.Reviews))
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantIDs    []int64
+	}{
+		{
+			name:       "All groups",
+			query:      "",
+			wantStatus: http.StatusOK,
+			wantIDs:    []int64{1, 3}, // Overdue word first, then the new word
+		},
+		{
+			name:       "Single group",
+			query:      "?group_id=1",
+			wantStatus: http.StatusOK,
+			wantIDs:    []int64{1},
+		},
+		{
+			name:       "Limit",
+			query:      "?limit=1",
+			wantStatus: http.StatusOK,
+			wantIDs:    []int64{1},
+		},
+		{
+			name:       "Unknown group",
+			query:      "?group_id=999",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Invalid group ID format",
+			query:      "?group_id=abc",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid limit",
+			query:      "?limit=0",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/review/due"+tt.query, nil)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			if tt.wantStatus == http.StatusOK {
+				var response dueWordsResponse
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+
+				var ids []int64
+				for _, item := range response.Items {
+					ids = append(ids, item.ID)
+				}
+				assert.Equal(t, tt.wantIDs, ids)
+			}
+		})
+	}
+}
+
+func TestCreateWordReviewUpdatesSchedule(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	db := setupTestDB(t)
+	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
+
+	r.GET("/api/review/due", GetDueWords(svc.Reviews))
+	r.POST("/api/study-sessions/:id/words/:word_id/review", CreateWordReview(svc.Reviews))
+
+	// A correct answer on the new word schedules it for tomorrow
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/study-sessions/1/words/3/review", bytes.NewBufferString(`{"correct":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var review struct {
+		Schedule struct {
+			IntervalDays int `json:"interval_days"`
+			Repetitions  int `json:"repetitions"`
+		} `json:"schedule"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &review))
+	assert.Equal(t, 1, review.Schedule.IntervalDays)
+	assert.Equal(t, 1, review.Schedule.Repetitions)
+
+	// A wrong answer on the overdue word resets it but keeps it due tomorrow
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/study-sessions/1/words/1/review", bytes.NewBufferString(`{"correct":false}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &review))
+	assert.Equal(t, 0, review.Schedule.Repetitions)
+
+	// Neither word is due any more
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/review/due", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var due dueWordsResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &due))
+	assert.Empty(t, due.Items)
+}
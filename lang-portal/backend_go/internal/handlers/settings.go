@@ -1,35 +1,40 @@
 package handlers
 
 import (
-	"database/sql"
+	"errors"
 	"net/http"
+	"strconv"
+
+	"lang-portal/backend_go/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
 
-func ResetHistory(db *sql.DB) gin.HandlerFunc {
+// ResetHistory resets the study history of the user given by the user_id
+// query parameter, or of the caller when it is absent.
+func ResetHistory(settings *service.SettingsService, users *service.UserService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		tx, err := db.Begin()
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-		defer tx.Rollback()
-
-		// Delete all study history
-		_, err = tx.Exec("DELETE FROM word_review_items")
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
+		userID := currentUser(c).ID
+		if raw := c.Query("user_id"); raw != "" {
+			id, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+				return
+			}
 
-		_, err = tx.Exec("DELETE FROM study_sessions")
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
+			_, err = users.Get(c.Request.Context(), id)
+			if errors.Is(err, service.ErrUserNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+				return
+			}
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			userID = id
 		}
 
-		if err := tx.Commit(); err != nil {
+		if err := settings.ResetHistory(c.Request.Context(), userID); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
@@ -41,34 +46,9 @@ func ResetHistory(db *sql.DB) gin.HandlerFunc {
 	}
 }
 
-func FullReset(db *sql.DB) gin.HandlerFunc {
+func FullReset(settings *service.SettingsService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		tx, err := db.Begin()
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-		defer tx.Rollback()
-
-		// Delete all data in reverse order of dependencies
-		tables := []string{
-			"word_review_items",
-			"study_sessions",
-			"study_activities",
-			"word_groups",
-			"words",
-			"groups",
-		}
-
-		for _, table := range tables {
-			_, err = tx.Exec("DELETE FROM " + table)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-				return
-			}
-		}
-
-		if err := tx.Commit(); err != nil {
+		if err := settings.FullReset(c.Request.Context()); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
@@ -78,4 +58,4 @@ func FullReset(db *sql.DB) gin.HandlerFunc {
 			"message": "System has been fully reset",
 		})
 	}
-} 
\ No newline at end of file
+}
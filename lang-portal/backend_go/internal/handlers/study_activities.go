@@ -1,233 +1,272 @@
 package handlers
 
 import (
-	"database/sql"
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+	"lang-portal/backend_go/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
 
+type studyActivityResponse struct {
+	ID                  int64    `json:"id"`
+	Slug                string   `json:"slug"`
+	Name                string   `json:"name"`
+	Description         string   `json:"description,omitempty"`
+	ThumbnailURL        string   `json:"thumbnail_url,omitempty"`
+	LaunchURL           string   `json:"launch_url,omitempty"`
+	SupportedGroupTypes []string `json:"supported_group_types"`
+	RequiredWordFields  []string `json:"required_word_fields"`
+}
+
+func newStudyActivityResponse(activity models.StudyActivity) studyActivityResponse {
+	response := studyActivityResponse{
+		ID:                  activity.ID,
+		Slug:                activity.Slug,
+		Name:                activity.Name,
+		Description:         activity.Description,
+		ThumbnailURL:        activity.ThumbnailURL,
+		LaunchURL:           activity.LaunchURL,
+		SupportedGroupTypes: activity.SupportedGroupTypes,
+		RequiredWordFields:  activity.RequiredWordFields,
+	}
+	if response.SupportedGroupTypes == nil {
+		response.SupportedGroupTypes = []string{}
+	}
+	if response.RequiredWordFields == nil {
+		response.RequiredWordFields = []string{}
+	}
+	return response
+}
+
 // GetStudyActivities returns all study activities
-func GetStudyActivities(db *sql.DB) gin.HandlerFunc {
+func GetStudyActivities(activities *service.ActivityService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		rows, err := db.Query(`
-			SELECT id, name, description, thumbnail_url
-			FROM study_activities
-			ORDER BY id
-		`)
+		items, err := activities.List(c.Request.Context())
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
-		defer rows.Close()
 
-		var activities []struct {
-			ID           int64          `json:"id"`
-			Name         string         `json:"name"`
-			Description  sql.NullString `json:"description"`
-			ThumbnailURL sql.NullString `json:"thumbnail_url"`
+		response := make([]studyActivityResponse, len(items))
+		for i, activity := range items {
+			response[i] = newStudyActivityResponse(activity)
 		}
 
-		for rows.Next() {
-			var activity struct {
-				ID           int64          `json:"id"`
-				Name         string         `json:"name"`
-				Description  sql.NullString `json:"description"`
-				ThumbnailURL sql.NullString `json:"thumbnail_url"`
-			}
-			if err := rows.Scan(&activity.ID, &activity.Name, &activity.Description, &activity.ThumbnailURL); err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-				return
-			}
-			activities = append(activities, activity)
-		}
+		c.JSON(http.StatusOK, response)
+	}
+}
 
-		if err = rows.Err(); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+func GetStudyActivity(activities *service.ActivityService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid activity ID"})
 			return
 		}
 
-		// Convert to response format
-		response := make([]struct {
-			ID           int64  `json:"id"`
-			Name         string `json:"name"`
-			Description  string `json:"description,omitempty"`
-			ThumbnailURL string `json:"thumbnail_url,omitempty"`
-		}, len(activities))
-
-		for i, activity := range activities {
-			response[i] = struct {
-				ID           int64  `json:"id"`
-				Name         string `json:"name"`
-				Description  string `json:"description,omitempty"`
-				ThumbnailURL string `json:"thumbnail_url,omitempty"`
-			}{
-				ID:           activity.ID,
-				Name:         activity.Name,
-				Description:  activity.Description.String,
-				ThumbnailURL: activity.ThumbnailURL.String,
-			}
+		activity, err := activities.Get(c.Request.Context(), id)
+		if errors.Is(err, service.ErrActivityNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
 		}
 
-		c.JSON(http.StatusOK, response)
+		c.JSON(http.StatusOK, newStudyActivityResponse(*activity))
 	}
 }
 
-func GetStudyActivity(db *sql.DB) gin.HandlerFunc {
+func GetStudyActivitySessions(activities *service.ActivityService, limits PageLimits) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		activityID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid activity ID"})
 			return
 		}
 
-		var activity struct {
-			ID           int64          `json:"id"`
-			Name         string         `json:"name"`
-			Description  sql.NullString `json:"description"`
-			ThumbnailURL sql.NullString `json:"thumbnail_url"`
+		list, err := parseList(c, limits, repository.SessionSorts)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
 		}
-
-		err = db.QueryRow(`
-			SELECT id, name, description, thumbnail_url
-			FROM study_activities
-			WHERE id = ?
-		`, id).Scan(&activity.ID, &activity.Name, &activity.Description, &activity.ThumbnailURL)
-
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+		filter, err := parseSessionFilter(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+
+		sessions, total, err := activities.Sessions(c.Request.Context(), currentUser(c).ID, activityID, filter, list.opts)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
-		response := struct {
-			ID           int64  `json:"id"`
-			Name         string `json:"name"`
-			Description  string `json:"description,omitempty"`
-			ThumbnailURL string `json:"thumbnail_url,omitempty"`
-		}{
-			ID:   activity.ID,
-			Name: activity.Name,
-			Description:  activity.Description.String,
-			ThumbnailURL: activity.ThumbnailURL.String,
-		}
-		c.JSON(http.StatusOK, response)
+		c.JSON(http.StatusOK, gin.H{
+			"items":      sessions,
+			"pagination": list.pagination(total),
+		})
 	}
 }
 
-func GetStudyActivitySessions(db *sql.DB) gin.HandlerFunc {
+// LaunchStudyActivity starts a study session of the activity and returns
+// the URL to open the activity's app at, carrying a launch token the app
+// uses to send its reviews back.
+func LaunchStudyActivity(activities *service.ActivityService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		activityID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid activity ID"})
 			return
 		}
 
-		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-		perPage := 100
-		offset := (page - 1) * perPage
+		var request struct {
+			GroupID int64 `json:"group_id" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 
-		// Get total count
-		var total int
-		err = db.QueryRow(`
-			SELECT COUNT(*) 
-			FROM study_sessions 
-			WHERE study_activity_id = ?
-		`, activityID).Scan(&total)
+		launch, err := activities.Launch(c.Request.Context(), currentUser(c).ID, id, request.GroupID, time.Now())
+		if errors.Is(err, service.ErrActivityNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+			return
+		}
+		if errors.Is(err, service.ErrGroupNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+			return
+		}
+		var invalid *service.ValidationError
+		if errors.As(err, &invalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": invalid.Message})
+			return
+		}
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
-		// Get paginated study sessions
-		rows, err := db.Query(`
-			SELECT 
-				ss.id,
-				sa.name as activity_name,
-				g.name as group_name,
-				ss.created_at as start_time,
-				MAX(wri.created_at) as end_time,
-				COUNT(wri.id) as review_items_count
-			FROM study_sessions ss
-			JOIN study_activities sa ON sa.id = ss.study_activity_id
-			JOIN groups g ON g.id = ss.group_id
-			LEFT JOIN word_review_items wri ON wri.study_session_id = ss.id
-			WHERE ss.study_activity_id = ?
-			GROUP BY ss.id
-			ORDER BY ss.created_at DESC
-			LIMIT ? OFFSET ?
-		`, activityID, perPage, offset)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusCreated, gin.H{
+			"study_session_id":  launch.Session.ID,
+			"group_id":          launch.Session.GroupID,
+			"study_activity_id": launch.Session.StudyActivityID,
+			"launch_url":        launch.URL,
+			"token":             launch.Token,
+			"expires_at":        launch.ExpiresAt,
+		})
+	}
+}
+
+// CreateStudyActivity registers an activity from a manifest in the request
+// body.
+func CreateStudyActivity(activities *service.ActivityService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var manifest service.ActivityManifest
+		if err := c.ShouldBindJSON(&manifest); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		defer rows.Close()
 
-		var sessions []models.StudySessionDetail
-		for rows.Next() {
-			var session models.StudySessionDetail
-			err := rows.Scan(
-				&session.ID,
-				&session.ActivityName,
-				&session.GroupName,
-				&session.StartTime,
-				&session.EndTime,
-				&session.ReviewItemCount,
-			)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-				return
-			}
-			sessions = append(sessions, session)
+		activity, err := activities.Create(c.Request.Context(), manifest)
+		var invalid *service.ValidationError
+		if errors.As(err, &invalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": invalid.Message})
+			return
+		}
+		if errors.Is(err, models.ErrDuplicateActivitySlug) {
+			c.JSON(http.StatusConflict, gin.H{"error": "An activity with this slug already exists"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{
-			"items": sessions,
-			"pagination": gin.H{
-				"current_page":   page,
-				"total_pages":    (total + perPage - 1) / perPage,
-				"total_items":    total,
-				"items_per_page": perPage,
-			},
-		})
+		c.JSON(http.StatusCreated, newStudyActivityResponse(*activity))
 	}
 }
 
-func CreateStudyActivity(db *sql.DB) gin.HandlerFunc {
+// UpdateStudyActivity changes the fields of an activity given in the
+// request body.
+func UpdateStudyActivity(activities *service.ActivityService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var request struct {
-			GroupID         int64 `json:"group_id" binding:"required"`
-			StudyActivityID int64 `json:"study_activity_id" binding:"required"`
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid activity ID"})
+			return
 		}
 
+		var request struct {
+			Slug                *string   `json:"slug"`
+			Name                *string   `json:"name"`
+			Description         *string   `json:"description"`
+			ThumbnailURL        *string   `json:"thumbnail_url"`
+			LaunchURL           *string   `json:"launch_url"`
+			SupportedGroupTypes *[]string `json:"supported_group_types"`
+			RequiredWordFields  *[]string `json:"required_word_fields"`
+		}
 		if err := c.ShouldBindJSON(&request); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		result, err := db.Exec(`
-			INSERT INTO study_sessions (group_id, study_activity_id)
-			VALUES (?, ?)
-		`, request.GroupID, request.StudyActivityID)
-
+		activity, err := activities.Update(c.Request.Context(), id, service.ActivityChanges(request))
+		var invalid *service.ValidationError
+		if errors.Is(err, service.ErrActivityNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+			return
+		}
+		if errors.As(err, &invalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": invalid.Message})
+			return
+		}
+		if errors.Is(err, models.ErrDuplicateActivitySlug) {
+			c.JSON(http.StatusConflict, gin.H{"error": "An activity with this slug already exists"})
+			return
+		}
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
-		sessionID, _ := result.LastInsertId()
+		c.JSON(http.StatusOK, newStudyActivityResponse(*activity))
+	}
+}
 
-		c.JSON(http.StatusCreated, gin.H{
-			"id":       sessionID,
-			"group_id": request.GroupID,
-			"success":  true,
-			"message": "Study activity created successfully",
+// DeleteStudyActivity removes an activity with its study sessions.
+func DeleteStudyActivity(activities *service.ActivityService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid activity ID"})
+			return
+		}
+
+		err = activities.Delete(c.Request.Context(), id)
+		if errors.Is(err, service.ErrActivityNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+			return
+		}
+		if errors.Is(err, models.ErrActivityInUse) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Activity has study sessions"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Activity deleted successfully",
 		})
 	}
 }
@@ -1,15 +1,17 @@
 package handlers
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetStudyActivity(t *testing.T) {
@@ -17,8 +19,10 @@ func TestGetStudyActivity(t *testing.T) {
 	r := gin.New()
 	db := setupTestDB(t)
 	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
 
-	r.GET("/api/study-activity/:id", GetStudyActivity(db))
+	r.GET("/api/study-activity/:id", GetStudyActivity(svc.Activities))
 
 	tests := []struct {
 		name       string
@@ -72,8 +76,10 @@ func TestGetStudyActivitySessions(t *testing.T) {
 	r := gin.New()
 	db := setupTestDB(t)
 	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
 
-	r.GET("/api/study-activity/:id/study-sessions", GetStudyActivitySessions(db))
+	r.GET("/api/study-activity/:id/study-sessions", GetStudyActivitySessions(svc.Activities, testPages))
 
 	tests := []struct {
 		name       string
@@ -143,82 +149,163 @@ func TestGetStudyActivitySessions(t *testing.T) {
 	}
 }
 
-func TestCreateStudyActivity(t *testing.T) {
+func TestStudyActivityRegistry(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
 	db := setupTestDB(t)
 	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
 
-	r.POST("/api/study-activities", CreateStudyActivity(db))
+	r.GET("/api/study-activity/:id", GetStudyActivity(svc.Activities))
+	r.POST("/api/study-activities", CreateStudyActivity(svc.Activities))
+	r.PATCH("/api/study-activities/:id", UpdateStudyActivity(svc.Activities))
+	r.DELETE("/api/study-activities/:id", DeleteStudyActivity(svc.Activities))
+
+	send := func(method, path, body string) (int, map[string]any) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+
+		var response map[string]any
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+
+	status, response := send("POST", "/api/study-activities", `{
+		"name": "Verb Drills",
+		"description": "Conjugate verbs against the clock",
+		"launch_url": "https://drills.example.com/?session={session_id}",
+		"supported_group_types": ["verb"],
+		"required_word_fields": ["parts.segments"]
+	}`)
+	require.Equal(t, http.StatusCreated, status, response)
+	assert.Equal(t, "verb-drills", response["slug"])
+	assert.Equal(t, []any{"verb"}, response["supported_group_types"])
+	id := fmt.Sprintf("%.0f", response["id"])
 
 	tests := []struct {
 		name       string
-		payload    map[string]interface{}
+		method     string
+		path       string
+		body       string
 		wantStatus int
 	}{
-		{
-			name: "Valid activity",
-			payload: map[string]interface{}{
-				"group_id":          float64(1),
-				"study_activity_id": float64(1),
-			},
-			wantStatus: http.StatusCreated,
-		},
-		{
-			name: "Missing group_id",
-			payload: map[string]interface{}{
-				"study_activity_id": float64(1),
-			},
-			wantStatus: http.StatusBadRequest,
-		},
-		{
-			name: "Missing study_activity_id",
-			payload: map[string]interface{}{
-				"group_id": float64(1),
-			},
-			wantStatus: http.StatusBadRequest,
-		},
-		{
-			name: "Invalid group",
-			payload: map[string]interface{}{
-				"group_id":          float64(999),
-				"study_activity_id": float64(1),
-			},
-			wantStatus: http.StatusCreated,
-		},
-		{
-			name: "Invalid activity",
-			payload: map[string]interface{}{
-				"group_id":          float64(1),
-				"study_activity_id": float64(999),
-			},
-			wantStatus: http.StatusCreated,
-		},
+		{"Same slug", "POST", "/api/study-activities", `{"name": "Verb drills"}`, http.StatusConflict},
+		{"Missing name", "POST", "/api/study-activities", `{"slug": "drills"}`, http.StatusBadRequest},
+		{"Relative launch URL", "POST", "/api/study-activities", `{"name": "Kana", "launch_url": "/kana"}`, http.StatusBadRequest},
+		{"Unknown word field", "POST", "/api/study-activities", `{"name": "Kana", "required_word_fields": ["audio"]}`, http.StatusBadRequest},
+		{"Rename", "PATCH", "/api/study-activities/" + id, `{"name": "Verb Sprint", "slug": "verb-sprint"}`, http.StatusOK},
+		{"Bad slug", "PATCH", "/api/study-activities/" + id, `{"slug": "Verb Sprint"}`, http.StatusBadRequest},
+		{"Update unknown", "PATCH", "/api/study-activities/999", `{"name": "Verb Sprint"}`, http.StatusNotFound},
+		{"Delete unknown", "DELETE", "/api/study-activities/999", "", http.StatusNotFound},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			payloadBytes, _ := json.Marshal(tt.payload)
-			w := httptest.NewRecorder()
-			req, _ := http.NewRequest("POST", "/api/study-activities", bytes.NewBuffer(payloadBytes))
-			req.Header.Set("Content-Type", "application/json")
-			r.ServeHTTP(w, req)
+			status, response := send(tt.method, tt.path, tt.body)
+			assert.Equal(t, tt.wantStatus, status, response)
+		})
+	}
 
-			assert.Equal(t, tt.wantStatus, w.Code)
+	status, response = send("GET", "/api/study-activity/"+id, "")
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "Verb Sprint", response["name"])
+	assert.Equal(t, "verb-sprint", response["slug"])
+	assert.Equal(t, "Conjugate verbs against the clock", response["description"])
 
-			if tt.wantStatus == http.StatusCreated {
-				var response struct {
-					ID      int64  `json:"id"`
-					GroupID int64  `json:"group_id"`
-					Success bool   `json:"success"`
-					Message string `json:"message"`
-				}
-				err := json.Unmarshal(w.Body.Bytes(), &response)
-				assert.NoError(t, err)
-				assert.True(t, response.Success)
-				assert.NotZero(t, response.ID)
-				assert.Equal(t, int64(tt.payload["group_id"].(float64)), response.GroupID)
-			}
+	// The seeded activity has a session, which keeps it
+	status, _ = send("DELETE", "/api/study-activities/1", "")
+	assert.Equal(t, http.StatusConflict, status)
+	var sessions int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM study_sessions WHERE study_activity_id = 1").Scan(&sessions))
+	assert.Equal(t, 1, sessions)
+
+	status, _ = send("DELETE", "/api/study-activities/"+id, "")
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = send("GET", "/api/study-activity/"+id, "")
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestLaunchStudyActivity(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	db := setupTestDB(t)
+	defer db.Close()
+	svc := newTestServices(db)
+
+	_, err := db.Exec(`INSERT INTO study_activities (id, name, launch_url) VALUES (2, 'Flashcards', 'https://cards.example.com/study/{session_id}?group={group_id}')`)
+	require.NoError(t, err)
+
+	r.POST("/api/study-activities/:id/launch", authenticateAs(svc.Users, 1), LaunchStudyActivity(svc.Activities))
+	r.GET("/api/groups/:id/words", AuthenticateLaunch(svc.Auth, GroupScope), GetGroupWords(svc.Groups, testPages))
+	r.POST("/api/study-sessions/:id/words/:word_id/review", AuthenticateLaunch(svc.Auth, SessionScope), CreateWordReview(svc.Reviews))
+
+	launch := func(activityID string, body string) (int, map[string]any) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/study-activities/"+activityID+"/launch", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+
+		var response map[string]any
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+
+	status, _ := launch("1", `{"group_id": 1}`)
+	assert.Equal(t, http.StatusBadRequest, status, "activity without a launch URL")
+	status, _ = launch("999", `{"group_id": 1}`)
+	assert.Equal(t, http.StatusNotFound, status)
+	status, _ = launch("2", `{"group_id": 999}`)
+	assert.Equal(t, http.StatusNotFound, status)
+	status, _ = launch("2", `{}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, response := launch("2", `{"group_id": 1}`)
+	require.Equal(t, http.StatusCreated, status, response)
+	sessionID := int64(response["study_session_id"].(float64))
+	token := response["token"].(string)
+	assert.Equal(t, fmt.Sprintf("https://cards.example.com/study/%d?group=1&token=%s", sessionID, token), response["launch_url"])
+
+	call := func(method, path, token string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(`{"correct": true}`))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		token      string
+		wantStatus int
+	}{
+		{"Group words", "GET", "/api/groups/1/words", token, http.StatusOK},
+		{"Review", "POST", fmt.Sprintf("/api/study-sessions/%d/words/1/review", sessionID), token, http.StatusCreated},
+		{"Word outside the group", "POST", fmt.Sprintf("/api/study-sessions/%d/words/2/review", sessionID), token, http.StatusForbidden},
+		{"Unknown word", "POST", fmt.Sprintf("/api/study-sessions/%d/words/999/review", sessionID), token, http.StatusNotFound},
+		{"Another session", "POST", "/api/study-sessions/1/words/1/review", token, http.StatusForbidden},
+		{"Another group", "GET", "/api/groups/2/words", token, http.StatusForbidden},
+		{"Tampered token", "POST", fmt.Sprintf("/api/study-sessions/%d/words/1/review", sessionID), token + "x", http.StatusUnauthorized},
+		{"No token", "POST", fmt.Sprintf("/api/study-sessions/%d/words/1/review", sessionID), "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantStatus, call(tt.method, tt.path, tt.token))
 		})
 	}
+
+	// Tokens expire
+	svc.Auth.LaunchTokenTTL = -time.Minute
+	status, response = launch("2", `{"group_id": 1}`)
+	require.Equal(t, http.StatusCreated, status, response)
+	path := fmt.Sprintf("/api/study-sessions/%d/words/1/review", int64(response["study_session_id"].(float64)))
+	assert.Equal(t, http.StatusUnauthorized, call("POST", path, response["token"].(string)))
 }
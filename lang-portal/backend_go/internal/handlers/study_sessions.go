@@ -1,84 +1,69 @@
 package handlers
 
 import (
-	"database/sql"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
+	"lang-portal/backend_go/internal/grading"
 	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+	"lang-portal/backend_go/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
 
-func GetStudySessions(db *sql.DB) gin.HandlerFunc {
+// GetStudySessions lists the user's sessions by page number or, when the
+// cursor query parameter is present, by cursor.
+func GetStudySessions(sessions *service.SessionService, limits PageLimits) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-		perPage := 100
-		offset := (page - 1) * perPage
-
-		// Get total count
-		var total int
-		err := db.QueryRow("SELECT COUNT(*) FROM study_sessions").Scan(&total)
+		filter, err := parseSessionFilter(c)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		// Get paginated study sessions
-		rows, err := db.Query(`
-			SELECT 
-				ss.id,
-				sa.name as activity_name,
-				g.name as group_name,
-				ss.created_at as start_time,
-				MAX(wri.created_at) as end_time,
-				COUNT(wri.id) as review_items_count
-			FROM study_sessions ss
-			JOIN study_activities sa ON sa.id = ss.study_activity_id
-			JOIN groups g ON g.id = ss.group_id
-			LEFT JOIN word_review_items wri ON wri.study_session_id = ss.id
-			GROUP BY ss.id
-			ORDER BY ss.created_at DESC
-			LIMIT ? OFFSET ?
-		`, perPage, offset)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-		defer rows.Close()
+		if _, ok := c.GetQuery("cursor"); ok {
+			keyset, err := parseKeyset(c, limits)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
 
-		var sessions []models.StudySessionDetail
-		for rows.Next() {
-			var session models.StudySessionDetail
-			err := rows.Scan(
-				&session.ID,
-				&session.ActivityName,
-				&session.GroupName,
-				&session.StartTime,
-				&session.EndTime,
-				&session.ReviewItemCount,
-			)
+			items, next, err := sessions.ListAfter(c.Request.Context(), currentUser(c).ID, filter, keyset)
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 				return
 			}
-			sessions = append(sessions, session)
+
+			c.JSON(http.StatusOK, gin.H{
+				"items":       items,
+				"next_cursor": nextCursor(next),
+			})
+			return
+		}
+
+		list, err := parseList(c, limits, repository.SessionSorts)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		items, total, err := sessions.List(c.Request.Context(), currentUser(c).ID, filter, list.opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{
-			"items": sessions,
-			"pagination": gin.H{
-				"current_page":   page,
-				"total_pages":    (total + perPage - 1) / perPage,
-				"total_items":    total,
-				"items_per_page": perPage,
-			},
+			"items":      items,
+			"pagination": list.pagination(total),
 		})
 	}
 }
 
-func GetStudySession(db *sql.DB) gin.HandlerFunc {
+func GetStudySession(sessions *service.SessionService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 		if err != nil {
@@ -86,8 +71,8 @@ func GetStudySession(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		session, err := models.GetStudySession(db, id)
-		if err == sql.ErrNoRows {
+		session, err := sessions.Get(c.Request.Context(), currentUser(c).ID, id)
+		if errors.Is(err, service.ErrSessionNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Study session not found"})
 			return
 		}
@@ -100,7 +85,7 @@ func GetStudySession(db *sql.DB) gin.HandlerFunc {
 	}
 }
 
-func GetStudySessionWords(db *sql.DB) gin.HandlerFunc {
+func GetStudySessionWords(sessions *service.SessionService, limits PageLimits) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		sessionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 		if err != nil {
@@ -108,57 +93,90 @@ func GetStudySessionWords(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		rows, err := db.Query(`
-			SELECT 
-				w.id,
-				w.japanese,
-				w.romaji,
-				w.english,
-				wri.correct,
-				wri.created_at as reviewed_at
-			FROM words w
-			JOIN word_review_items wri ON wri.word_id = w.id
-			WHERE wri.study_session_id = ?
-			ORDER BY wri.created_at
-		`, sessionID)
+		keyset, err := parseKeyset(c, limits)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		words, next, err := sessions.Words(c.Request.Context(), currentUser(c).ID, sessionID, keyset)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
-		defer rows.Close()
 
-		var words []struct {
-			models.Word
-			Correct    bool      `json:"correct"`
-			ReviewedAt time.Time `json:"reviewed_at"`
+		c.JSON(http.StatusOK, gin.H{
+			"items":       words,
+			"next_cursor": nextCursor(next),
+		})
+	}
+}
+
+// EndStudySession marks an active study session as completed.
+func EndStudySession(sessions *service.SessionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+			return
 		}
 
-		for rows.Next() {
-			var word struct {
-				models.Word
-				Correct    bool      `json:"correct"`
-				ReviewedAt time.Time `json:"reviewed_at"`
-			}
-			err := rows.Scan(
-				&word.ID,
-				&word.Japanese,
-				&word.Romaji,
-				&word.English,
-				&word.Correct,
-				&word.ReviewedAt,
-			)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-				return
-			}
-			words = append(words, word)
+		session, err := sessions.End(c.Request.Context(), currentUser(c).ID, id, time.Now())
+		if errors.Is(err, service.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Study session not found"})
+			return
+		}
+		if errors.Is(err, models.ErrSessionClosed) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Study session has already ended"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, session)
+	}
+}
+
+// StartStudySession starts a study session of an activity over a group.
+// Activities with an app are started with LaunchStudyActivity instead.
+func StartStudySession(sessions *service.SessionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request struct {
+			GroupID         int64 `json:"group_id" binding:"required"`
+			StudyActivityID int64 `json:"study_activity_id" binding:"required"`
+		}
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		session, err := sessions.Start(c.Request.Context(), currentUser(c).ID, request.GroupID, request.StudyActivityID)
+		if errors.Is(err, service.ErrGroupNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+			return
+		}
+		if errors.Is(err, service.ErrActivityNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{"items": words})
+		c.JSON(http.StatusCreated, gin.H{
+			"id":       session.ID,
+			"group_id": session.GroupID,
+			"success":  true,
+			"message":  "Study session started successfully",
+		})
 	}
 }
 
-func CreateWordReview(db *sql.DB) gin.HandlerFunc {
+func CreateWordReview(reviews *service.ReviewService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		sessionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 		if err != nil {
@@ -173,61 +191,146 @@ func CreateWordReview(db *sql.DB) gin.HandlerFunc {
 		}
 
 		var request struct {
-			Correct bool `json:"correct" binding:"required"`
+			Correct     *bool  `json:"correct" binding:"required"`
+			AnswerGiven string `json:"answer_given"`
+			ResponseMS  *int64 `json:"response_ms"`
+			Quality     *int   `json:"quality"`
+			Direction   string `json:"direction"`
+			HintsUsed   int    `json:"hints_used"`
 		}
 		if err := c.ShouldBindJSON(&request); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		answer := service.Answer{
+			Correct:     *request.Correct,
+			AnswerGiven: request.AnswerGiven,
+			ResponseMS:  request.ResponseMS,
+			Quality:     request.Quality,
+			Direction:   request.Direction,
+			HintsUsed:   request.HintsUsed,
+		}
 
-		// Validate session exists
-		var sessionExists bool
-		err = db.QueryRow("SELECT EXISTS(SELECT 1 FROM study_sessions WHERE id = ?)", sessionID).Scan(&sessionExists)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		var review *models.WordReview
+		var schedule *models.WordSchedule
+		if launch := currentLaunch(c); launch != nil {
+			review, schedule, err = reviews.RecordLaunched(c.Request.Context(), launch, wordID, answer, time.Now())
+		} else {
+			review, schedule, err = reviews.Record(c.Request.Context(), currentUser(c).ID, sessionID, wordID, answer, time.Now())
+		}
+		var invalid *service.ValidationError
+		if errors.As(err, &invalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": invalid.Message})
 			return
 		}
-		if !sessionExists {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Study session not found"})
+		if errors.Is(err, service.ErrOutsideLaunch) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Word is not in the group of the launched session"})
+			return
+		}
+		if errors.Is(err, service.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Study session not found"})
+			return
+		}
+		if errors.Is(err, models.ErrSessionClosed) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Study session has already ended"})
+			return
+		}
+		if errors.Is(err, service.ErrWordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Word not found"})
 			return
 		}
-
-		// Validate word exists
-		var wordExists bool
-		err = db.QueryRow("SELECT EXISTS(SELECT 1 FROM words WHERE id = ?)", wordID).Scan(&wordExists)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
-		if !wordExists {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Word not found"})
+
+		c.JSON(http.StatusCreated, gin.H{
+			"success":          true,
+			"word_id":          review.WordID,
+			"study_session_id": review.StudySessionID,
+			"correct":          review.Correct,
+			"answer_given":     review.AnswerGiven,
+			"response_ms":      review.ResponseMS,
+			"quality":          review.Quality,
+			"direction":        review.Direction,
+			"hints_used":       review.HintsUsed,
+			"created_at":       review.CreatedAt,
+			"schedule":         schedule,
+		})
+	}
+}
+
+// CheckWordAnswer grades the learner's own answer to a word and records it
+// as a review, returning the verdict with the expected answer.
+func CheckWordAnswer(reviews *service.ReviewService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sessionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
 			return
 		}
 
-		result, err := db.Exec(`
-			INSERT INTO word_review_items (word_id, study_session_id, correct)
-			VALUES (?, ?, ?)
-		`, wordID, sessionID, request.Correct)
-
+		wordID, err := strconv.ParseInt(c.Param("word_id"), 10, 64)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid word ID"})
+			return
+		}
+
+		var request struct {
+			Answer     *string `json:"answer" binding:"required"`
+			Direction  string  `json:"direction" binding:"required"`
+			ResponseMS *int64  `json:"response_ms"`
+			HintsUsed  int     `json:"hints_used"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		attempt := service.Attempt{
+			Input:      *request.Answer,
+			Direction:  request.Direction,
+			ResponseMS: request.ResponseMS,
+			HintsUsed:  request.HintsUsed,
+		}
 
-		id, _ := result.LastInsertId()
-		var createdAt string
-		err = db.QueryRow("SELECT created_at FROM word_review_items WHERE id = ?", id).Scan(&createdAt)
+		var verdict *grading.Verdict
+		var review *models.WordReview
+		var schedule *models.WordSchedule
+		if launch := currentLaunch(c); launch != nil {
+			verdict, review, schedule, err = reviews.CheckLaunched(c.Request.Context(), launch, wordID, attempt, time.Now())
+		} else {
+			verdict, review, schedule, err = reviews.Check(c.Request.Context(), currentUser(c).ID, sessionID, wordID, attempt, time.Now())
+		}
+		var invalid *service.ValidationError
+		if errors.As(err, &invalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": invalid.Message})
+			return
+		}
+		if errors.Is(err, service.ErrOutsideLaunch) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Word is not in the group of the launched session"})
+			return
+		}
+		if errors.Is(err, service.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Study session not found"})
+			return
+		}
+		if errors.Is(err, models.ErrSessionClosed) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Study session has already ended"})
+			return
+		}
+		if errors.Is(err, service.ErrWordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Word not found"})
+			return
+		}
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
 		c.JSON(http.StatusCreated, gin.H{
-			"success":          true,
-			"word_id":          wordID,
-			"study_session_id": sessionID,
-			"correct":          request.Correct,
-			"created_at":       createdAt,
+			"verdict":  verdict,
+			"review":   review,
+			"schedule": schedule,
 		})
 	}
 }
@@ -2,11 +2,13 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -17,8 +19,10 @@ func TestGetStudySessions(t *testing.T) {
 	r := gin.New()
 	db := setupTestDB(t)
 	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
 
-	r.GET("/api/study-sessions", GetStudySessions(db))
+	r.GET("/api/study-sessions", GetStudySessions(svc.Sessions, testPages))
 
 	tests := []struct {
 		name       string
@@ -83,8 +87,10 @@ func TestGetStudySession(t *testing.T) {
 	r := gin.New()
 	db := setupTestDB(t)
 	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
 
-	r.GET("/api/study-sessions/:id", GetStudySession(db))
+	r.GET("/api/study-sessions/:id", GetStudySession(svc.Sessions))
 
 	tests := []struct {
 		name       string
@@ -140,8 +146,10 @@ func TestGetStudySessionWords(t *testing.T) {
 	r := gin.New()
 	db := setupTestDB(t)
 	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
 
-	r.GET("/api/study-sessions/:id/words", GetStudySessionWords(db))
+	r.GET("/api/study-sessions/:id/words", GetStudySessionWords(svc.Sessions, testPages))
 
 	tests := []struct {
 		name       string
@@ -194,19 +202,104 @@ func TestGetStudySessionWords(t *testing.T) {
 	}
 }
 
+func TestStartStudySession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	db := setupTestDB(t)
+	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
+
+	r.POST("/api/study-sessions", StartStudySession(svc.Sessions))
+
+	tests := []struct {
+		name       string
+		payload    map[string]interface{}
+		wantStatus int
+	}{
+		{
+			name: "Valid session",
+			payload: map[string]interface{}{
+				"group_id":          float64(1),
+				"study_activity_id": float64(1),
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name: "Missing group_id",
+			payload: map[string]interface{}{
+				"study_activity_id": float64(1),
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Missing study_activity_id",
+			payload: map[string]interface{}{
+				"group_id": float64(1),
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Invalid group",
+			payload: map[string]interface{}{
+				"group_id":          float64(999),
+				"study_activity_id": float64(1),
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "Invalid activity",
+			payload: map[string]interface{}{
+				"group_id":          float64(1),
+				"study_activity_id": float64(999),
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payloadBytes, _ := json.Marshal(tt.payload)
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/study-sessions", bytes.NewBuffer(payloadBytes))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			if tt.wantStatus == http.StatusCreated {
+				var response struct {
+					ID      int64  `json:"id"`
+					GroupID int64  `json:"group_id"`
+					Success bool   `json:"success"`
+					Message string `json:"message"`
+				}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.True(t, response.Success)
+				assert.NotZero(t, response.ID)
+				assert.Equal(t, int64(tt.payload["group_id"].(float64)), response.GroupID)
+			}
+		})
+	}
+}
+
 func TestCreateWordReview(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
 	db := setupTestDB(t)
 	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
 
-	r.POST("/api/study-sessions/:id/words/:word_id/review", CreateWordReview(db))
+	r.POST("/api/study-sessions/:id/words/:word_id/review", CreateWordReview(svc.Reviews))
 
 	tests := []struct {
 		name       string
 		sessionID  string
 		wordID     string
 		correct    bool
+		quality    int
 		wantStatus int
 	}{
 		{
@@ -221,14 +314,22 @@ func TestCreateWordReview(t *testing.T) {
 			sessionID:  "999",
 			wordID:     "1",
 			correct:    true,
-			wantStatus: http.StatusInternalServerError,
+			wantStatus: http.StatusNotFound,
 		},
 		{
 			name:       "Invalid word",
 			sessionID:  "1",
 			wordID:     "999",
 			correct:    true,
-			wantStatus: http.StatusInternalServerError,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Quality that contradicts the answer",
+			sessionID:  "1",
+			wordID:     "1",
+			correct:    true,
+			quality:    1,
+			wantStatus: http.StatusBadRequest,
 		},
 		{
 			name:       "Invalid session ID format",
@@ -241,7 +342,10 @@ func TestCreateWordReview(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			payload := map[string]bool{"correct": tt.correct}
+			payload := map[string]any{"correct": tt.correct, "answer_given": "hello", "response_ms": 1200, "direction": "ja-en"}
+			if tt.quality != 0 {
+				payload["quality"] = tt.quality
+			}
 			payloadBytes, _ := json.Marshal(payload)
 
 			w := httptest.NewRecorder()
@@ -258,14 +362,262 @@ func TestCreateWordReview(t *testing.T) {
 					WordID         int64  `json:"word_id"`
 					StudySessionID int64  `json:"study_session_id"`
 					Correct        bool   `json:"correct"`
+					AnswerGiven    string `json:"answer_given"`
+					ResponseMS     int64  `json:"response_ms"`
+					Quality        int    `json:"quality"`
+					Direction      string `json:"direction"`
 					CreatedAt      string `json:"created_at"`
 				}
 				err := json.Unmarshal(w.Body.Bytes(), &response)
 				assert.NoError(t, err)
 				assert.True(t, response.Success)
 				assert.Equal(t, tt.correct, response.Correct)
+				assert.Equal(t, "hello", response.AnswerGiven)
+				assert.Equal(t, int64(1200), response.ResponseMS)
+				assert.Equal(t, 4, response.Quality)
+				assert.Equal(t, "ja-en", response.Direction)
 				assert.NotEmpty(t, response.CreatedAt)
 			}
 		})
 	}
 }
+
+func TestCheckWordAnswer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	db := setupTestDB(t)
+	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
+
+	r.POST("/api/study-sessions/:id/words/:word_id/answer", CheckWordAnswer(svc.Reviews))
+
+	tests := []struct {
+		name        string
+		sessionID   string
+		wordID      string
+		body        string
+		wantStatus  int
+		wantResult  string
+		wantCorrect bool
+	}{
+		{
+			name:        "Kana answer",
+			sessionID:   "1",
+			wordID:      "1",
+			body:        `{"answer": "コンニチワ", "direction": "en-ja"}`,
+			wantStatus:  http.StatusCreated,
+			wantResult:  "exact",
+			wantCorrect: true,
+		},
+		{
+			name:        "Near miss",
+			sessionID:   "1",
+			wordID:      "3",
+			body:        `{"answer": "thank yuo", "direction": "ja-en", "response_ms": 2400}`,
+			wantStatus:  http.StatusCreated,
+			wantResult:  "close",
+			wantCorrect: true,
+		},
+		{
+			name:       "Wrong answer",
+			sessionID:  "1",
+			wordID:     "2",
+			body:       `{"answer": "hello", "direction": "ja-en"}`,
+			wantStatus: http.StatusCreated,
+			wantResult: "wrong",
+		},
+		{
+			name:       "Missing direction",
+			sessionID:  "1",
+			wordID:     "1",
+			body:       `{"answer": "hello"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Unknown direction",
+			sessionID:  "1",
+			wordID:     "1",
+			body:       `{"answer": "hello", "direction": "ja-fr"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid word",
+			sessionID:  "1",
+			wordID:     "999",
+			body:       `{"answer": "hello", "direction": "ja-en"}`,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Invalid session",
+			sessionID:  "999",
+			wordID:     "1",
+			body:       `{"answer": "hello", "direction": "ja-en"}`,
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			url := fmt.Sprintf("/api/study-sessions/%s/words/%s/answer", tt.sessionID, tt.wordID)
+			req, _ := http.NewRequest("POST", url, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			if tt.wantStatus == http.StatusCreated {
+				var response struct {
+					Verdict struct {
+						Result   string   `json:"result"`
+						Correct  bool     `json:"correct"`
+						Expected string   `json:"expected"`
+						Accepted []string `json:"accepted"`
+						Diff     []struct {
+							Op   string `json:"op"`
+							Text string `json:"text"`
+						} `json:"diff"`
+					} `json:"verdict"`
+					Review struct {
+						Correct     bool   `json:"correct"`
+						AnswerGiven string `json:"answer_given"`
+					} `json:"review"`
+					Schedule *struct {
+						DueAt string `json:"due_at"`
+					} `json:"schedule"`
+				}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantResult, response.Verdict.Result)
+				assert.Equal(t, tt.wantCorrect, response.Verdict.Correct)
+				assert.Equal(t, tt.wantCorrect, response.Review.Correct)
+				assert.NotEmpty(t, response.Verdict.Expected)
+				assert.NotEmpty(t, response.Verdict.Accepted)
+				assert.NotEmpty(t, response.Verdict.Diff)
+				assert.NotEmpty(t, response.Review.AnswerGiven)
+				assert.NotNil(t, response.Schedule)
+			}
+		})
+	}
+}
+
+func TestEndStudySession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	db := setupTestDB(t)
+	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
+
+	r.POST("/api/study-sessions/:id/end", EndStudySession(svc.Sessions))
+	r.POST("/api/study-sessions/:id/words/:word_id/review", CreateWordReview(svc.Reviews))
+
+	tests := []struct {
+		name       string
+		sessionID  string
+		wantStatus int
+	}{
+		{
+			name:       "Active session",
+			sessionID:  "1",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Already ended",
+			sessionID:  "1",
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "Invalid session",
+			sessionID:  "999",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Invalid ID format",
+			sessionID:  "abc",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", fmt.Sprintf("/api/study-sessions/%s/end", tt.sessionID), nil)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			if tt.wantStatus == http.StatusOK {
+				var response struct {
+					Status  string  `json:"status"`
+					EndTime *string `json:"end_time"`
+				}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "completed", response.Status)
+				assert.NotNil(t, response.EndTime)
+			}
+		})
+	}
+
+	// Reviews are rejected once the session has ended
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/study-sessions/1/words/1/review", bytes.NewBufferString(`{"correct":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestCloseIdleSessions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	db := setupTestDB(t)
+	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
+
+	r.GET("/api/study-sessions/:id", GetStudySession(svc.Sessions))
+
+	// Session 2 was started two hours ago and never used
+	_, err := db.Exec(`
+		INSERT INTO study_sessions (id, group_id, study_activity_id, created_at)
+		VALUES (2, 1, 1, datetime('now', '-2 hours'))
+	`)
+	assert.NoError(t, err)
+
+	closed, err := svc.Sessions.CloseIdle(context.Background(), 30*time.Minute, time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), closed)
+
+	tests := []struct {
+		sessionID   string
+		wantStatus  string
+		wantEndTime bool
+	}{
+		{sessionID: "1", wantStatus: "active", wantEndTime: false},
+		{sessionID: "2", wantStatus: "abandoned", wantEndTime: true},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/api/study-sessions/%s", tt.sessionID), nil)
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Status    string  `json:"status"`
+			StartTime string  `json:"start_time"`
+			EndTime   *string `json:"end_time"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, tt.wantStatus, response.Status)
+		if tt.wantEndTime {
+			// An unused session ends when it started
+			assert.NotNil(t, response.EndTime)
+			assert.Equal(t, response.StartTime, *response.EndTime)
+		} else {
+			assert.Nil(t, response.EndTime)
+		}
+	}
+}
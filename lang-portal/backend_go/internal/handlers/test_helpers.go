@@ -1,20 +1,27 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
+	"net/http"
 	"testing"
 
-	_ "github.com/mattn/go-sqlite3"
+	"lang-portal/backend_go/internal/repository/sqlstore"
+	"lang-portal/backend_go/internal/service"
+
+	"github.com/gin-gonic/gin"
 )
 
 func setupTestDB(t *testing.T) *sql.DB {
-	db, err := sql.Open("sqlite3", ":memory:")
+	db, err := sql.Open(sqlstore.SQLite.Driver, ":memory:")
 	if err != nil {
 		t.Fatalf("Failed to open test database: %v", err)
 	}
+	// Every new connection to :memory: gets its own empty database
+	db.SetMaxOpenConns(1)
 
-	// Run migrations
-	err = runTestMigrations(db)
+	// Build the schema from the migrations the server embeds
+	_, err = sqlstore.New(db, sqlstore.SQLite).Migrate(context.Background())
 	if err != nil {
 		t.Fatalf("Failed to run migrations: %v", err)
 	}
@@ -28,65 +35,34 @@ func setupTestDB(t *testing.T) *sql.DB {
 	return db
 }
 
-func runTestMigrations(db *sql.DB) error {
-	migrations := []string{
-		`CREATE TABLE words (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			japanese TEXT NOT NULL,
-			romaji TEXT NOT NULL,
-			english TEXT NOT NULL,
-			parts TEXT NOT NULL
-		)`,
-		`CREATE TABLE groups (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			name TEXT NOT NULL UNIQUE
-		)`,
-		`CREATE TABLE word_groups (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			word_id INTEGER NOT NULL,
-			group_id INTEGER NOT NULL,
-			FOREIGN KEY (word_id) REFERENCES words(id) ON DELETE CASCADE,
-			FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE,
-			UNIQUE(word_id, group_id)
-		)`,
-		`CREATE TABLE study_activities (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			name TEXT NOT NULL,
-			thumbnail_url TEXT,
-			description TEXT,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE TABLE study_sessions (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			group_id INTEGER NOT NULL,
-			study_activity_id INTEGER NOT NULL,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE,
-			FOREIGN KEY (study_activity_id) REFERENCES study_activities(id) ON DELETE CASCADE
-		)`,
-		`CREATE TABLE word_review_items (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			word_id INTEGER NOT NULL,
-			study_session_id INTEGER NOT NULL,
-			correct BOOLEAN NOT NULL,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (word_id) REFERENCES words(id) ON DELETE CASCADE,
-			FOREIGN KEY (study_session_id) REFERENCES study_sessions(id) ON DELETE CASCADE
-		)`,
-	}
+// testPages are the page limits the list endpoints are tested with.
+var testPages = PageLimits{Default: 100, Max: 500}
 
-	for _, migration := range migrations {
-		_, err := db.Exec(migration)
+// newTestServices builds the service layer on top of a test database.
+func newTestServices(db *sql.DB) *service.Services {
+	return service.New(sqlstore.New(db, sqlstore.SQLite))
+}
+
+// authenticateAs stands in for Authenticate, treating every request as made
+// by the given user.
+func authenticateAs(users *service.UserService, id int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := users.Get(c.Request.Context(), id)
 		if err != nil {
-			return err
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
 		}
-	}
 
-	return nil
+		c.Set(userKey, user)
+		c.Next()
+	}
 }
 
 func seedTestData(db *sql.DB) error {
 	testData := []string{
+		// Users, the migrations create the default admin
+		`INSERT INTO users (id, name) VALUES (2, 'hana')`,
+
 		// Words
 		`INSERT INTO words (japanese, romaji, english, parts) VALUES 
 		('こんにちは', 'konnichiwa', 'hello', '{"type":"greeting"}')`,
@@ -112,6 +88,12 @@ func seedTestData(db *sql.DB) error {
 		// Word reviews
 		`INSERT INTO word_review_items (word_id, study_session_id, correct, created_at) 
 		VALUES (1, 1, true, datetime('now'))`,
+
+		// Review schedules: word 1 is overdue, word 2 is not due yet, word 3 is new
+		`INSERT INTO word_schedules (user_id, word_id, ease_factor, interval_days, repetitions, due_at, last_reviewed_at)
+		VALUES (1, 1, 2.5, 1, 1, datetime('now', '-1 day'), datetime('now', '-2 days'))`,
+		`INSERT INTO word_schedules (user_id, word_id, ease_factor, interval_days, repetitions, due_at, last_reviewed_at)
+		VALUES (1, 2, 2.6, 6, 2, datetime('now', '+5 days'), datetime('now', '-1 day'))`,
 	}
 
 	for _, data := range testData {
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetUsers(users *service.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		items, err := users.List(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"items": items})
+	}
+}
+
+// GetCurrentUser returns the user the request was authenticated as.
+func GetCurrentUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, currentUser(c))
+	}
+}
+
+func CreateUser(users *service.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request struct {
+			Name     string `json:"name" binding:"required"`
+			Password string `json:"password" binding:"required"`
+			Role     string `json:"role"`
+		}
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		user, err := users.Create(c.Request.Context(), request.Name, request.Password, request.Role)
+		var invalid *service.ValidationError
+		if errors.As(err, &invalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": invalid.Message})
+			return
+		}
+		if errors.Is(err, models.ErrDuplicateUserName) {
+			c.JSON(http.StatusConflict, gin.H{"error": "A user with this name already exists"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, user)
+	}
+}
+
+// UpdateUser changes a user's role or password.
+func UpdateUser(users *service.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		var request struct {
+			Role     *string `json:"role"`
+			Password *string `json:"password"`
+		}
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if request.Role == nil && request.Password == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Nothing to update: provide role or password"})
+			return
+		}
+
+		user, err := users.Update(c.Request.Context(), id, service.UserChanges{
+			Role:     request.Role,
+			Password: request.Password,
+		})
+		var invalid *service.ValidationError
+		if errors.As(err, &invalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": invalid.Message})
+			return
+		}
+		if errors.Is(err, service.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		if errors.Is(err, service.ErrLastAdmin) {
+			c.JSON(http.StatusConflict, gin.H{"error": "The last admin cannot lose the admin role"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, user)
+	}
+}
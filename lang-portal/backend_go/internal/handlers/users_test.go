@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHistoryIsPerUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	db := setupTestDB(t)
+	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 2))
+
+	r.GET("/api/study-sessions/:id", GetStudySession(svc.Sessions))
+	r.GET("/api/dashboard/quick-stats", GetQuickStats(svc.Dashboard))
+
+	// The seeded session belongs to the default user
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/study-sessions/1", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/dashboard/quick-stats", nil)
+	r.ServeHTTP(w, req)
+
+	var stats struct {
+		TotalStudySessions int `json:"total_study_sessions"`
+	}
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	assert.Zero(t, stats.TotalStudySessions)
+}
+
+func TestCreateUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	db := setupTestDB(t)
+	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
+
+	r.POST("/api/users", CreateUser(svc.Users))
+
+	tests := []struct {
+		name       string
+		payload    string
+		wantStatus int
+		wantRole   string
+	}{
+		{
+			name:       "New student",
+			payload:    `{"name": "kenji", "password": "correct horse"}`,
+			wantStatus: http.StatusCreated,
+			wantRole:   "student",
+		},
+		{
+			name:       "New teacher",
+			payload:    `{"name": "sato", "password": "correct horse", "role": "teacher"}`,
+			wantStatus: http.StatusCreated,
+			wantRole:   "teacher",
+		},
+		{
+			name:       "Duplicate name",
+			payload:    `{"name": "hana", "password": "correct horse"}`,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "Blank name",
+			payload:    `{"name": "  ", "password": "correct horse"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Short password",
+			payload:    `{"name": "yuki", "password": "short"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Unknown role",
+			payload:    `{"name": "yuki", "password": "correct horse", "role": "principal"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Missing password",
+			payload:    `{"name": "yuki"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/users", bytes.NewBufferString(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			if tt.wantRole != "" {
+				var response struct {
+					Role string `json:"role"`
+				}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, tt.wantRole, response.Role)
+				assert.NotContains(t, w.Body.String(), "password")
+			}
+		})
+	}
+}
+
+func TestUpdateUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	db := setupTestDB(t)
+	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
+
+	r.PATCH("/api/users/:id", UpdateUser(svc.Users))
+
+	tests := []struct {
+		name       string
+		userID     string
+		payload    string
+		wantStatus int
+	}{
+		{
+			name:       "Promote to teacher",
+			userID:     "2",
+			payload:    `{"role": "teacher"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Set password",
+			userID:     "2",
+			payload:    `{"password": "correct horse"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Unknown role",
+			userID:     "2",
+			payload:    `{"role": "principal"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Password with unknown role",
+			userID:     "2",
+			payload:    `{"password": "battery staple", "role": "bogus"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Demote last admin",
+			userID:     "1",
+			payload:    `{"role": "teacher"}`,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "Nothing to update",
+			userID:     "2",
+			payload:    `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Unknown user",
+			userID:     "999",
+			payload:    `{"role": "teacher"}`,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Invalid user ID",
+			userID:     "abc",
+			payload:    `{"role": "teacher"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("PATCH", "/api/users/"+tt.userID, bytes.NewBufferString(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+
+	user, err := svc.Users.Get(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "teacher", user.Role)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte("correct horse")))
+
+	admin, err := svc.Users.Get(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", admin.Role)
+}
@@ -1,99 +1,39 @@
 package handlers
 
 import (
-	"database/sql"
-	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+	"lang-portal/backend_go/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
 
-func GetWords(db *sql.DB) gin.HandlerFunc {
+func GetWords(words *service.WordService, limits PageLimits) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-		query := c.Query("q")
-		perPage := 100
-		offset := (page - 1) * perPage
-
-		// Base query
-		countQuery := "SELECT COUNT(*) FROM words"
-		selectQuery := `
-			SELECT id, japanese, romaji, english, parts
-			FROM words
-		`
-
-		// Add search condition if query parameter exists
-		var params []interface{}
-		if query != "" {
-			searchCond := `
-				WHERE japanese LIKE ? 
-				OR romaji LIKE ? 
-				OR english LIKE ?
-			`
-			countQuery += " " + searchCond
-			selectQuery += " " + searchCond
-			searchPattern := "%" + query + "%"
-			params = append(params, searchPattern, searchPattern, searchPattern)
-		}
-
-		// Add pagination
-		selectQuery += " LIMIT ? OFFSET ?"
-		params = append(params, perPage, offset)
-
-		// Get total count
-		var total int
-		countErr := db.QueryRow(countQuery, params[:len(params)-2]...).Scan(&total)
-		if countErr != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Count error: " + countErr.Error()})
-			return
-		}
-
-		// Get paginated words
-		rows, err := db.Query(selectQuery, params...)
+		list, err := parseList(c, limits, repository.WordSorts)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Query error: " + err.Error()})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		defer rows.Close()
-
-		var words []models.Word
-		for rows.Next() {
-			var word models.Word
-			err := rows.Scan(
-				&word.ID,
-				&word.Japanese,
-				&word.Romaji,
-				&word.English,
-				&word.Parts,
-			)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Scan error: " + err.Error()})
-				return
-			}
-			words = append(words, word)
-		}
 
-		if err = rows.Err(); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Rows error: " + err.Error()})
+		items, total, err := words.List(c.Request.Context(), currentUser(c).ID, c.Query("q"), list.opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{
-			"items": words,
-			"pagination": gin.H{
-				"current_page":   page,
-				"total_pages":    (total + perPage - 1) / perPage,
-				"total_items":    total,
-				"items_per_page": perPage,
-			},
+			"items":      items,
+			"pagination": list.pagination(total),
 		})
 	}
 }
 
-func GetWord(db *sql.DB) gin.HandlerFunc {
+func GetWord(words *service.WordService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 		if err != nil {
@@ -101,8 +41,8 @@ func GetWord(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		word, err := models.GetWord(db, id)
-		if err == sql.ErrNoRows {
+		word, err := words.Get(c.Request.Context(), currentUser(c).ID, id)
+		if errors.Is(err, service.ErrWordNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Word not found"})
 			return
 		}
@@ -111,55 +51,56 @@ func GetWord(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		stats, err := models.GetWordStats(db, id)
+		c.JSON(http.StatusOK, gin.H{
+			"japanese": word.Japanese,
+			"romaji":   word.Romaji,
+			"english":  word.English,
+			"stats":    word.Stats,
+			"groups":   word.Groups,
+		})
+	}
+}
+
+// GetWordReviews lists the user's reviews of a word, newest first, by
+// cursor.
+func GetWordReviews(words *service.WordService, limits PageLimits) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid word ID"})
 			return
 		}
 
-		// Get groups this word belongs to
-		rows, err := db.Query(`
-			SELECT g.id, g.name
-			FROM groups g
-			JOIN word_groups wg ON wg.group_id = g.id
-			WHERE wg.word_id = ?
-		`, id)
+		keyset, err := parseKeyset(c, limits)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		defer rows.Close()
 
-		var groups []models.Group
-		for rows.Next() {
-			var group models.Group
-			if err := rows.Scan(&group.ID, &group.Name); err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-				return
-			}
-			groups = append(groups, group)
+		reviews, next, err := words.Reviews(c.Request.Context(), currentUser(c).ID, id, keyset)
+		if errors.Is(err, service.ErrWordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Word not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{
-			"japanese": word.Japanese,
-			"romaji":   word.Romaji,
-			"english":  word.English,
-			"stats": gin.H{
-				"correct_count": stats.CorrectCount,
-				"wrong_count":   stats.WrongCount,
-			},
-			"groups": groups,
+			"items":       reviews,
+			"next_cursor": nextCursor(next),
 		})
 	}
 }
 
-func CreateWord(db *sql.DB) gin.HandlerFunc {
+func CreateWord(words *service.WordService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var request struct {
-			Japanese string `json:"japanese" binding:"required"`
-			Romaji   string `json:"romaji" binding:"required"`
-			English  string `json:"english" binding:"required"`
-			Parts    string `json:"parts" binding:"required"`
+			Japanese string        `json:"japanese" binding:"required"`
+			Romaji   string        `json:"romaji" binding:"required"`
+			English  string        `json:"english" binding:"required"`
+			Parts    *models.Parts `json:"parts" binding:"required"`
 		}
 
 		if err := c.ShouldBindJSON(&request); err != nil {
@@ -167,29 +108,104 @@ func CreateWord(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		// Validate Parts is valid JSON
-		var partsJSON map[string]interface{}
-		if err := json.Unmarshal([]byte(request.Parts), &partsJSON); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid parts JSON"})
+		word := models.Word{
+			Japanese: request.Japanese,
+			Romaji:   request.Romaji,
+			English:  request.English,
+			Parts:    *request.Parts,
+		}
+		err := words.Create(c.Request.Context(), &word)
+		var invalid *service.ValidationError
+		if errors.As(err, &invalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": invalid.Message})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
-		result, err := db.Exec(`
-			INSERT INTO words (japanese, romaji, english, parts)
-			VALUES (?, ?, ?, ?)
-		`, request.Japanese, request.Romaji, request.English, request.Parts)
+		c.JSON(http.StatusCreated, gin.H{
+			"id":      word.ID,
+			"success": true,
+			"message": "Word created successfully",
+		})
+	}
+}
 
+// UpdateWord serves both PUT, which requires every field, and PATCH, which
+// only changes the fields present in the request.
+func UpdateWord(words *service.WordService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid word ID"})
+			return
+		}
+
+		var request struct {
+			Japanese *string       `json:"japanese"`
+			Romaji   *string       `json:"romaji"`
+			English  *string       `json:"english"`
+			Parts    *models.Parts `json:"parts"`
+		}
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if c.Request.Method == http.MethodPut &&
+			(request.Japanese == nil || request.Romaji == nil || request.English == nil || request.Parts == nil) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "japanese, romaji, english and parts are required"})
+			return
+		}
+
+		word, err := words.Update(c.Request.Context(), id, service.WordChanges{
+			Japanese: request.Japanese,
+			Romaji:   request.Romaji,
+			English:  request.English,
+			Parts:    request.Parts,
+		})
+		var invalid *service.ValidationError
+		if errors.Is(err, service.ErrWordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Word not found"})
+			return
+		}
+		if errors.As(err, &invalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": invalid.Message})
+			return
+		}
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
-		id, _ := result.LastInsertId()
+		c.JSON(http.StatusOK, word)
+	}
+}
 
-		c.JSON(http.StatusCreated, gin.H{
-			"id":      id,
+func DeleteWord(words *service.WordService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid word ID"})
+			return
+		}
+
+		err = words.Delete(c.Request.Context(), id)
+		if errors.Is(err, service.ErrWordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Word not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
 			"success": true,
-			"message": "Word created successfully",
+			"message": "Word deleted successfully",
 		})
 	}
 }
@@ -8,6 +8,8 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"lang-portal/backend_go/internal/models"
+
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 )
@@ -17,8 +19,10 @@ func TestGetWords(t *testing.T) {
 	r := gin.New()
 	db := setupTestDB(t)
 	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
 
-	r.GET("/api/words", GetWords(db))
+	r.GET("/api/words", GetWords(svc.Words, testPages))
 
 	tests := []struct {
 		name       string
@@ -62,6 +66,20 @@ func TestGetWords(t *testing.T) {
 			wantStatus: http.StatusOK,
 			wantItems:  1,
 		},
+		{
+			name:       "Search by katakana",
+			page:       "1",
+			query:      "コンニチハ",
+			wantStatus: http.StatusOK,
+			wantItems:  1,
+		},
+		{
+			name:       "Search by long vowel",
+			page:       "1",
+			query:      "sayōnara",
+			wantStatus: http.StatusOK,
+			wantItems:  1,
+		},
 		{
 			name:       "No results",
 			page:       "1",
@@ -89,7 +107,7 @@ func TestGetWords(t *testing.T) {
 					Japanese string `json:"japanese"`
 					Romaji   string `json:"romaji"`
 					English  string `json:"english"`
-					Parts    string `json:"parts"`
+					Parts    map[string]interface{} `json:"parts"`
 				} `json:"items"`
 				Pagination struct {
 					CurrentPage  int `json:"current_page"`
@@ -106,18 +124,53 @@ func TestGetWords(t *testing.T) {
 				assert.NotEmpty(t, response.Items[0].Japanese)
 				assert.NotEmpty(t, response.Items[0].Romaji)
 				assert.NotEmpty(t, response.Items[0].English)
+				assert.Equal(t, "greeting", response.Items[0].Parts["type"])
 			}
 		})
 	}
 }
 
+func TestGetWordsHighlights(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	db := setupTestDB(t)
+	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
+
+	r.GET("/api/words", GetWords(svc.Words, testPages))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/words?q=%E3%82%A2%E3%83%AA%E3%82%AC%E3%83%88%20thank", nil) // アリガト thank
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Items []struct {
+			Japanese   string            `json:"japanese"`
+			Highlights map[string]string `json:"highlights"`
+		} `json:"items"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	if assert.Len(t, response.Items, 1) {
+		assert.Equal(t, "ありがとう", response.Items[0].Japanese)
+		assert.Equal(t, map[string]string{
+			"japanese": "<mark>ありがと</mark>う",
+			"romaji":   "<mark>arigatou</mark>",
+			"english":  "<mark>thank</mark> you",
+		}, response.Items[0].Highlights)
+	}
+}
+
 func TestGetWord(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
 	db := setupTestDB(t)
 	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
 
-	r.GET("/api/words/:id", GetWord(db))
+	r.GET("/api/words/:id", GetWord(svc.Words))
 
 	tests := []struct {
 		name       string
@@ -181,8 +234,10 @@ func TestCreateWord(t *testing.T) {
 	r := gin.New()
 	db := setupTestDB(t)
 	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
 
-	r.POST("/api/words", CreateWord(db))
+	r.POST("/api/words", CreateWord(svc.Words))
 
 	tests := []struct {
 		name       string
@@ -262,3 +317,250 @@ func TestCreateWord(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateWord(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	db := setupTestDB(t)
+	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
+
+	r.PUT("/api/words/:id", UpdateWord(svc.Words))
+	r.PATCH("/api/words/:id", UpdateWord(svc.Words))
+
+	tests := []struct {
+		name        string
+		method      string
+		wordID      string
+		payload     map[string]interface{}
+		wantStatus  int
+		wantEnglish string
+		wantParts   *models.Parts
+	}{
+		{
+			name:   "PUT all fields",
+			method: "PUT",
+			wordID: "1",
+			payload: map[string]interface{}{
+				"japanese": "こんにちは",
+				"romaji":   "konnichiwa",
+				"english":  "good afternoon",
+				"parts":    `{"type":"greeting"}`,
+			},
+			wantStatus:  http.StatusOK,
+			wantEnglish: "good afternoon",
+		},
+		{
+			name:   "PUT missing field",
+			method: "PUT",
+			wordID: "1",
+			payload: map[string]interface{}{
+				"english": "good afternoon",
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "PATCH single field",
+			method: "PATCH",
+			wordID: "2",
+			payload: map[string]interface{}{
+				"english": "farewell",
+			},
+			wantStatus:  http.StatusOK,
+			wantEnglish: "farewell",
+		},
+		{
+			name:   "PATCH parts object",
+			method: "PATCH",
+			wordID: "2",
+			payload: map[string]interface{}{
+				"parts": map[string]interface{}{"type": "greeting", "politeness": "formal"},
+			},
+			wantStatus:  http.StatusOK,
+			wantEnglish: "farewell",
+			wantParts:   &models.Parts{Type: "greeting", Formality: "formal"},
+		},
+		{
+			name:   "PATCH array parts as string",
+			method: "PATCH",
+			wordID: "2",
+			payload: map[string]interface{}{
+				"parts": `[{"kanji":"さようなら","romaji":"sayounara"}]`,
+			},
+			wantStatus:  http.StatusOK,
+			wantEnglish: "farewell",
+			wantParts:   &models.Parts{Segments: []models.Segment{{Kanji: "さようなら", Romaji: "sayounara"}}},
+		},
+		{
+			name:   "PATCH segments with several romaji",
+			method: "PATCH",
+			wordID: "2",
+			payload: map[string]interface{}{
+				"parts": []interface{}{map[string]interface{}{"kanji": "さようなら", "romaji": []string{"sayou", "nara"}}},
+			},
+			wantStatus:  http.StatusOK,
+			wantEnglish: "farewell",
+			wantParts:   &models.Parts{Segments: []models.Segment{{Kanji: "さようなら", Romaji: "sayounara"}}},
+		},
+		{
+			name:   "PATCH segments not spelling the word",
+			method: "PATCH",
+			wordID: "2",
+			payload: map[string]interface{}{
+				"parts": []interface{}{map[string]interface{}{"kanji": "さよなら", "romaji": "sayonara"}},
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "PATCH invalid parts",
+			method: "PATCH",
+			wordID: "1",
+			payload: map[string]interface{}{
+				"parts": `"greeting"`,
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "PATCH empty field",
+			method: "PATCH",
+			wordID: "1",
+			payload: map[string]interface{}{
+				"romaji": "",
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Invalid word",
+			method: "PATCH",
+			wordID: "999",
+			payload: map[string]interface{}{
+				"english": "nothing",
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:   "Invalid ID format",
+			method: "PATCH",
+			wordID: "abc",
+			payload: map[string]interface{}{
+				"english": "nothing",
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payloadBytes, _ := json.Marshal(tt.payload)
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, fmt.Sprintf("/api/words/%s", tt.wordID), bytes.NewBuffer(payloadBytes))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			if tt.wantStatus == http.StatusOK {
+				var response struct {
+					ID      int64        `json:"id"`
+					English string       `json:"english"`
+					Parts   models.Parts `json:"parts"`
+				}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantEnglish, response.English)
+				if tt.wantParts != nil {
+					assert.Equal(t, *tt.wantParts, response.Parts)
+				}
+			}
+		})
+	}
+}
+
+func TestDeleteWord(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	db := setupTestDB(t)
+	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
+
+	r.DELETE("/api/words/:id", DeleteWord(svc.Words))
+
+	tests := []struct {
+		name       string
+		wordID     string
+		wantStatus int
+	}{
+		{
+			name:       "Valid word",
+			wordID:     "1",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Already deleted",
+			wordID:     "1",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Invalid ID format",
+			wordID:     "abc",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("DELETE", fmt.Sprintf("/api/words/%s", tt.wordID), nil)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+
+	// Word 1 was in a group and had a review; both must be gone
+	for _, table := range []string{"word_groups", "word_review_items", "word_schedules"} {
+		var count int
+		err := db.QueryRow("SELECT COUNT(*) FROM "+table+" WHERE word_id = 1").Scan(&count)
+		assert.NoError(t, err)
+		assert.Zero(t, count, table)
+	}
+}
+
+func TestGetWordsStats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	db := setupTestDB(t)
+	defer db.Close()
+	svc := newTestServices(db)
+	r.Use(authenticateAs(svc.Users, 1))
+
+	r.GET("/api/words", GetWords(svc.Words, testPages))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/words?sort=last_reviewed_at&order=desc", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Items []struct {
+			ID             int64   `json:"id"`
+			CorrectCount   int     `json:"correct_count"`
+			WrongCount     int     `json:"wrong_count"`
+			LastReviewedAt *string `json:"last_reviewed_at"`
+			Mastery        string  `json:"mastery"`
+		} `json:"items"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	if assert.Len(t, response.Items, 3) {
+		// Word 1 has one correct review and is due again in a day
+		assert.Equal(t, int64(1), response.Items[0].ID)
+		assert.Equal(t, 1, response.Items[0].CorrectCount)
+		assert.NotNil(t, response.Items[0].LastReviewedAt)
+		assert.Equal(t, "learning", response.Items[0].Mastery)
+
+		assert.Nil(t, response.Items[2].LastReviewedAt)
+		assert.Equal(t, "new", response.Items[2].Mastery)
+	}
+}
@@ -0,0 +1,379 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+	"lang-portal/backend_go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const xapiVersionHeader = "X-Experience-API-Version"
+
+// XAPIVersion rejects xAPI requests that do not declare a 1.0 version of the
+// specification and labels responses with the version the LRS implements.
+func XAPIVersion() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header(xapiVersionHeader, models.XAPIVersion)
+
+		version := c.GetHeader(xapiVersionHeader)
+		if version != "1.0" && !strings.HasPrefix(version, "1.0.") {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": xapiVersionHeader + " header must be 1.0 or 1.0.x"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// GetXAPIAbout describes the LRS. Unlike the other xAPI resources it needs
+// neither a token nor the version header.
+func GetXAPIAbout() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header(xapiVersionHeader, models.XAPIVersion)
+		c.JSON(http.StatusOK, gin.H{"version": []string{models.XAPIVersion}})
+	}
+}
+
+// writeXAPIError responds to an error of the LRS service.
+func writeXAPIError(c *gin.Context, err error) {
+	var invalid *service.ValidationError
+	switch {
+	case errors.As(err, &invalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": invalid.Message})
+	case errors.Is(err, service.ErrStatementNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Statement not found"})
+	case errors.Is(err, service.ErrStateNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "State document not found"})
+	case errors.Is(err, service.ErrStatementConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": "A different statement with this ID already exists"})
+	case errors.Is(err, models.ErrSessionClosed):
+		c.JSON(http.StatusConflict, gin.H{"error": "Study session has already ended"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// bindStatements reads a request body holding a statement or an array of
+// them.
+func bindStatements(c *gin.Context) ([]models.Statement, error) {
+	body, err := c.GetRawData()
+	if err != nil {
+		return nil, err
+	}
+
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var statements []models.Statement
+		err := json.Unmarshal(body, &statements)
+		return statements, err
+	}
+
+	var statement models.Statement
+	if err := json.Unmarshal(body, &statement); err != nil {
+		return nil, err
+	}
+	return []models.Statement{statement}, nil
+}
+
+// PostStatements stores a statement or an array of them and returns their
+// IDs.
+func PostStatements(xapi *service.XAPIService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		statements, err := bindStatements(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ids, err := xapi.Store(c.Request.Context(), currentUser(c), statements, time.Now())
+		if err != nil {
+			writeXAPIError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, ids)
+	}
+}
+
+// PutStatement stores a single statement under the ID given by the
+// statementId query parameter.
+func PutStatement(xapi *service.XAPIService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Query("statementId")
+		if id == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "statementId is required"})
+			return
+		}
+
+		statements, err := bindStatements(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if len(statements) != 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Expected a single statement"})
+			return
+		}
+
+		statement := statements[0]
+		if statement.ID == "" {
+			statement.ID = id
+		} else if !strings.EqualFold(statement.ID, id) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "statementId does not match the id of the statement"})
+			return
+		}
+
+		_, err = xapi.Store(c.Request.Context(), currentUser(c), []models.Statement{statement}, time.Now())
+		if err != nil {
+			writeXAPIError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// statementFilters are the query parameters of a statement listing, which
+// cannot be combined with statementId or voidedStatementId.
+var statementFilters = []string{"agent", "verb", "activity", "registration", "since", "until", "limit", "ascending", "cursor"}
+
+// GetStatements returns a single statement selected by statementId or
+// voidedStatementId, or the page of the user's statements matching the
+// filters in the query. Further pages are linked by more.
+func GetStatements(xapi *service.XAPIService, limits PageLimits) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, voidedID := c.Query("statementId"), c.Query("voidedStatementId")
+		if id != "" || voidedID != "" {
+			if id != "" && voidedID != "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "statementId cannot be combined with voidedStatementId"})
+				return
+			}
+			for _, name := range statementFilters {
+				if _, ok := c.GetQuery(name); ok {
+					c.JSON(http.StatusBadRequest, gin.H{"error": name + " cannot be combined with a statement ID"})
+					return
+				}
+			}
+
+			statement, err := xapi.Get(c.Request.Context(), currentUser(c).ID, id+voidedID, voidedID != "")
+			if err != nil {
+				writeXAPIError(c, err)
+				return
+			}
+
+			c.JSON(http.StatusOK, statement)
+			return
+		}
+
+		filter, keyset, err := parseStatementQuery(c, limits)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		consistentThrough := time.Now()
+		statements, next, err := xapi.List(c.Request.Context(), currentUser(c).ID, filter, keyset)
+		if err != nil {
+			writeXAPIError(c, err)
+			return
+		}
+
+		more := ""
+		if !next.IsZero() {
+			query := c.Request.URL.Query()
+			query.Set("cursor", encodeCursor(next))
+			more = c.Request.URL.Path + "?" + query.Encode()
+		}
+
+		c.Header("X-Experience-API-Consistent-Through", consistentThrough.UTC().Format(time.RFC3339Nano))
+		c.JSON(http.StatusOK, gin.H{"statements": statements, "more": more})
+	}
+}
+
+// parseStatementQuery reads the filters of a statement listing. A limit of
+// 0, or none, means the default page size and larger ones are capped.
+func parseStatementQuery(c *gin.Context, limits PageLimits) (repository.StatementFilter, repository.Keyset, error) {
+	filter := repository.StatementFilter{
+		VerbID:       c.Query("verb"),
+		ObjectID:     c.Query("activity"),
+		Registration: c.Query("registration"),
+	}
+	keyset := repository.Keyset{Limit: limits.Default}
+	var err error
+
+	if v := c.Query("agent"); v != "" {
+		if filter.ActorKey, err = parseAgent(v); err != nil {
+			return filter, keyset, err
+		}
+	}
+	if v := c.Query("since"); v != "" {
+		if filter.Since, err = time.Parse(time.RFC3339Nano, v); err != nil {
+			return filter, keyset, errors.New("since must be an RFC 3339 time")
+		}
+	}
+	if v := c.Query("until"); v != "" {
+		if filter.Until, err = time.Parse(time.RFC3339Nano, v); err != nil {
+			return filter, keyset, errors.New("until must be an RFC 3339 time")
+		}
+	}
+	if v := c.Query("ascending"); v != "" {
+		if filter.Ascending, err = strconv.ParseBool(v); err != nil {
+			return filter, keyset, errors.New("ascending must be true or false")
+		}
+	}
+
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return filter, keyset, errors.New("limit must be a non-negative integer")
+		}
+		if n > 0 {
+			keyset.Limit = min(n, limits.Max)
+		}
+	}
+	if v := c.Query("cursor"); v != "" {
+		if keyset.After, err = decodeCursor(v); err != nil {
+			return filter, keyset, err
+		}
+	}
+
+	return filter, keyset, nil
+}
+
+// parseAgent reads an agent given as JSON in a query parameter and returns
+// its key.
+func parseAgent(raw string) (string, error) {
+	var agent models.Agent
+	if err := json.Unmarshal([]byte(raw), &agent); err != nil {
+		return "", errors.New("agent must be a JSON object")
+	}
+	key, err := agent.Key()
+	if err != nil {
+		return "", errors.New("agent: " + err.Error())
+	}
+	return key, nil
+}
+
+// parseStateKey reads the activityId, agent, registration and stateId query
+// parameters naming state documents of the user.
+func parseStateKey(c *gin.Context) (repository.StateKey, error) {
+	key := repository.StateKey{
+		UserID:       currentUser(c).ID,
+		ActivityID:   c.Query("activityId"),
+		Registration: c.Query("registration"),
+		StateID:      c.Query("stateId"),
+	}
+	if key.ActivityID == "" {
+		return key, errors.New("activityId is required")
+	}
+
+	agent := c.Query("agent")
+	if agent == "" {
+		return key, errors.New("agent is required")
+	}
+	var err error
+	key.AgentKey, err = parseAgent(agent)
+	return key, err
+}
+
+// GetActivityState returns the state document named by stateId, or the IDs
+// of the documents of the activity and agent if there is no stateId.
+func GetActivityState(xapi *service.XAPIService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, err := parseStateKey(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if key.StateID == "" {
+			var since time.Time
+			if v := c.Query("since"); v != "" {
+				if since, err = time.Parse(time.RFC3339Nano, v); err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "since must be an RFC 3339 time"})
+					return
+				}
+			}
+
+			ids, err := xapi.StateIDs(c.Request.Context(), key, since)
+			if err != nil {
+				writeXAPIError(c, err)
+				return
+			}
+
+			c.JSON(http.StatusOK, ids)
+			return
+		}
+
+		state, err := xapi.State(c.Request.Context(), key)
+		if err != nil {
+			writeXAPIError(c, err)
+			return
+		}
+
+		c.Data(http.StatusOK, state.ContentType, state.Document)
+	}
+}
+
+// SaveActivityState stores the request body as a state document. PUT
+// replaces the document, POST, with merge, merges a JSON object into it.
+func SaveActivityState(xapi *service.XAPIService, merge bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, err := parseStateKey(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		contentType := c.GetHeader("Content-Type")
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		if merge && c.ContentType() != "application/json" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Merged state documents must be sent as application/json"})
+			return
+		}
+
+		document, err := c.GetRawData()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		err = xapi.SaveState(c.Request.Context(), key, contentType, document, merge, time.Now())
+		if err != nil {
+			writeXAPIError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// DeleteActivityState deletes the state document named by stateId, or all
+// documents of the activity and agent if there is no stateId.
+func DeleteActivityState(xapi *service.XAPIService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, err := parseStateKey(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := xapi.DeleteState(c.Request.Context(), key); err != nil {
+			writeXAPIError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestXAPIStatements(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	db := setupTestDB(t)
+	defer db.Close()
+	svc := newTestServices(db)
+
+	r.GET("/xapi/about", GetXAPIAbout())
+	lrs := r.Group("/xapi", XAPIVersion(), authenticateAs(svc.Users, 1))
+	lrs.POST("/statements", PostStatements(svc.XAPI))
+	lrs.PUT("/statements", PutStatement(svc.XAPI))
+	lrs.GET("/statements", GetStatements(svc.XAPI, PageLimits{Default: 2, Max: 10}))
+
+	// Session 1 is active and holds the only review of word 1
+	answer := `{
+		"actor": {"mbox": "mailto:hana@example.com"},
+		"verb": {"id": "http://adlnet.gov/expapi/verbs/answered"},
+		"object": {"id": "` + svc.XAPI.WordIRI(2) + `"},
+		"result": {"success": true},
+		"context": {"contextActivities": {"parent": {"id": "` + svc.XAPI.SessionIRI(1) + `"}}}
+	}`
+	lesson := `{
+		"actor": {"mbox": "mailto:hana@example.com"},
+		"verb": {"id": "http://adlnet.gov/expapi/verbs/experienced"},
+		"object": {"id": "http://example.com/lessons/1"}
+	}`
+	const putID = "0b8f0a4e-2c1d-4f3a-9e5b-6c7d8e9fa0b1"
+
+	tests := []struct {
+		name       string
+		method     string
+		query      string
+		body       string
+		noVersion  bool
+		wantStatus int
+		wantIDs    int
+	}{
+		{"Missing version header", "POST", "", answer, true, http.StatusBadRequest, 0},
+		{"Answer", "POST", "", answer, false, http.StatusOK, 1},
+		{"Batch", "POST", "", "[" + lesson + "," + lesson + "]", false, http.StatusOK, 2},
+		{"Malformed JSON", "POST", "", `{"actor":`, false, http.StatusBadRequest, 0},
+		{"Unknown word", "POST", "", strings.Replace(answer, svc.XAPI.WordIRI(2), svc.XAPI.WordIRI(99), 1), false, http.StatusBadRequest, 0},
+		{"Put", "PUT", "?statementId=" + putID, lesson, false, http.StatusNoContent, 0},
+		{"Put again", "PUT", "?statementId=" + putID, lesson, false, http.StatusNoContent, 0},
+		{"Put a different statement", "PUT", "?statementId=" + putID, answer, false, http.StatusConflict, 0},
+		{"Put without ID", "PUT", "", lesson, false, http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, "/xapi/statements"+tt.query, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if !tt.noVersion {
+				req.Header.Set("X-Experience-API-Version", "1.0.3")
+			}
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			assert.Equal(t, "1.0.3", w.Header().Get("X-Experience-API-Version"))
+
+			if tt.wantStatus == http.StatusOK {
+				var ids []string
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &ids))
+				assert.Len(t, ids, tt.wantIDs)
+			}
+		})
+	}
+
+	// The answer was recorded as a review
+	var reviews int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM word_review_items WHERE word_id = 2 AND correct").Scan(&reviews))
+	assert.Equal(t, 1, reviews)
+
+	get := func(query string) (int, map[string]json.RawMessage) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/xapi/statements"+query, nil)
+		req.Header.Set("X-Experience-API-Version", "1.0.3")
+		r.ServeHTTP(w, req)
+
+		var body map[string]json.RawMessage
+		if w.Code == http.StatusOK {
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		}
+		return w.Code, body
+	}
+
+	// Four statements were stored, listed two at a time
+	status, body := get("")
+	require.Equal(t, http.StatusOK, status)
+	var statements []map[string]any
+	var more string
+	assert.NoError(t, json.Unmarshal(body["statements"], &statements))
+	assert.NoError(t, json.Unmarshal(body["more"], &more))
+	assert.Len(t, statements, 2)
+	require.True(t, strings.HasPrefix(more, "/xapi/statements?"), more)
+
+	status, body = get(strings.TrimPrefix(more, "/xapi/statements"))
+	require.Equal(t, http.StatusOK, status)
+	assert.NoError(t, json.Unmarshal(body["statements"], &statements))
+	assert.NoError(t, json.Unmarshal(body["more"], &more))
+	assert.Len(t, statements, 2)
+	assert.Empty(t, more)
+
+	status, body = get("?verb=" + url.QueryEscape("http://adlnet.gov/expapi/verbs/answered"))
+	require.Equal(t, http.StatusOK, status)
+	assert.NoError(t, json.Unmarshal(body["statements"], &statements))
+	if assert.Len(t, statements, 1) {
+		assert.Equal(t, map[string]any{"success": true}, statements[0]["result"])
+	}
+
+	status, body = get("?agent=" + url.QueryEscape(`{"mbox":"mailto:kenji@example.com"}`))
+	require.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `[]`, string(body["statements"]))
+
+	status, body = get("?statementId=" + strings.ToUpper(putID))
+	require.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `"`+putID+`"`, string(body["id"]))
+	assert.JSONEq(t, `{"objectType":"Agent","name":"default","account":{"homePage":"http://localhost:4000","name":"1"}}`, string(body["authority"]))
+
+	status, _ = get("?voidedStatementId=" + putID)
+	assert.Equal(t, http.StatusNotFound, status)
+	status, _ = get("?statementId=" + putID + "&verb=x")
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = get("?since=yesterday")
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = get("?agent=hana")
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/xapi/about", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"version":["1.0.3"]}`, w.Body.String())
+}
+
+func TestXAPIState(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	db := setupTestDB(t)
+	defer db.Close()
+	svc := newTestServices(db)
+
+	lrs := r.Group("/xapi", XAPIVersion(), authenticateAs(svc.Users, 1))
+	lrs.GET("/activities/state", GetActivityState(svc.XAPI))
+	lrs.PUT("/activities/state", SaveActivityState(svc.XAPI, false))
+	lrs.POST("/activities/state", SaveActivityState(svc.XAPI, true))
+	lrs.DELETE("/activities/state", DeleteActivityState(svc.XAPI))
+
+	document := "?activityId=" + url.QueryEscape("http://example.com/lessons/1") +
+		"&agent=" + url.QueryEscape(`{"mbox":"mailto:hana@example.com"}`)
+
+	tests := []struct {
+		name        string
+		method      string
+		query       string
+		contentType string
+		body        string
+		wantStatus  int
+		wantBody    string
+	}{
+		{"Put", "PUT", document + "&stateId=progress", "application/json", `{"card":3,"deck":"n5"}`, http.StatusNoContent, ""},
+		{"Merge", "POST", document + "&stateId=progress", "application/json", `{"card":4}`, http.StatusNoContent, ""},
+		{"Get", "GET", document + "&stateId=progress", "", "", http.StatusOK, `{"card":4,"deck":"n5"}`},
+		{"Put text", "PUT", document + "&stateId=notes", "text/plain", "remember kanji", http.StatusNoContent, ""},
+		{"Merge text", "POST", document + "&stateId=notes", "text/plain", "and kana", http.StatusBadRequest, ""},
+		{"List", "GET", document, "", "", http.StatusOK, `["notes","progress"]`},
+		{"Delete", "DELETE", document + "&stateId=progress", "", "", http.StatusNoContent, ""},
+		{"Get deleted", "GET", document + "&stateId=progress", "", "", http.StatusNotFound, ""},
+		{"Missing agent", "GET", "?activityId=" + url.QueryEscape("http://example.com/lessons/1"), "", "", http.StatusBadRequest, ""},
+		{"Bad registration", "GET", document + "&registration=first", "", "", http.StatusBadRequest, ""},
+		{"Delete all", "DELETE", document, "", "", http.StatusNoContent, ""},
+		{"List after delete", "GET", document, "", "", http.StatusOK, `[]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, "/xapi/activities/state"+tt.query, strings.NewReader(tt.body))
+			req.Header.Set("X-Experience-API-Version", "1.0.3")
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+
+	// Documents come back with the type they were stored with
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/xapi/activities/state"+document+"&stateId=notes", strings.NewReader("remember kanji"))
+	req.Header.Set("X-Experience-API-Version", "1.0.3")
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/xapi/activities/state"+document+"&stateId=notes", nil)
+	req.Header.Set("X-Experience-API-Version", "1.0.3")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "remember kanji", w.Body.String())
+}
@@ -0,0 +1,22 @@
+package models
+
+// LastStudySession is the most recent study session shown on the dashboard.
+type LastStudySession struct {
+	ID              int64  `json:"id"`
+	GroupID         int64  `json:"group_id"`
+	GroupName       string `json:"group_name"`
+	StudyActivityID int64  `json:"study_activity_id"`
+	CreatedAt       string `json:"created_at"`
+}
+
+type StudyProgress struct {
+	TotalWordsStudied   int `json:"total_words_studied"`
+	TotalAvailableWords int `json:"total_available_words"`
+}
+
+type QuickStats struct {
+	SuccessRate        float64 `json:"success_rate"`
+	TotalStudySessions int     `json:"total_study_sessions"`
+	TotalActiveGroups  int     `json:"total_active_groups"`
+	StudyStreakDays    int     `json:"study_streak_days"`
+}
@@ -0,0 +1,9 @@
+package models
+
+// ExportWord is a word together with the names of the groups it belongs to
+// and, optionally, its review statistics.
+type ExportWord struct {
+	Word
+	Groups []string   `json:"groups"`
+	Stats  *WordStats `json:"stats,omitempty"`
+}
@@ -1,44 +1,29 @@
 package models
 
 import (
-	"database/sql"
+	"errors"
 )
 
+// ErrDuplicateGroupName is returned when a group name is already taken.
+var ErrDuplicateGroupName = errors.New("group name already exists")
+
 type Group struct {
-	ID   int64  `json:"id"`
-	Name string `json:"name"`
+	ID   int64  `json:"id" db:"id"`
+	Name string `json:"name" db:"name"`
 }
 
 type GroupStats struct {
 	TotalWordCount int `json:"total_word_count"`
 }
 
-func GetGroup(db *sql.DB, id int64) (*Group, error) {
-	var group Group
-	err := db.QueryRow(`
-		SELECT id, name
-		FROM groups
-		WHERE id = ?
-	`, id).Scan(&group.ID, &group.Name)
-	
-	if err != nil {
-		return nil, err
-	}
-	
-	return &group, nil
+// GroupSummary is a group as listed on the groups page.
+type GroupSummary struct {
+	Group
+	WordCount int `json:"word_count"`
 }
 
-func GetGroupStats(db *sql.DB, groupID int64) (*GroupStats, error) {
-	var stats GroupStats
-	err := db.QueryRow(`
-		SELECT COUNT(DISTINCT word_id)
-		FROM word_groups
-		WHERE group_id = ?
-	`, groupID).Scan(&stats.TotalWordCount)
-	
-	if err != nil {
-		return nil, err
-	}
-	
-	return &stats, nil
-} 
\ No newline at end of file
+// GroupWord is a word as listed on a group's page.
+type GroupWord struct {
+	Word
+	WordStats
+}
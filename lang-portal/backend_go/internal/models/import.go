@@ -0,0 +1,124 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// SeedWord is a word as stored in the seed files under db/seeds.
+type SeedWord struct {
+	Japanese string `json:"japanese"`
+	Romaji   string `json:"romaji"`
+	English  string `json:"english"`
+	Parts    Parts  `json:"parts"`
+}
+
+// SeedFile is the structure of the seed files under db/seeds.
+type SeedFile struct {
+	GroupName string     `json:"group_name"`
+	Words     []SeedWord `json:"words"`
+}
+
+// ImporterWord is a word as generated by the vocabulary-importer app, its
+// parts an array of segments.
+type ImporterWord struct {
+	Kanji   string          `json:"kanji"`
+	Romaji  string          `json:"romaji"`
+	English string          `json:"english"`
+	Parts   json.RawMessage `json:"parts"`
+}
+
+// ImporterFile is the response body of the vocabulary-importer app.
+type ImporterFile struct {
+	Vocabulary []ImporterWord `json:"vocabulary"`
+}
+
+// ErrUnknownImportFormat is returned when an import payload is neither a
+// SeedFile nor an ImporterFile.
+var ErrUnknownImportFormat = errors.New(`import must contain a "vocabulary" or "words" list`)
+
+// Import statuses reported for each item.
+const (
+	ImportCreated = "created"
+	ImportLinked  = "linked"
+	ImportSkipped = "skipped"
+)
+
+// ImportResult reports what happened to a single imported item.
+type ImportResult struct {
+	Index    int    `json:"index"`
+	Japanese string `json:"japanese"`
+	Status   string `json:"status"`
+	WordID   int64  `json:"word_id,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// ParseImport decodes an import payload in either the vocabulary-importer or
+// the SeedFile format into words ready to be inserted.
+func ParseImport(data []byte) ([]Word, error) {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, err
+	}
+
+	if _, ok := probe["vocabulary"]; ok {
+		var file ImporterFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, err
+		}
+
+		words := make([]Word, len(file.Vocabulary))
+		for i, item := range file.Vocabulary {
+			words[i] = Word{
+				Japanese: item.Kanji,
+				Romaji:   item.Romaji,
+				English:  item.English,
+			}
+			if err := parseParts(item.Parts, &words[i].Parts); err != nil {
+				return nil, fmt.Errorf("vocabulary item %d: %w", i+1, err)
+			}
+		}
+		return words, nil
+	}
+
+	if _, ok := probe["words"]; ok {
+		// A SeedFile whose parts are decoded item by item, so that errors
+		// tell which item is malformed
+		var file struct {
+			Words []struct {
+				Japanese string          `json:"japanese"`
+				Romaji   string          `json:"romaji"`
+				English  string          `json:"english"`
+				Parts    json.RawMessage `json:"parts"`
+			} `json:"words"`
+		}
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, err
+		}
+
+		words := make([]Word, len(file.Words))
+		for i, item := range file.Words {
+			words[i] = Word{
+				Japanese: item.Japanese,
+				Romaji:   item.Romaji,
+				English:  item.English,
+			}
+			if err := parseParts(item.Parts, &words[i].Parts); err != nil {
+				return nil, fmt.Errorf("words item %d: %w", i+1, err)
+			}
+		}
+		return words, nil
+	}
+
+	return nil, ErrUnknownImportFormat
+}
+
+// parseParts decodes the parts of an imported item, which may be missing.
+func parseParts(data json.RawMessage, parts *Parts) error {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	return parts.UnmarshalJSON(data)
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// Kanji is a character with its readings and meanings. Words are linked to
+// the kanji they are written with as they are stored.
+type Kanji struct {
+	ID          int64      `json:"id" db:"id"`
+	Character   string     `json:"character" db:"literal"`
+	OnReadings  StringList `json:"on_readings" db:"on_readings"`
+	KunReadings StringList `json:"kun_readings" db:"kun_readings"`
+	Meanings    StringList `json:"meanings" db:"meanings"`
+	StrokeCount int        `json:"stroke_count" db:"stroke_count"`
+	// JLPTLevel is the level of the JLPT, 5 (N5) to 1 (N1), that first
+	// tests the kanji, or nil if none does
+	JLPTLevel *int `json:"jlpt_level" db:"jlpt_level"`
+}
+
+// KanjiStats sums up a user's reviews of the words written with a kanji.
+type KanjiStats struct {
+	CorrectCount int `json:"correct_count"`
+	WrongCount   int `json:"wrong_count"`
+	// SuccessRate is the percentage of correct reviews, 0 without reviews
+	SuccessRate float64 `json:"success_rate"`
+}
+
+// StringList is a list of strings stored as a JSON array.
+type StringList []string
+
+// Value stores the list as JSON text, an empty list for nil.
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal([]string(l))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan reads a list stored as JSON text.
+func (l *StringList) Scan(src any) error {
+	switch src := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(src), (*[]string)(l))
+	case []byte:
+		return json.Unmarshal(src, (*[]string)(l))
+	default:
+		return fmt.Errorf("cannot scan %T into a string list", src)
+	}
+}
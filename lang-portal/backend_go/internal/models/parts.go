@@ -0,0 +1,168 @@
+package models
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidParts is returned when a word's parts are neither a JSON object
+// nor an array of segments.
+var ErrInvalidParts = errors.New("parts must be a JSON object or array")
+
+// Parts describes a word beyond its text: the part of speech and other
+// grammatical metadata, and how the japanese text splits into segments.
+// Every field is optional.
+type Parts struct {
+	// Type is the part of speech, e.g. "verb", "greeting" or "number"
+	Type      string `json:"type,omitempty"`
+	Formality string `json:"formality,omitempty"`
+	Category  string `json:"category,omitempty"`
+	// Number is the value of a numeral
+	Number   *int      `json:"value,omitempty"`
+	Segments []Segment `json:"segments,omitempty"`
+}
+
+// Segment is a run of kanji or kana of a word with its reading, e.g. 食 read
+// "ta" in 食べる.
+type Segment struct {
+	Kanji  string `json:"kanji"`
+	Romaji string `json:"romaji"`
+}
+
+// UnmarshalJSON reads a segment whose romaji may be split into a list of
+// readings, as the vocabulary importer wrote some of them. The readings are
+// joined, as migration 0015 joined the stored ones.
+func (s *Segment) UnmarshalJSON(data []byte) error {
+	var segment struct {
+		Kanji  string          `json:"kanji"`
+		Romaji json.RawMessage `json:"romaji"`
+	}
+	if err := json.Unmarshal(data, &segment); err != nil {
+		return err
+	}
+
+	var romaji string
+	switch {
+	case len(segment.Romaji) == 0 || string(segment.Romaji) == "null":
+	case segment.Romaji[0] == '[':
+		var readings []string
+		if err := json.Unmarshal(segment.Romaji, &readings); err != nil {
+			return err
+		}
+		romaji = strings.Join(readings, "")
+	default:
+		if err := json.Unmarshal(segment.Romaji, &romaji); err != nil {
+			return err
+		}
+	}
+
+	*s = Segment{Kanji: segment.Kanji, Romaji: romaji}
+	return nil
+}
+
+// partsObject is the object form of Parts. The seed files used to call
+// formality politeness.
+type partsObject struct {
+	Type       string    `json:"type"`
+	Formality  string    `json:"formality"`
+	Politeness string    `json:"politeness"`
+	Category   string    `json:"category"`
+	Value      *int      `json:"value"`
+	Segments   []Segment `json:"segments"`
+}
+
+// UnmarshalJSON reads parts in any of the shapes words were stored in: an
+// object, the vocabulary importer's bare array of segments, or either of
+// them encoded as a JSON string. Null leaves p unchanged.
+func (p *Parts) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return ErrInvalidParts
+	}
+	if string(data) == "null" {
+		return nil
+	}
+
+	switch data[0] {
+	case '"':
+		var encoded string
+		if err := json.Unmarshal(data, &encoded); err != nil {
+			return ErrInvalidParts
+		}
+		return p.UnmarshalJSON([]byte(encoded))
+	case '[':
+		var segments []Segment
+		if err := json.Unmarshal(data, &segments); err != nil {
+			return fmt.Errorf("parts segments must have a kanji string and a romaji string or list of strings: %w", ErrInvalidParts)
+		}
+		*p = Parts{Segments: segments}
+	case '{':
+		var object partsObject
+		if err := json.Unmarshal(data, &object); err != nil {
+			return fmt.Errorf("parts fields have the wrong type: %w", ErrInvalidParts)
+		}
+		*p = Parts{
+			Type:      object.Type,
+			Formality: object.Formality,
+			Category:  object.Category,
+			Number:    object.Value,
+			Segments:  object.Segments,
+		}
+		if p.Formality == "" {
+			p.Formality = object.Politeness
+		}
+	default:
+		return ErrInvalidParts
+	}
+
+	if len(p.Segments) == 0 {
+		p.Segments = nil
+	}
+	return nil
+}
+
+// Value stores parts as JSON text.
+func (p Parts) Value() (driver.Value, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan reads parts stored as JSON text.
+func (p *Parts) Scan(src any) error {
+	switch src := src.(type) {
+	case string:
+		return p.UnmarshalJSON([]byte(src))
+	case []byte:
+		return p.UnmarshalJSON(src)
+	default:
+		return fmt.Errorf("cannot scan %T into parts", src)
+	}
+}
+
+// ValidateParts checks that the segments of parts, if any, spell out the
+// japanese text of their word and each have a reading.
+func ValidateParts(japanese string, parts Parts) error {
+	if len(parts.Segments) == 0 {
+		return nil
+	}
+
+	var spelled strings.Builder
+	for i, segment := range parts.Segments {
+		if segment.Kanji == "" || segment.Romaji == "" {
+			return fmt.Errorf("parts segment %d must have a kanji and a romaji", i+1)
+		}
+		spelled.WriteString(segment.Kanji)
+	}
+	if spelled.String() != japanese {
+		return fmt.Errorf("parts segments spell %q instead of %q", spelled.String(), japanese)
+	}
+
+	return nil
+}
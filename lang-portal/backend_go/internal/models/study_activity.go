@@ -1,29 +1,71 @@
 package models
 
 import (
-	"database/sql"
+	"errors"
+	"slices"
 	"time"
 )
 
+// ErrDuplicateActivitySlug is returned when a study activity slug is
+// already taken.
+var ErrDuplicateActivitySlug = errors.New("study activity slug already exists")
+
+// ErrActivityInUse is returned when deleting a study activity that study
+// sessions were recorded with.
+var ErrActivityInUse = errors.New("study activity has study sessions")
+
+// Word fields an activity may require the words it practises to have. The
+// japanese text is always there.
+var WordFields = []string{"romaji", "english", "parts.type", "parts.formality", "parts.category", "parts.segments"}
+
 type StudyActivity struct {
-	ID          int64     `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	ThumbnailURL string  `json:"thumbnail_url"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID int64 `json:"id" db:"id"`
+	// Slug names the activity in manifests and URLs, such as
+	// "vocabulary-quiz".
+	Slug         string `json:"slug" db:"slug"`
+	Name         string `json:"name" db:"name"`
+	Description  string `json:"description" db:"description"`
+	ThumbnailURL string `json:"thumbnail_url" db:"thumbnail_url"`
+	// LaunchURL is the address of the app the activity runs in. It may
+	// hold the placeholders {session_id}, {group_id}, {activity_id} and
+	// {token}, filled in when the activity is launched.
+	LaunchURL string `json:"launch_url" db:"launch_url"`
+	// SupportedGroupTypes are the word types (parts.type) the activity
+	// practises, such as "verb". Groups without a word of one of them cannot
+	// be studied with it. Empty means any.
+	SupportedGroupTypes StringList `json:"supported_group_types" db:"supported_group_types"`
+	// RequiredWordFields are the WordFields a word needs for the activity to
+	// use it.
+	RequiredWordFields StringList `json:"required_word_fields" db:"required_word_fields"`
+	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
 }
 
-func GetStudyActivity(db *sql.DB, id int64) (*StudyActivity, error) {
-	var activity StudyActivity
-	err := db.QueryRow(`
-		SELECT id, name, description, thumbnail_url, created_at
-		FROM study_activities
-		WHERE id = ?
-	`, id).Scan(&activity.ID, &activity.Name, &activity.Description, &activity.ThumbnailURL, &activity.CreatedAt)
-	
-	if err != nil {
-		return nil, err
+// Fits reports whether the activity can use a word: it has the required
+// fields and, if the activity is limited to some word types, one of them.
+func (a *StudyActivity) Fits(word *Word) bool {
+	if len(a.SupportedGroupTypes) > 0 && !slices.Contains(a.SupportedGroupTypes, word.Parts.Type) {
+		return false
 	}
-	
-	return &activity, nil
-} 
\ No newline at end of file
+
+	for _, field := range a.RequiredWordFields {
+		var empty bool
+		switch field {
+		case "romaji":
+			empty = word.Romaji == ""
+		case "english":
+			empty = word.English == ""
+		case "parts.type":
+			empty = word.Parts.Type == ""
+		case "parts.formality":
+			empty = word.Parts.Formality == ""
+		case "parts.category":
+			empty = word.Parts.Category == ""
+		case "parts.segments":
+			empty = len(word.Parts.Segments) == 0
+		}
+		if empty {
+			return false
+		}
+	}
+	return true
+}
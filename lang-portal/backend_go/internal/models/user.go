@@ -0,0 +1,54 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// DefaultUserID is the account that owns the study history recorded before
+// the portal had accounts. It is an admin.
+const DefaultUserID int64 = 1
+
+// Roles, in increasing order of privilege. Each role may do everything the
+// roles before it may.
+const (
+	RoleStudent = "student"
+	RoleTeacher = "teacher"
+	RoleAdmin   = "admin"
+)
+
+var roleRanks = map[string]int{
+	RoleStudent: 1,
+	RoleTeacher: 2,
+	RoleAdmin:   3,
+}
+
+// ErrDuplicateUserName is returned when a user name is already taken.
+var ErrDuplicateUserName = errors.New("user name already exists")
+
+type User struct {
+	ID           int64     `json:"id" db:"id"`
+	Name         string    `json:"name" db:"name"`
+	Role         string    `json:"role" db:"role"`
+	PasswordHash string    `json:"-" db:"password_hash"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// ValidRole reports whether role is one of the known roles.
+func ValidRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// HasRole reports whether the user's role grants at least role.
+func (u *User) HasRole(role string) bool {
+	return roleRanks[u.Role] >= roleRanks[role] && ValidRole(role)
+}
+
+// AuthToken is an issued bearer token. Only a hash of the token is stored.
+type AuthToken struct {
+	Hash      string    `json:"-" db:"hash"`
+	UserID    int64     `json:"user_id" db:"user_id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+}
@@ -1,50 +1,39 @@
 package models
 
-import (
-	"database/sql"
-)
+import "time"
 
 type Word struct {
-	ID       int64  `json:"id"`
-	Japanese string `json:"japanese"`
-	Romaji   string `json:"romaji"`
-	English  string `json:"english"`
-	Parts    string `json:"parts"`
+	ID       int64  `json:"id" db:"id"`
+	Japanese string `json:"japanese" db:"japanese"`
+	Romaji   string `json:"romaji" db:"romaji"`
+	English  string `json:"english" db:"english"`
+	Parts    Parts  `json:"parts" db:"parts"`
 }
 
-type WordStats struct {
-	CorrectCount int `json:"correct_count"`
-	WrongCount   int `json:"wrong_count"`
+// WordSummary is a word as listed on the words page, with a user's review
+// statistics.
+type WordSummary struct {
+	Word
+	WordStats
 }
 
-func GetWord(db *sql.DB, id int64) (*Word, error) {
-	var word Word
-	err := db.QueryRow(`
-		SELECT id, japanese, romaji, english, parts
-		FROM words
-		WHERE id = ?
-	`, id).Scan(&word.ID, &word.Japanese, &word.Romaji, &word.English, &word.Parts)
-	
-	if err != nil {
-		return nil, err
-	}
-	
-	return &word, nil
+// WordMatch is a listed word. When the words are searched, Highlights maps
+// each field the search matched to its text, HTML-escaped, with the matching
+// parts wrapped in <mark> tags.
+type WordMatch struct {
+	WordSummary
+	Highlights map[string]string `json:"highlights,omitempty"`
 }
 
-func GetWordStats(db *sql.DB, wordID int64) (*WordStats, error) {
-	var stats WordStats
-	err := db.QueryRow(`
-		SELECT 
-			COUNT(CASE WHEN correct = 1 THEN 1 END) as correct_count,
-			COUNT(CASE WHEN correct = 0 THEN 1 END) as wrong_count
-		FROM word_review_items
-		WHERE word_id = ?
-	`, wordID).Scan(&stats.CorrectCount, &stats.WrongCount)
-	
-	if err != nil {
-		return nil, err
-	}
-	
-	return &stats, nil
-} 
\ No newline at end of file
+// WordStats sums up a user's reviews of a word. The store keeps the counts
+// in the word_stats table as reviews are recorded and deleted. Words the
+// user never reviewed have no row and zero counts.
+type WordStats struct {
+	UserID         int64      `json:"-" db:"user_id"`
+	WordID         int64      `json:"-" db:"word_id"`
+	CorrectCount   int        `json:"correct_count" db:"correct_count"`
+	WrongCount     int        `json:"wrong_count" db:"wrong_count"`
+	LastReviewedAt *time.Time `json:"last_reviewed_at" db:"last_reviewed_at"`
+	// Mastery is one of the Mastery levels, from the word's review schedule
+	Mastery string `json:"mastery"`
+}
@@ -1,33 +1,48 @@
 package models
 
 import (
-	"database/sql"
 	"time"
 )
 
+// Prompt directions of a review: what the learner was shown, and what they
+// answered with.
+const (
+	DirectionJapaneseToEnglish = "ja-en"
+	DirectionEnglishToJapanese = "en-ja"
+	DirectionAudioToJapanese   = "audio-ja"
+)
+
+// Directions are the prompt directions a review may have.
+var Directions = []string{DirectionJapaneseToEnglish, DirectionEnglishToJapanese, DirectionAudioToJapanese}
+
 type WordReview struct {
-	ID             int64     `json:"id"`
-	WordID         int64     `json:"word_id"`
-	StudySessionID int64     `json:"study_session_id"`
-	Correct        bool      `json:"correct"`
-	CreatedAt      time.Time `json:"created_at"`
+	ID             int64 `json:"id" db:"id"`
+	UserID         int64 `json:"user_id" db:"user_id"`
+	WordID         int64 `json:"word_id" db:"word_id"`
+	StudySessionID int64 `json:"study_session_id" db:"study_session_id"`
+	Correct        bool  `json:"correct" db:"correct"`
+	// AnswerGiven is what the learner typed or picked, empty if the
+	// activity did not say.
+	AnswerGiven string `json:"answer_given" db:"answer_given"`
+	// ResponseMS is how long the learner took to answer, nil if unknown.
+	ResponseMS *int64 `json:"response_ms" db:"response_ms"`
+	// Quality is the SM-2 grade (0-5) the review moved the schedule by.
+	Quality int `json:"quality" db:"quality"`
+	// Direction is one of Directions, empty if unknown.
+	Direction string    `json:"direction" db:"direction"`
+	HintsUsed int       `json:"hints_used" db:"hints_used"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
 
-func CreateWordReview(db *sql.DB, review *WordReview) error {
-	result, err := db.Exec(`
-		INSERT INTO word_review_items (word_id, study_session_id, correct, created_at)
-		VALUES (?, ?, ?, ?)
-	`, review.WordID, review.StudySessionID, review.Correct, review.CreatedAt)
-	
-	if err != nil {
-		return err
-	}
-	
-	id, err := result.LastInsertId()
-	if err != nil {
-		return err
-	}
-	
-	review.ID = id
-	return nil
-} 
\ No newline at end of file
+// SessionWord is a word as reviewed during a study session.
+type SessionWord struct {
+	Word
+	ReviewID    int64     `json:"review_id"`
+	Correct     bool      `json:"correct"`
+	AnswerGiven string    `json:"answer_given"`
+	ResponseMS  *int64    `json:"response_ms"`
+	Quality     int       `json:"quality"`
+	Direction   string    `json:"direction"`
+	HintsUsed   int       `json:"hints_used"`
+	ReviewedAt  time.Time `json:"reviewed_at"`
+}
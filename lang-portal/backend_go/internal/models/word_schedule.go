@@ -0,0 +1,110 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	defaultEaseFactor = 2.5
+	minEaseFactor     = 1.3
+)
+
+// WordSchedule is the spaced-repetition state of a single word for one user,
+// updated with the SM-2 algorithm every time the user reviews the word.
+type WordSchedule struct {
+	UserID         int64     `json:"-" db:"user_id"`
+	WordID         int64     `json:"word_id" db:"word_id"`
+	EaseFactor     float64   `json:"ease_factor" db:"ease_factor"`
+	IntervalDays   int       `json:"interval_days" db:"interval_days"`
+	Repetitions    int       `json:"repetitions" db:"repetitions"`
+	DueAt          time.Time `json:"due_at" db:"due_at"`
+	LastReviewedAt time.Time `json:"last_reviewed_at" db:"last_reviewed_at"`
+}
+
+// Mastery levels of a word for a user. Words move up as correct answers
+// push their review schedule out, and fall back to learning when missed.
+const (
+	MasteryNew       = "new"
+	MasteryLearning  = "learning"
+	MasteryReviewing = "reviewing"
+	MasteryMastered  = "mastered"
+)
+
+// Mastery returns the mastery level of a word reviewed the given number of
+// times whose schedule has the given interval, 0 for no schedule. A word is
+// learning until it is reviewed no more than weekly, and mastered once it
+// is reviewed three weeks apart.
+func Mastery(reviews, intervalDays int) string {
+	switch {
+	case reviews == 0:
+		return MasteryNew
+	case intervalDays < 7:
+		return MasteryLearning
+	case intervalDays < 21:
+		return MasteryReviewing
+	default:
+		return MasteryMastered
+	}
+}
+
+// DueWord is a word that should be studied now. Schedule is nil for words
+// that have never been reviewed.
+type DueWord struct {
+	Word
+	Schedule *WordSchedule `json:"schedule"`
+}
+
+// NewWordSchedule returns the initial schedule for a word the user has not
+// reviewed yet.
+func NewWordSchedule(userID, wordID int64) *WordSchedule {
+	return &WordSchedule{
+		UserID:     userID,
+		WordID:     wordID,
+		EaseFactor: defaultEaseFactor,
+	}
+}
+
+// QualityFromCorrect maps a plain correct/incorrect answer onto the SM-2
+// 0-5 quality scale.
+func QualityFromCorrect(correct bool) int {
+	if correct {
+		return 4
+	}
+	return 1
+}
+
+// Apply records a review of the given quality (0-5) made at now and moves the
+// due date accordingly.
+func (s *WordSchedule) Apply(quality int, now time.Time) {
+	if quality < 0 {
+		quality = 0
+	}
+	if quality > 5 {
+		quality = 5
+	}
+
+	if quality < 3 {
+		s.Repetitions = 0
+		s.IntervalDays = 1
+	} else {
+		s.Repetitions++
+		switch s.Repetitions {
+		case 1:
+			s.IntervalDays = 1
+		case 2:
+			s.IntervalDays = 6
+		default:
+			s.IntervalDays = int(math.Round(float64(s.IntervalDays) * s.EaseFactor))
+		}
+	}
+
+	q := float64(5 - quality)
+	s.EaseFactor += 0.1 - q*(0.08+q*0.02)
+	if s.EaseFactor < minEaseFactor {
+		s.EaseFactor = minEaseFactor
+	}
+
+	s.LastReviewedAt = now.UTC()
+	s.DueAt = s.LastReviewedAt.AddDate(0, 0, s.IntervalDays)
+}
@@ -0,0 +1,205 @@
+package models
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// XAPIVersion is the version of the xAPI specification the Learning Record
+// Store implements.
+const XAPIVersion = "1.0.3"
+
+// Verbs the portal acts on, from the ADL vocabulary.
+const (
+	VerbAnswered  = "http://adlnet.gov/expapi/verbs/answered"
+	VerbCompleted = "http://adlnet.gov/expapi/verbs/completed"
+	VerbVoided    = "http://adlnet.gov/expapi/verbs/voided"
+)
+
+// Object types of a statement's object.
+const (
+	ObjectActivity     = "Activity"
+	ObjectStatementRef = "StatementRef"
+)
+
+var (
+	// ErrDuplicateStatement is returned when a statement ID is already taken.
+	ErrDuplicateStatement = errors.New("statement already exists")
+	// ErrInvalidAgent is returned for an agent without exactly one
+	// identifier.
+	ErrInvalidAgent = errors.New("agent must have exactly one of mbox, mbox_sha1sum, openid and account")
+)
+
+// Statement is an xAPI statement: an actor did something, the verb, to an
+// object. Only activities and, for voiding, other statements are supported
+// as objects.
+type Statement struct {
+	ID        string     `json:"id,omitempty"`
+	Actor     Agent      `json:"actor"`
+	Verb      Verb       `json:"verb"`
+	Object    Object     `json:"object"`
+	Result    *Result    `json:"result,omitempty"`
+	Context   *Context   `json:"context,omitempty"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	// Stored, Authority and Version are set by the LRS.
+	Stored    *time.Time `json:"stored,omitempty"`
+	Authority *Agent     `json:"authority,omitempty"`
+	Version   string     `json:"version,omitempty"`
+}
+
+// Agent is a person identified by an email address, an OpenID or an
+// account on some system.
+type Agent struct {
+	ObjectType  string   `json:"objectType,omitempty"`
+	Name        string   `json:"name,omitempty"`
+	Mbox        string   `json:"mbox,omitempty"`
+	MboxSHA1Sum string   `json:"mbox_sha1sum,omitempty"`
+	OpenID      string   `json:"openid,omitempty"`
+	Account     *Account `json:"account,omitempty"`
+}
+
+type Account struct {
+	HomePage string `json:"homePage"`
+	Name     string `json:"name"`
+}
+
+// Key returns the agent's identifier as a single string, under which its
+// statements and state documents are stored.
+func (a Agent) Key() (string, error) {
+	var keys []string
+	if a.Mbox != "" {
+		keys = append(keys, "mbox:"+a.Mbox)
+	}
+	if a.MboxSHA1Sum != "" {
+		keys = append(keys, "mbox_sha1sum:"+a.MboxSHA1Sum)
+	}
+	if a.OpenID != "" {
+		keys = append(keys, "openid:"+a.OpenID)
+	}
+	if a.Account != nil {
+		if a.Account.HomePage == "" || a.Account.Name == "" {
+			return "", fmt.Errorf("agent account must have a homePage and a name: %w", ErrInvalidAgent)
+		}
+		keys = append(keys, "account:"+a.Account.HomePage+"|"+a.Account.Name)
+	}
+	if len(keys) != 1 {
+		return "", ErrInvalidAgent
+	}
+	return keys[0], nil
+}
+
+type Verb struct {
+	ID      string            `json:"id"`
+	Display map[string]string `json:"display,omitempty"`
+}
+
+// Object is the activity a statement is about, or the statement a voiding
+// statement voids. Activity definitions are kept as they were sent.
+type Object struct {
+	ObjectType string          `json:"objectType,omitempty"`
+	ID         string          `json:"id"`
+	Definition json.RawMessage `json:"definition,omitempty"`
+}
+
+type Result struct {
+	Score      json.RawMessage            `json:"score,omitempty"`
+	Success    *bool                      `json:"success,omitempty"`
+	Completion *bool                      `json:"completion,omitempty"`
+	Response   string                     `json:"response,omitempty"`
+	Duration   string                     `json:"duration,omitempty"`
+	Extensions map[string]json.RawMessage `json:"extensions,omitempty"`
+}
+
+type Context struct {
+	Registration      string                     `json:"registration,omitempty"`
+	Instructor        *Agent                     `json:"instructor,omitempty"`
+	ContextActivities *ContextActivities         `json:"contextActivities,omitempty"`
+	Revision          string                     `json:"revision,omitempty"`
+	Platform          string                     `json:"platform,omitempty"`
+	Language          string                     `json:"language,omitempty"`
+	Extensions        map[string]json.RawMessage `json:"extensions,omitempty"`
+}
+
+// ContextActivities are the activities a statement happened within.
+type ContextActivities struct {
+	Parent   Objects `json:"parent,omitempty"`
+	Grouping Objects `json:"grouping,omitempty"`
+	Category Objects `json:"category,omitempty"`
+	Other    Objects `json:"other,omitempty"`
+}
+
+// Objects is a list of context activities. A single object is read as a
+// list of one, as older clients send it.
+type Objects []Object
+
+func (o *Objects) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		var object Object
+		if err := json.Unmarshal(data, &object); err != nil {
+			return err
+		}
+		*o = Objects{object}
+		return nil
+	}
+
+	var objects []Object
+	if err := json.Unmarshal(data, &objects); err != nil {
+		return err
+	}
+	*o = objects
+	return nil
+}
+
+// Value stores a statement as JSON text.
+func (s Statement) Value() (driver.Value, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan reads a statement stored as JSON text.
+func (s *Statement) Scan(src any) error {
+	switch src := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(src), s)
+	case []byte:
+		return json.Unmarshal(src, s)
+	default:
+		return fmt.Errorf("cannot scan %T into a statement", src)
+	}
+}
+
+// StoredStatement is a statement as kept by the LRS, with the fields it is
+// looked up by. Statements belong to the user who sent them.
+type StoredStatement struct {
+	ID           int64     `db:"id"`
+	StatementID  string    `db:"statement_id"`
+	UserID       int64     `db:"user_id"`
+	ActorKey     string    `db:"actor_key"`
+	VerbID       string    `db:"verb_id"`
+	ObjectID     string    `db:"object_id"`
+	Registration string    `db:"registration"`
+	Voided       bool      `db:"voided"`
+	Stored       time.Time `db:"stored"`
+	Statement    Statement `db:"statement"`
+}
+
+// XAPIState is a document an activity keeps for an agent, such as the
+// place a learner got to, optionally per registration.
+type XAPIState struct {
+	UserID       int64     `db:"user_id"`
+	ActivityID   string    `db:"activity_id"`
+	AgentKey     string    `db:"agent_key"`
+	Registration string    `db:"registration"`
+	StateID      string    `db:"state_id"`
+	ContentType  string    `db:"content_type"`
+	Document     []byte    `db:"document"`
+	UpdatedAt    time.Time `db:"updated_at"`
+}
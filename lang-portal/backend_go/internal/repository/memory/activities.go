@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"context"
+
+	"lang-portal/backend_go/internal/models"
+)
+
+type activityRepo struct {
+	s *Store
+}
+
+func (r *activityRepo) List(ctx context.Context) ([]models.StudyActivity, error) {
+	activities := []models.StudyActivity{}
+	for _, id := range sortedIDs(r.s.d.activities) {
+		activities = append(activities, r.s.d.activities[id])
+	}
+	return activities, nil
+}
+
+func (r *activityRepo) Get(ctx context.Context, id int64) (*models.StudyActivity, error) {
+	activity, ok := r.s.d.activities[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return &activity, nil
+}
+
+func (r *activityRepo) FindBySlug(ctx context.Context, slug string) (*models.StudyActivity, error) {
+	for _, id := range sortedIDs(r.s.d.activities) {
+		if activity := r.s.d.activities[id]; activity.Slug == slug {
+			return &activity, nil
+		}
+	}
+	return nil, models.ErrNotFound
+}
+
+// slugTaken reports whether another activity than id has the slug. Like
+// the SQL index, it ignores empty slugs.
+func (r *activityRepo) slugTaken(slug string, id int64) bool {
+	for otherID, activity := range r.s.d.activities {
+		if otherID != id && slug != "" && activity.Slug == slug {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *activityRepo) Create(ctx context.Context, activity *models.StudyActivity) error {
+	if r.slugTaken(activity.Slug, 0) {
+		return models.ErrDuplicateActivitySlug
+	}
+	activity.ID = r.s.nextID()
+	activity.CreatedAt = r.s.now()
+	r.s.d.activities[activity.ID] = *activity
+	return nil
+}
+
+func (r *activityRepo) Update(ctx context.Context, activity *models.StudyActivity) error {
+	if _, ok := r.s.d.activities[activity.ID]; !ok {
+		return models.ErrNotFound
+	}
+	if r.slugTaken(activity.Slug, activity.ID) {
+		return models.ErrDuplicateActivitySlug
+	}
+	r.s.d.activities[activity.ID] = *activity
+	return nil
+}
+
+func (r *activityRepo) Delete(ctx context.Context, id int64) error {
+	if _, ok := r.s.d.activities[id]; !ok {
+		return models.ErrNotFound
+	}
+
+	for _, session := range r.s.d.sessions {
+		if session.StudyActivityID == id {
+			return models.ErrActivityInUse
+		}
+	}
+	delete(r.s.d.activities, id)
+	return nil
+}
+
+func (r *activityRepo) DeleteAll(ctx context.Context) error {
+	r.s.d.activities = map[int64]models.StudyActivity{}
+	return nil
+}
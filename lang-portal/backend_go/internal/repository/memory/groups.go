@@ -0,0 +1,165 @@
+package memory
+
+import (
+	"context"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+)
+
+type groupRepo struct {
+	s *Store
+}
+
+func (r *groupRepo) wordIDs(id int64) []int64 {
+	ids := []int64{}
+	for _, wordID := range sortedIDs(r.s.d.words) {
+		if r.s.d.memberships[membership{wordID, id}] {
+			ids = append(ids, wordID)
+		}
+	}
+	return ids
+}
+
+var groupSortKeys = sortKeys[models.GroupSummary]{
+	"id":         func(g models.GroupSummary) any { return g.ID },
+	"name":       func(g models.GroupSummary) any { return g.Name },
+	"word_count": func(g models.GroupSummary) any { return g.WordCount },
+}
+
+func (r *groupRepo) List(ctx context.Context, opts repository.ListOptions) ([]models.GroupSummary, int, error) {
+	groups := []models.GroupSummary{}
+	for _, id := range sortedIDs(r.s.d.groups) {
+		groups = append(groups, models.GroupSummary{
+			Group:     r.s.d.groups[id],
+			WordCount: len(r.wordIDs(id)),
+		})
+	}
+	if err := sortBy(groups, opts, groupSortKeys); err != nil {
+		return nil, 0, err
+	}
+
+	start, end := page(len(groups), opts.Offset, opts.Limit)
+	return groups[start:end], len(groups), nil
+}
+
+func (r *groupRepo) Get(ctx context.Context, id int64) (*models.Group, error) {
+	group, ok := r.s.d.groups[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return &group, nil
+}
+
+func (r *groupRepo) Stats(ctx context.Context, id int64) (*models.GroupStats, error) {
+	return &models.GroupStats{TotalWordCount: len(r.wordIDs(id))}, nil
+}
+
+var groupWordSortKeys = sortKeys[models.GroupWord]{
+	"id":               func(w models.GroupWord) any { return w.ID },
+	"japanese":         func(w models.GroupWord) any { return w.Japanese },
+	"romaji":           func(w models.GroupWord) any { return w.Romaji },
+	"english":          func(w models.GroupWord) any { return w.English },
+	"correct_count":    func(w models.GroupWord) any { return w.CorrectCount },
+	"wrong_count":      func(w models.GroupWord) any { return w.WrongCount },
+	"last_reviewed_at": func(w models.GroupWord) any { return lastReviewed(w.WordStats) },
+}
+
+func (r *groupRepo) Words(ctx context.Context, userID, id int64, opts repository.ListOptions) ([]models.GroupWord, int, error) {
+	words := []models.GroupWord{}
+	for _, wordID := range r.wordIDs(id) {
+		stats, _ := r.s.Words().Stats(ctx, userID, wordID)
+		word := r.s.d.words[wordID]
+		words = append(words, models.GroupWord{Word: word, WordStats: *stats})
+	}
+	if err := sortBy(words, opts, groupWordSortKeys); err != nil {
+		return nil, 0, err
+	}
+
+	start, end := page(len(words), opts.Offset, opts.Limit)
+	return words[start:end], len(words), nil
+}
+
+func (r *groupRepo) nameTaken(name string, except int64) bool {
+	for id, group := range r.s.d.groups {
+		if id != except && group.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *groupRepo) Create(ctx context.Context, group *models.Group) error {
+	if r.nameTaken(group.Name, 0) {
+		return models.ErrDuplicateGroupName
+	}
+	group.ID = r.s.nextID()
+	r.s.d.groups[group.ID] = *group
+	return nil
+}
+
+func (r *groupRepo) Rename(ctx context.Context, id int64, name string) error {
+	group, ok := r.s.d.groups[id]
+	if !ok {
+		return models.ErrNotFound
+	}
+	if r.nameTaken(name, id) {
+		return models.ErrDuplicateGroupName
+	}
+	group.Name = name
+	r.s.d.groups[id] = group
+	return nil
+}
+
+func (r *groupRepo) Delete(ctx context.Context, id int64) error {
+	if _, ok := r.s.d.groups[id]; !ok {
+		return models.ErrNotFound
+	}
+
+	for m := range r.s.d.memberships {
+		if m.groupID == id {
+			delete(r.s.d.memberships, m)
+		}
+	}
+	for sessionID, session := range r.s.d.sessions {
+		if session.GroupID != id {
+			continue
+		}
+		r.s.d.reviews = filterReviews(r.s.d.reviews, func(review models.WordReview) bool {
+			return review.StudySessionID != sessionID
+		})
+		delete(r.s.d.sessions, sessionID)
+	}
+	delete(r.s.d.groups, id)
+	return nil
+}
+
+func (r *groupRepo) DeleteAll(ctx context.Context) error {
+	r.s.d.memberships = map[membership]bool{}
+	r.s.d.groups = map[int64]models.Group{}
+	return nil
+}
+
+func (r *groupRepo) AddWords(ctx context.Context, id int64, wordIDs []int64) (int, error) {
+	added := 0
+	for _, wordID := range wordIDs {
+		m := membership{wordID, id}
+		if !r.s.d.memberships[m] {
+			r.s.d.memberships[m] = true
+			added++
+		}
+	}
+	return added, nil
+}
+
+func (r *groupRepo) RemoveWords(ctx context.Context, id int64, wordIDs []int64) (int, error) {
+	removed := 0
+	for _, wordID := range wordIDs {
+		m := membership{wordID, id}
+		if r.s.d.memberships[m] {
+			delete(r.s.d.memberships, m)
+			removed++
+		}
+	}
+	return removed, nil
+}
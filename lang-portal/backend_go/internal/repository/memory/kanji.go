@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"context"
+	"strings"
+
+	"lang-portal/backend_go/internal/models"
+)
+
+type kanjiRepo struct {
+	s *Store
+}
+
+func (r *kanjiRepo) Get(ctx context.Context, character string) (*models.Kanji, error) {
+	for _, kanji := range r.s.d.kanji {
+		if kanji.Character == character {
+			return &kanji, nil
+		}
+	}
+	return nil, models.ErrNotFound
+}
+
+func (r *kanjiRepo) Create(ctx context.Context, kanji *models.Kanji) error {
+	kanji.ID = r.s.nextID()
+	r.s.d.kanji[kanji.ID] = *kanji
+	return nil
+}
+
+// Words finds the words by their text, where the SQL store keeps links.
+func (r *kanjiRepo) Words(ctx context.Context, userID, id int64) ([]models.WordSummary, error) {
+	words := []models.WordSummary{}
+	kanji, ok := r.s.d.kanji[id]
+	if !ok {
+		return words, nil
+	}
+
+	for _, wordID := range sortedIDs(r.s.d.words) {
+		word := r.s.d.words[wordID]
+		if !strings.Contains(word.Japanese, kanji.Character) {
+			continue
+		}
+		stats, _ := r.s.Words().Stats(ctx, userID, wordID)
+		words = append(words, models.WordSummary{Word: word, WordStats: *stats})
+	}
+	return words, nil
+}
+
+func (r *kanjiRepo) DeleteAll(ctx context.Context) error {
+	r.s.d.kanji = map[int64]models.Kanji{}
+	return nil
+}
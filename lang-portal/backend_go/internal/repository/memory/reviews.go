@@ -0,0 +1,170 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+)
+
+type reviewRepo struct {
+	s *Store
+}
+
+func filterReviews(reviews []models.WordReview, keep func(models.WordReview) bool) []models.WordReview {
+	kept := []models.WordReview{}
+	for _, review := range reviews {
+		if keep(review) {
+			kept = append(kept, review)
+		}
+	}
+	return kept
+}
+
+func (r *reviewRepo) Create(ctx context.Context, review *models.WordReview) error {
+	review.ID = r.s.nextID()
+	review.CreatedAt = r.s.now()
+	r.s.d.reviews = append(r.s.d.reviews, *review)
+	return nil
+}
+
+// inTimeOrder returns the reviews kept by keep, oldest first.
+func (r *reviewRepo) inTimeOrder(keep func(models.WordReview) bool) []models.WordReview {
+	reviews := filterReviews(r.s.d.reviews, keep)
+	sort.SliceStable(reviews, func(i, j int) bool {
+		if !reviews[i].CreatedAt.Equal(reviews[j].CreatedAt) {
+			return reviews[i].CreatedAt.Before(reviews[j].CreatedAt)
+		}
+		return reviews[i].ID < reviews[j].ID
+	})
+	return reviews
+}
+
+func (r *reviewRepo) ListBySession(ctx context.Context, sessionID int64, keyset repository.Keyset) ([]models.SessionWord, error) {
+	words := []models.SessionWord{}
+	reviews := r.inTimeOrder(func(review models.WordReview) bool {
+		return review.StudySessionID == sessionID && follows(review.CreatedAt, review.ID, keyset.After, false)
+	})
+	for _, review := range reviews {
+		if len(words) == keyset.Limit {
+			break
+		}
+		word, ok := r.s.d.words[review.WordID]
+		if !ok {
+			continue
+		}
+		words = append(words, models.SessionWord{
+			Word:        word,
+			ReviewID:    review.ID,
+			Correct:     review.Correct,
+			AnswerGiven: review.AnswerGiven,
+			ResponseMS:  review.ResponseMS,
+			Quality:     review.Quality,
+			Direction:   review.Direction,
+			HintsUsed:   review.HintsUsed,
+			ReviewedAt:  review.CreatedAt,
+		})
+	}
+	return words, nil
+}
+
+func (r *reviewRepo) ListByWord(ctx context.Context, userID, wordID int64, keyset repository.Keyset) ([]models.WordReview, error) {
+	reviews := r.inTimeOrder(func(review models.WordReview) bool {
+		return review.UserID == userID && review.WordID == wordID && follows(review.CreatedAt, review.ID, keyset.After, true)
+	})
+	slices.Reverse(reviews)
+	if len(reviews) > keyset.Limit {
+		reviews = reviews[:keyset.Limit]
+	}
+	return reviews, nil
+}
+
+func (r *reviewRepo) Totals(ctx context.Context, userID int64) (int, int, error) {
+	correct, total := 0, 0
+	for _, review := range r.s.d.reviews {
+		if review.UserID != userID {
+			continue
+		}
+		total++
+		if review.Correct {
+			correct++
+		}
+	}
+	return correct, total, nil
+}
+
+func (r *reviewRepo) CountStudiedWords(ctx context.Context, userID int64) (int, error) {
+	words := map[int64]bool{}
+	for _, review := range r.s.d.reviews {
+		if review.UserID == userID {
+			words[review.WordID] = true
+		}
+	}
+	return len(words), nil
+}
+
+func (r *reviewRepo) GetSchedule(ctx context.Context, userID, wordID int64) (*models.WordSchedule, error) {
+	schedule, ok := r.s.d.schedules[scheduleKey{userID, wordID}]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return &schedule, nil
+}
+
+func (r *reviewRepo) SaveSchedule(ctx context.Context, schedule *models.WordSchedule) error {
+	r.s.d.schedules[scheduleKey{schedule.UserID, schedule.WordID}] = *schedule
+	return nil
+}
+
+func (r *reviewRepo) DueWords(ctx context.Context, userID, groupID int64, now time.Time, limit int) ([]models.DueWord, error) {
+	var due, unseen []models.DueWord
+	for _, id := range sortedIDs(r.s.d.words) {
+		if groupID != 0 && !r.s.d.memberships[membership{id, groupID}] {
+			continue
+		}
+
+		word := models.DueWord{Word: r.s.d.words[id]}
+		schedule, ok := r.s.d.schedules[scheduleKey{userID, id}]
+		switch {
+		case !ok:
+			unseen = append(unseen, word)
+		case !schedule.DueAt.After(now):
+			word.Schedule = &schedule
+			due = append(due, word)
+		}
+	}
+
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].Schedule.DueAt.Before(due[j].Schedule.DueAt)
+	})
+
+	words := append(due, unseen...)
+	if len(words) > limit {
+		words = words[:limit]
+	}
+	if words == nil {
+		words = []models.DueWord{}
+	}
+	return words, nil
+}
+
+func (r *reviewRepo) DeleteByUser(ctx context.Context, userID int64) error {
+	r.s.d.reviews = filterReviews(r.s.d.reviews, func(review models.WordReview) bool {
+		return review.UserID != userID
+	})
+	for key := range r.s.d.schedules {
+		if key.userID == userID {
+			delete(r.s.d.schedules, key)
+		}
+	}
+	return nil
+}
+
+func (r *reviewRepo) DeleteAll(ctx context.Context) error {
+	r.s.d.reviews = nil
+	r.s.d.schedules = map[scheduleKey]models.WordSchedule{}
+	return nil
+}
@@ -0,0 +1,259 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+)
+
+type sessionRepo struct {
+	s *Store
+}
+
+// detail joins a session with its activity and group. It reports false for
+// sessions whose group or activity no longer exists, like the SQL inner join.
+func (r *sessionRepo) detail(session models.StudySession) (models.StudySessionDetail, bool) {
+	activity, ok := r.s.d.activities[session.StudyActivityID]
+	if !ok {
+		return models.StudySessionDetail{}, false
+	}
+	group, ok := r.s.d.groups[session.GroupID]
+	if !ok {
+		return models.StudySessionDetail{}, false
+	}
+
+	detail := models.StudySessionDetail{
+		ID:           session.ID,
+		UserID:       session.UserID,
+		ActivityName: activity.Name,
+		GroupName:    group.Name,
+		Status:       session.Status,
+		StartTime:    session.CreatedAt.Format(time.RFC3339),
+	}
+	if session.EndedAt != nil {
+		end := session.EndedAt.Format(time.RFC3339)
+		detail.EndTime = &end
+	}
+	for _, review := range r.s.d.reviews {
+		if review.StudySessionID == session.ID {
+			detail.ReviewItemCount++
+		}
+	}
+	return detail, true
+}
+
+// newestFirst returns the sessions ordered by start time, newest first.
+func (r *sessionRepo) newestFirst() []models.StudySession {
+	sessions := make([]models.StudySession, 0, len(r.s.d.sessions))
+	for _, session := range r.s.d.sessions {
+		sessions = append(sessions, session)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		if !sessions[i].CreatedAt.Equal(sessions[j].CreatedAt) {
+			return sessions[i].CreatedAt.After(sessions[j].CreatedAt)
+		}
+		return sessions[i].ID > sessions[j].ID
+	})
+	return sessions
+}
+
+var sessionSortKeys = sortKeys[models.StudySessionDetail]{
+	"id":                 func(s models.StudySessionDetail) any { return s.ID },
+	"start_time":         func(s models.StudySessionDetail) any { return s.StartTime },
+	"activity_name":      func(s models.StudySessionDetail) any { return s.ActivityName },
+	"group_name":         func(s models.StudySessionDetail) any { return s.GroupName },
+	"review_items_count": func(s models.StudySessionDetail) any { return s.ReviewItemCount },
+}
+
+func inFilter(session models.StudySession, filter repository.SessionFilter) bool {
+	switch {
+	case filter.UserID != 0 && session.UserID != filter.UserID,
+		filter.GroupID != 0 && session.GroupID != filter.GroupID,
+		filter.ActivityID != 0 && session.StudyActivityID != filter.ActivityID,
+		!filter.From.IsZero() && session.CreatedAt.Before(filter.From),
+		!filter.To.IsZero() && !session.CreatedAt.Before(filter.To):
+		return false
+	default:
+		return true
+	}
+}
+
+func (r *sessionRepo) List(ctx context.Context, filter repository.SessionFilter, opts repository.ListOptions) ([]models.StudySessionDetail, int, error) {
+	total := 0
+	details := []models.StudySessionDetail{}
+	for _, session := range r.newestFirst() {
+		if !inFilter(session, filter) {
+			continue
+		}
+		total++
+
+		if detail, ok := r.detail(session); ok {
+			details = append(details, detail)
+		}
+	}
+
+	if err := sortBy(details, opts, sessionSortKeys); err != nil {
+		return nil, 0, err
+	}
+
+	start, end := page(len(details), opts.Offset, opts.Limit)
+	return details[start:end], total, nil
+}
+
+func (r *sessionRepo) ListAfter(ctx context.Context, filter repository.SessionFilter, keyset repository.Keyset) ([]models.StudySessionDetail, error) {
+	details := []models.StudySessionDetail{}
+	for _, session := range r.newestFirst() {
+		if len(details) == keyset.Limit {
+			break
+		}
+		if !inFilter(session, filter) || !follows(session.CreatedAt, session.ID, keyset.After, true) {
+			continue
+		}
+		if detail, ok := r.detail(session); ok {
+			details = append(details, detail)
+		}
+	}
+	return details, nil
+}
+
+func (r *sessionRepo) Get(ctx context.Context, id int64) (*models.StudySessionDetail, error) {
+	session, ok := r.s.d.sessions[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	detail, ok := r.detail(session)
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return &detail, nil
+}
+
+func (r *sessionRepo) Status(ctx context.Context, id int64) (string, error) {
+	session, ok := r.s.d.sessions[id]
+	if !ok {
+		return "", models.ErrNotFound
+	}
+	return session.Status, nil
+}
+
+// byUser returns the user's sessions, newest first.
+func (r *sessionRepo) byUser(userID int64) []models.StudySession {
+	sessions := []models.StudySession{}
+	for _, session := range r.newestFirst() {
+		if session.UserID == userID {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions
+}
+
+func (r *sessionRepo) Last(ctx context.Context, userID int64) (*models.LastStudySession, error) {
+	for _, session := range r.byUser(userID) {
+		group, ok := r.s.d.groups[session.GroupID]
+		if !ok {
+			continue
+		}
+		return &models.LastStudySession{
+			ID:              session.ID,
+			GroupID:         session.GroupID,
+			GroupName:       group.Name,
+			StudyActivityID: session.StudyActivityID,
+			CreatedAt:       session.CreatedAt.Format(time.RFC3339),
+		}, nil
+	}
+	return nil, models.ErrNotFound
+}
+
+func (r *sessionRepo) Count(ctx context.Context, userID int64) (int, error) {
+	return len(r.byUser(userID)), nil
+}
+
+func (r *sessionRepo) CountActiveGroups(ctx context.Context, userID int64) (int, error) {
+	groups := map[int64]bool{}
+	for _, session := range r.byUser(userID) {
+		groups[session.GroupID] = true
+	}
+	return len(groups), nil
+}
+
+func (r *sessionRepo) StudyDays(ctx context.Context, userID int64) ([]time.Time, error) {
+	seen := map[time.Time]bool{}
+	days := []time.Time{}
+	for _, session := range r.byUser(userID) {
+		t := session.CreatedAt.UTC()
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		if !seen[day] {
+			seen[day] = true
+			days = append(days, day)
+		}
+	}
+	return days, nil
+}
+
+func (r *sessionRepo) Create(ctx context.Context, session *models.StudySession) error {
+	if session.Status == "" {
+		session.Status = models.SessionActive
+	}
+	session.ID = r.s.nextID()
+	session.CreatedAt = r.s.now()
+	r.s.d.sessions[session.ID] = *session
+	return nil
+}
+
+func (r *sessionRepo) End(ctx context.Context, id int64, at time.Time) error {
+	session, ok := r.s.d.sessions[id]
+	if !ok {
+		return models.ErrNotFound
+	}
+	if session.Status != models.SessionActive {
+		return models.ErrSessionClosed
+	}
+
+	ended := at.UTC().Truncate(time.Second)
+	session.Status = models.SessionCompleted
+	session.EndedAt = &ended
+	r.s.d.sessions[id] = session
+	return nil
+}
+
+func (r *sessionRepo) CloseIdle(ctx context.Context, cutoff time.Time) (int64, error) {
+	var closed int64
+	for id, session := range r.s.d.sessions {
+		if session.Status != models.SessionActive {
+			continue
+		}
+
+		last := session.CreatedAt
+		for _, review := range r.s.d.reviews {
+			if review.StudySessionID == id && review.CreatedAt.After(last) {
+				last = review.CreatedAt
+			}
+		}
+		if !last.Before(cutoff) {
+			continue
+		}
+
+		session.Status = models.SessionAbandoned
+		session.EndedAt = &last
+		r.s.d.sessions[id] = session
+		closed++
+	}
+	return closed, nil
+}
+
+func (r *sessionRepo) DeleteByUser(ctx context.Context, userID int64) error {
+	for id, session := range r.s.d.sessions {
+		if session.UserID == userID {
+			delete(r.s.d.sessions, id)
+		}
+	}
+	return nil
+}
+
+func (r *sessionRepo) DeleteAll(ctx context.Context) error {
+	r.s.d.sessions = map[int64]models.StudySession{}
+	return nil
+}
@@ -0,0 +1,239 @@
+// Package memory is an in-memory implementation of the repository interfaces
+// for unit tests. It is not safe for concurrent use.
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+)
+
+type membership struct {
+	wordID  int64
+	groupID int64
+}
+
+type scheduleKey struct {
+	userID int64
+	wordID int64
+}
+
+type data struct {
+	words       map[int64]models.Word
+	groups      map[int64]models.Group
+	memberships map[membership]bool
+	kanji       map[int64]models.Kanji
+	sessions    map[int64]models.StudySession
+	reviews     []models.WordReview
+	schedules   map[scheduleKey]models.WordSchedule
+	activities  map[int64]models.StudyActivity
+	users       map[int64]models.User
+	tokens      map[string]models.AuthToken
+	statements  []models.StoredStatement
+	states      map[repository.StateKey]models.XAPIState
+	lastID      int64
+}
+
+func (d *data) clone() *data {
+	c := &data{
+		words:       make(map[int64]models.Word, len(d.words)),
+		groups:      make(map[int64]models.Group, len(d.groups)),
+		memberships: make(map[membership]bool, len(d.memberships)),
+		kanji:       make(map[int64]models.Kanji, len(d.kanji)),
+		sessions:    make(map[int64]models.StudySession, len(d.sessions)),
+		reviews:     append([]models.WordReview(nil), d.reviews...),
+		schedules:   make(map[scheduleKey]models.WordSchedule, len(d.schedules)),
+		activities:  make(map[int64]models.StudyActivity, len(d.activities)),
+		users:       make(map[int64]models.User, len(d.users)),
+		tokens:      make(map[string]models.AuthToken, len(d.tokens)),
+		statements:  append([]models.StoredStatement(nil), d.statements...),
+		states:      make(map[repository.StateKey]models.XAPIState, len(d.states)),
+		lastID:      d.lastID,
+	}
+	for k, v := range d.words {
+		c.words[k] = v
+	}
+	for k, v := range d.groups {
+		c.groups[k] = v
+	}
+	for k, v := range d.memberships {
+		c.memberships[k] = v
+	}
+	for k, v := range d.kanji {
+		c.kanji[k] = v
+	}
+	for k, v := range d.sessions {
+		c.sessions[k] = v
+	}
+	for k, v := range d.schedules {
+		c.schedules[k] = v
+	}
+	for k, v := range d.activities {
+		c.activities[k] = v
+	}
+	for k, v := range d.users {
+		c.users[k] = v
+	}
+	for k, v := range d.tokens {
+		c.tokens[k] = v
+	}
+	for k, v := range d.states {
+		c.states[k] = v
+	}
+	return c
+}
+
+// Store is a repository.Store that keeps everything in maps.
+type Store struct {
+	d    *data
+	inTx bool
+
+	// Now stamps created records. It defaults to time.Now.
+	Now func() time.Time
+}
+
+// New returns an empty store holding only the default user, like a freshly
+// migrated database.
+func New() *Store {
+	s := &Store{
+		d: &data{
+			words:       map[int64]models.Word{},
+			groups:      map[int64]models.Group{},
+			memberships: map[membership]bool{},
+			kanji:       map[int64]models.Kanji{},
+			sessions:    map[int64]models.StudySession{},
+			schedules:   map[scheduleKey]models.WordSchedule{},
+			activities:  map[int64]models.StudyActivity{},
+			users:       map[int64]models.User{},
+			tokens:      map[string]models.AuthToken{},
+			states:      map[repository.StateKey]models.XAPIState{},
+		},
+		Now: time.Now,
+	}
+	s.d.users[models.DefaultUserID] = models.User{
+		ID:        models.DefaultUserID,
+		Name:      "default",
+		Role:      models.RoleAdmin,
+		CreatedAt: s.now(),
+	}
+	s.d.lastID = models.DefaultUserID
+	return s
+}
+
+func (s *Store) Words() repository.WordRepo          { return &wordRepo{s} }
+func (s *Store) Groups() repository.GroupRepo        { return &groupRepo{s} }
+func (s *Store) Kanji() repository.KanjiRepo         { return &kanjiRepo{s} }
+func (s *Store) Sessions() repository.SessionRepo    { return &sessionRepo{s} }
+func (s *Store) Reviews() repository.ReviewRepo      { return &reviewRepo{s} }
+func (s *Store) Activities() repository.ActivityRepo { return &activityRepo{s} }
+func (s *Store) Users() repository.UserRepo          { return &userRepo{s} }
+func (s *Store) Tokens() repository.TokenRepo        { return &tokenRepo{s} }
+func (s *Store) XAPI() repository.XAPIRepo           { return &xapiRepo{s} }
+
+// WithTx snapshots the data and restores the snapshot if fn fails.
+func (s *Store) WithTx(ctx context.Context, fn func(tx repository.Store) error) error {
+	if s.inTx {
+		return fn(s)
+	}
+
+	snapshot := s.d.clone()
+	s.inTx = true
+	err := fn(s)
+	s.inTx = false
+	if err != nil {
+		s.d = snapshot
+	}
+	return err
+}
+
+func (s *Store) nextID() int64 {
+	s.d.lastID++
+	return s.d.lastID
+}
+
+// now truncates to the second like SQLite's CURRENT_TIMESTAMP.
+func (s *Store) now() time.Time {
+	return s.Now().UTC().Truncate(time.Second)
+}
+
+// page returns the [offset, offset+limit) window of n items.
+func page(n, offset, limit int) (int, int) {
+	if offset > n {
+		offset = n
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	end := offset + limit
+	if end > n || limit < 0 {
+		end = n
+	}
+	return offset, end
+}
+
+// follows reports whether the item at time at with the given id comes
+// after cursor in a listing ordered by time, then by ID, newest first when
+// desc.
+func follows(at time.Time, id int64, cursor repository.Cursor, desc bool) bool {
+	if cursor.IsZero() {
+		return true
+	}
+	if !at.Equal(cursor.Time) {
+		return at.Before(cursor.Time) == desc
+	}
+	return id != cursor.ID && (id < cursor.ID) == desc
+}
+
+func sortedIDs[V any](m map[int64]V) []int64 {
+	ids := make([]int64, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// sortKeys maps the sort fields of a listing, including "id", to the value
+// an item is ordered by: an int, an int64 or a string.
+type sortKeys[T any] map[string]func(T) any
+
+// sortBy orders items by opts.Sort like the SQL store, ties broken by ID in
+// the same direction. Without a sort the items keep their order.
+func sortBy[T any](items []T, opts repository.ListOptions, keys sortKeys[T]) error {
+	if opts.Sort == "" {
+		return nil
+	}
+	key, ok := keys[opts.Sort]
+	if !ok {
+		return fmt.Errorf("cannot sort by %q", opts.Sort)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		c := compare(key(items[i]), key(items[j]))
+		if c == 0 {
+			c = compare(keys["id"](items[i]), keys["id"](items[j]))
+		}
+		if opts.Desc {
+			return c > 0
+		}
+		return c < 0
+	})
+	return nil
+}
+
+func compare(a, b any) int {
+	switch a := a.(type) {
+	case int:
+		return cmp.Compare(a, b.(int))
+	case int64:
+		return cmp.Compare(a, b.(int64))
+	default:
+		return strings.Compare(strings.ToLower(a.(string)), strings.ToLower(b.(string)))
+	}
+}
@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"lang-portal/backend_go/internal/models"
+)
+
+type tokenRepo struct {
+	s *Store
+}
+
+func (r *tokenRepo) Create(ctx context.Context, token *models.AuthToken) error {
+	r.s.d.tokens[token.Hash] = *token
+	return nil
+}
+
+func (r *tokenRepo) Get(ctx context.Context, hash string) (*models.AuthToken, error) {
+	token, ok := r.s.d.tokens[hash]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return &token, nil
+}
+
+func (r *tokenRepo) Delete(ctx context.Context, hash string) error {
+	delete(r.s.d.tokens, hash)
+	return nil
+}
+
+func (r *tokenRepo) DeleteUser(ctx context.Context, userID int64) error {
+	for hash, token := range r.s.d.tokens {
+		if token.UserID == userID {
+			delete(r.s.d.tokens, hash)
+		}
+	}
+	return nil
+}
+
+func (r *tokenRepo) DeleteExpired(ctx context.Context, now time.Time) error {
+	for hash, token := range r.s.d.tokens {
+		if !token.ExpiresAt.After(now) {
+			delete(r.s.d.tokens, hash)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"context"
+
+	"lang-portal/backend_go/internal/models"
+)
+
+type userRepo struct {
+	s *Store
+}
+
+func (r *userRepo) List(ctx context.Context) ([]models.User, error) {
+	users := []models.User{}
+	for _, id := range sortedIDs(r.s.d.users) {
+		users = append(users, r.s.d.users[id])
+	}
+	return users, nil
+}
+
+func (r *userRepo) Get(ctx context.Context, id int64) (*models.User, error) {
+	user, ok := r.s.d.users[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return &user, nil
+}
+
+func (r *userRepo) FindByName(ctx context.Context, name string) (*models.User, error) {
+	for _, user := range r.s.d.users {
+		if user.Name == name {
+			return &user, nil
+		}
+	}
+	return nil, models.ErrNotFound
+}
+
+func (r *userRepo) Create(ctx context.Context, user *models.User) error {
+	if _, err := r.FindByName(ctx, user.Name); err == nil {
+		return models.ErrDuplicateUserName
+	}
+
+	if user.Role == "" {
+		user.Role = models.RoleStudent
+	}
+	user.ID = r.s.nextID()
+	user.CreatedAt = r.s.now()
+	r.s.d.users[user.ID] = *user
+	return nil
+}
+
+func (r *userRepo) SetPassword(ctx context.Context, id int64, hash string) error {
+	user, ok := r.s.d.users[id]
+	if !ok {
+		return models.ErrNotFound
+	}
+	user.PasswordHash = hash
+	r.s.d.users[id] = user
+	return nil
+}
+
+func (r *userRepo) SetRole(ctx context.Context, id int64, role string) error {
+	user, ok := r.s.d.users[id]
+	if !ok {
+		return models.ErrNotFound
+	}
+	user.Role = role
+	r.s.d.users[id] = user
+	return nil
+}
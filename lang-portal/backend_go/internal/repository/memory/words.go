@@ -0,0 +1,227 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+	"lang-portal/backend_go/internal/textnorm"
+)
+
+type wordRepo struct {
+	s *Store
+}
+
+// wordSortKeys orders words by repository.WordSorts.
+var wordSortKeys = sortKeys[models.WordSummary]{
+	"id":               func(w models.WordSummary) any { return w.ID },
+	"japanese":         func(w models.WordSummary) any { return w.Japanese },
+	"romaji":           func(w models.WordSummary) any { return w.Romaji },
+	"english":          func(w models.WordSummary) any { return w.English },
+	"correct_count":    func(w models.WordSummary) any { return w.CorrectCount },
+	"wrong_count":      func(w models.WordSummary) any { return w.WrongCount },
+	"last_reviewed_at": func(w models.WordSummary) any { return lastReviewed(w.WordStats) },
+}
+
+// lastReviewed orders words by when they were last reviewed, the ones never
+// reviewed first.
+func lastReviewed(stats models.WordStats) any {
+	if stats.LastReviewedAt == nil {
+		return ""
+	}
+	return stats.LastReviewedAt.UTC().Format(time.RFC3339)
+}
+
+func (r *wordRepo) List(ctx context.Context, userID int64, query string, opts repository.ListOptions) ([]models.WordSummary, int, error) {
+	terms := textnorm.Terms(query)
+	if query != "" && len(terms) == 0 {
+		return []models.WordSummary{}, 0, nil
+	}
+
+	whole := textnorm.NewTerm(query)
+	matches := []models.WordSummary{}
+	ranks := map[int64]int{}
+	for _, id := range sortedIDs(r.s.d.words) {
+		word := r.s.d.words[id]
+		keys := searchKeys(word)
+		if !matchesAll(terms, keys) {
+			continue
+		}
+
+		stats, _ := r.Stats(ctx, userID, id)
+		matches = append(matches, models.WordSummary{Word: word, WordStats: *stats})
+
+		ranks[id] = 2
+		for _, field := range textnorm.Fields {
+			ranks[id] = min(ranks[id], whole.Rank(field, keys[field]))
+		}
+	}
+
+	// Best matches first, like the SQL store
+	sort.SliceStable(matches, func(i, j int) bool {
+		return ranks[matches[i].ID] < ranks[matches[j].ID]
+	})
+	if err := sortBy(matches, opts, wordSortKeys); err != nil {
+		return nil, 0, err
+	}
+
+	start, end := page(len(matches), opts.Offset, opts.Limit)
+	return matches[start:end], len(matches), nil
+}
+
+// searchKeys returns the search form of each of the word's fields.
+func searchKeys(word models.Word) map[textnorm.Field]string {
+	return map[textnorm.Field]string{
+		textnorm.Japanese: textnorm.Japanese.Key(word.Japanese),
+		textnorm.Romaji:   textnorm.Romaji.Key(word.Romaji),
+		textnorm.English:  textnorm.English.Key(word.English),
+	}
+}
+
+// matchesAll reports whether every term matches one of the fields.
+func matchesAll(terms []textnorm.Term, keys map[textnorm.Field]string) bool {
+	for _, term := range terms {
+		matched := false
+		for _, field := range textnorm.Fields {
+			matched = matched || term.Matches(field, keys[field])
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *wordRepo) Get(ctx context.Context, id int64) (*models.Word, error) {
+	word, ok := r.s.d.words[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return &word, nil
+}
+
+func (r *wordRepo) FindByText(ctx context.Context, japanese, english string) (*models.Word, error) {
+	for _, id := range sortedIDs(r.s.d.words) {
+		word := r.s.d.words[id]
+		if word.Japanese == japanese && strings.EqualFold(word.English, english) {
+			return &word, nil
+		}
+	}
+	return nil, models.ErrNotFound
+}
+
+func (r *wordRepo) MissingIDs(ctx context.Context, ids []int64) ([]int64, error) {
+	missing := []int64{}
+	for _, id := range ids {
+		if _, ok := r.s.d.words[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	return missing, nil
+}
+
+func (r *wordRepo) Count(ctx context.Context) (int, error) {
+	return len(r.s.d.words), nil
+}
+
+func (r *wordRepo) Stats(ctx context.Context, userID, id int64) (*models.WordStats, error) {
+	if _, ok := r.s.d.words[id]; !ok {
+		return nil, models.ErrNotFound
+	}
+
+	stats := models.WordStats{UserID: userID, WordID: id}
+	for _, review := range r.s.d.reviews {
+		if review.UserID != userID || review.WordID != id {
+			continue
+		}
+		if review.Correct {
+			stats.CorrectCount++
+		} else {
+			stats.WrongCount++
+		}
+		if stats.LastReviewedAt == nil || review.CreatedAt.After(*stats.LastReviewedAt) {
+			at := review.CreatedAt
+			stats.LastReviewedAt = &at
+		}
+	}
+
+	schedule := r.s.d.schedules[scheduleKey{userID, id}]
+	stats.Mastery = models.Mastery(stats.CorrectCount+stats.WrongCount, schedule.IntervalDays)
+	return &stats, nil
+}
+
+func (r *wordRepo) Groups(ctx context.Context, id int64) ([]models.Group, error) {
+	groups := []models.Group{}
+	for _, groupID := range sortedIDs(r.s.d.groups) {
+		if r.s.d.memberships[membership{id, groupID}] {
+			groups = append(groups, r.s.d.groups[groupID])
+		}
+	}
+	return groups, nil
+}
+
+func (r *wordRepo) Export(ctx context.Context, userID, groupID int64, withStats bool) ([]models.ExportWord, error) {
+	words := []models.ExportWord{}
+	for _, id := range sortedIDs(r.s.d.words) {
+		if groupID != 0 && !r.s.d.memberships[membership{id, groupID}] {
+			continue
+		}
+
+		word := models.ExportWord{Word: r.s.d.words[id], Groups: []string{}}
+		groups, _ := r.Groups(ctx, id)
+		for _, group := range groups {
+			word.Groups = append(word.Groups, group.Name)
+		}
+		sort.Strings(word.Groups)
+
+		if withStats {
+			word.Stats, _ = r.Stats(ctx, userID, id)
+		}
+		words = append(words, word)
+	}
+	return words, nil
+}
+
+func (r *wordRepo) Create(ctx context.Context, word *models.Word) error {
+	word.ID = r.s.nextID()
+	r.s.d.words[word.ID] = *word
+	return nil
+}
+
+func (r *wordRepo) Update(ctx context.Context, word *models.Word) error {
+	if _, ok := r.s.d.words[word.ID]; !ok {
+		return models.ErrNotFound
+	}
+	r.s.d.words[word.ID] = *word
+	return nil
+}
+
+func (r *wordRepo) Delete(ctx context.Context, id int64) error {
+	if _, ok := r.s.d.words[id]; !ok {
+		return models.ErrNotFound
+	}
+
+	for m := range r.s.d.memberships {
+		if m.wordID == id {
+			delete(r.s.d.memberships, m)
+		}
+	}
+	r.s.d.reviews = filterReviews(r.s.d.reviews, func(review models.WordReview) bool {
+		return review.WordID != id
+	})
+	for key := range r.s.d.schedules {
+		if key.wordID == id {
+			delete(r.s.d.schedules, key)
+		}
+	}
+	delete(r.s.d.words, id)
+	return nil
+}
+
+func (r *wordRepo) DeleteAll(ctx context.Context) error {
+	r.s.d.words = map[int64]models.Word{}
+	return nil
+}
@@ -0,0 +1,145 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+)
+
+type xapiRepo struct {
+	s *Store
+}
+
+func (r *xapiRepo) CreateStatement(ctx context.Context, statement *models.StoredStatement) error {
+	for _, stored := range r.s.d.statements {
+		if stored.StatementID == statement.StatementID {
+			return models.ErrDuplicateStatement
+		}
+	}
+
+	statement.ID = r.s.nextID()
+	r.s.d.statements = append(r.s.d.statements, *statement)
+	return nil
+}
+
+func (r *xapiRepo) GetStatement(ctx context.Context, userID int64, statementID string) (*models.StoredStatement, error) {
+	for _, statement := range r.s.d.statements {
+		if statement.UserID == userID && statement.StatementID == statementID {
+			return &statement, nil
+		}
+	}
+	return nil, models.ErrNotFound
+}
+
+func (r *xapiRepo) ListStatements(ctx context.Context, filter repository.StatementFilter, keyset repository.Keyset) ([]models.StoredStatement, error) {
+	matches := func(want, got string) bool { return want == "" || want == got }
+
+	statements := []models.StoredStatement{}
+	for _, statement := range r.s.d.statements {
+		if statement.UserID != filter.UserID || statement.Voided ||
+			!matches(filter.ActorKey, statement.ActorKey) ||
+			!matches(filter.VerbID, statement.VerbID) ||
+			!matches(filter.ObjectID, statement.ObjectID) ||
+			!matches(filter.Registration, statement.Registration) ||
+			!filter.Since.IsZero() && !statement.Stored.After(filter.Since) ||
+			!filter.Until.IsZero() && statement.Stored.After(filter.Until) ||
+			!follows(statement.Stored, statement.ID, keyset.After, !filter.Ascending) {
+			continue
+		}
+		statements = append(statements, statement)
+	}
+
+	sort.SliceStable(statements, func(i, j int) bool {
+		a, b := statements[i], statements[j]
+		if !a.Stored.Equal(b.Stored) {
+			return a.Stored.Before(b.Stored) == filter.Ascending
+		}
+		return (a.ID < b.ID) == filter.Ascending
+	})
+	if len(statements) > keyset.Limit {
+		statements = statements[:keyset.Limit]
+	}
+	return statements, nil
+}
+
+func (r *xapiRepo) VoidStatement(ctx context.Context, userID int64, statementID string) error {
+	for i, statement := range r.s.d.statements {
+		if statement.UserID == userID && statement.StatementID == statementID {
+			r.s.d.statements[i].Voided = true
+			return nil
+		}
+	}
+	return models.ErrNotFound
+}
+
+func (r *xapiRepo) GetState(ctx context.Context, key repository.StateKey) (*models.XAPIState, error) {
+	state, ok := r.s.d.states[key]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	state.Document = slices.Clone(state.Document)
+	return &state, nil
+}
+
+// sameDocuments reports whether key stands for the document of state.
+func sameDocuments(key repository.StateKey, state models.XAPIState) bool {
+	return state.UserID == key.UserID && state.ActivityID == key.ActivityID &&
+		state.AgentKey == key.AgentKey && state.Registration == key.Registration &&
+		(key.StateID == "" || state.StateID == key.StateID)
+}
+
+func (r *xapiRepo) StateIDs(ctx context.Context, key repository.StateKey, since time.Time) ([]string, error) {
+	key.StateID = ""
+	ids := []string{}
+	for _, state := range r.s.d.states {
+		if sameDocuments(key, state) && (since.IsZero() || state.UpdatedAt.After(since)) {
+			ids = append(ids, state.StateID)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (r *xapiRepo) SaveState(ctx context.Context, state *models.XAPIState) error {
+	saved := *state
+	saved.Document = slices.Clone(state.Document)
+	r.s.d.states[repository.StateKey{
+		UserID:       state.UserID,
+		ActivityID:   state.ActivityID,
+		AgentKey:     state.AgentKey,
+		Registration: state.Registration,
+		StateID:      state.StateID,
+	}] = saved
+	return nil
+}
+
+func (r *xapiRepo) DeleteState(ctx context.Context, key repository.StateKey) error {
+	for k, state := range r.s.d.states {
+		if sameDocuments(key, state) {
+			delete(r.s.d.states, k)
+		}
+	}
+	return nil
+}
+
+func (r *xapiRepo) DeleteByUser(ctx context.Context, userID int64) error {
+	r.s.d.statements = slices.DeleteFunc(r.s.d.statements, func(statement models.StoredStatement) bool {
+		return statement.UserID == userID
+	})
+	for key := range r.s.d.states {
+		if key.UserID == userID {
+			delete(r.s.d.states, key)
+		}
+	}
+	return nil
+}
+
+func (r *xapiRepo) DeleteAll(ctx context.Context) error {
+	r.s.d.statements = nil
+	r.s.d.states = map[repository.StateKey]models.XAPIState{}
+	return nil
+}
@@ -0,0 +1,272 @@
+// Package repository defines the storage interfaces the service layer is
+// built on. Implementations return models.ErrNotFound for missing records.
+package repository
+
+import (
+	"context"
+	"time"
+
+	"lang-portal/backend_go/internal/models"
+)
+
+// Store gives access to every repository. Repositories obtained from the
+// Store passed to WithTx's callback share a single transaction.
+type Store interface {
+	Words() WordRepo
+	Groups() GroupRepo
+	Kanji() KanjiRepo
+	Sessions() SessionRepo
+	Reviews() ReviewRepo
+	Activities() ActivityRepo
+	Users() UserRepo
+	Tokens() TokenRepo
+	XAPI() XAPIRepo
+
+	// WithTx runs fn in a transaction, committing if it returns nil and
+	// rolling back otherwise.
+	WithTx(ctx context.Context, fn func(tx Store) error) error
+}
+
+// ListOptions selects a page of a listing and its order.
+type ListOptions struct {
+	Offset int
+	Limit  int
+	// Sort is one of the listing's sort fields, such as WordSorts, or empty
+	// for the listing's default order. Ties are broken by ID, in the same
+	// direction.
+	Sort string
+	Desc bool
+}
+
+// Cursor is the position of an item in a listing ordered by time, then by
+// ID. The zero Cursor is the start of the listing.
+type Cursor struct {
+	Time time.Time
+	ID   int64
+}
+
+// IsZero reports whether c is the start of the listing.
+func (c Cursor) IsZero() bool {
+	return c.ID == 0
+}
+
+// Keyset selects the page of a listing that follows After. Unlike an offset
+// it stays cheap however deep the page is.
+type Keyset struct {
+	After Cursor
+	Limit int
+}
+
+// The fields each listing can be sorted by, named like the JSON fields of
+// the items. Text sorts ignore ASCII case, and missing values sort before
+// all others.
+var (
+	WordSorts    = []string{"id", "japanese", "romaji", "english", "correct_count", "wrong_count", "last_reviewed_at"}
+	GroupSorts   = []string{"id", "name", "word_count"}
+	SessionSorts = []string{"id", "start_time", "activity_name", "group_name", "review_items_count"}
+)
+
+type WordRepo interface {
+	// List returns a page of words matching query, with the user's review
+	// counts, together with the total number of matches. Query and words
+	// are compared in the folded forms of package textnorm. Without a sort
+	// matches are ordered as by textnorm.Term.Rank, then by ID, and an empty
+	// query lists all words by ID.
+	List(ctx context.Context, userID int64, query string, opts ListOptions) ([]models.WordSummary, int, error)
+	Get(ctx context.Context, id int64) (*models.Word, error)
+	// FindByText returns the oldest word with the given japanese text and
+	// case-insensitively equal english text.
+	FindByText(ctx context.Context, japanese, english string) (*models.Word, error)
+	// MissingIDs returns the IDs from ids that do not exist.
+	MissingIDs(ctx context.Context, ids []int64) ([]int64, error)
+	Count(ctx context.Context) (int, error)
+	// Stats returns the user's review statistics of a word.
+	Stats(ctx context.Context, userID, id int64) (*models.WordStats, error)
+	Groups(ctx context.Context, id int64) ([]models.Group, error)
+	// Export returns every word in a group, or every word if groupID is 0,
+	// ordered by ID. Stats are the user's.
+	Export(ctx context.Context, userID, groupID int64, withStats bool) ([]models.ExportWord, error)
+	Create(ctx context.Context, word *models.Word) error
+	Update(ctx context.Context, word *models.Word) error
+	// Delete removes a word with its group memberships, kanji links,
+	// reviews and schedule.
+	Delete(ctx context.Context, id int64) error
+	DeleteAll(ctx context.Context) error
+}
+
+type GroupRepo interface {
+	// List returns a page of groups, by ID unless sorted, with the total.
+	List(ctx context.Context, opts ListOptions) ([]models.GroupSummary, int, error)
+	Get(ctx context.Context, id int64) (*models.Group, error)
+	Stats(ctx context.Context, id int64) (*models.GroupStats, error)
+	// Words returns a page of the group's words with the user's review
+	// counts, by ID unless sorted by one of WordSorts.
+	Words(ctx context.Context, userID, id int64, opts ListOptions) ([]models.GroupWord, int, error)
+	// Create and Rename return models.ErrDuplicateGroupName if the name is
+	// taken.
+	Create(ctx context.Context, group *models.Group) error
+	Rename(ctx context.Context, id int64, name string) error
+	// Delete removes a group with its memberships and study sessions.
+	Delete(ctx context.Context, id int64) error
+	DeleteAll(ctx context.Context) error
+	// AddWords and RemoveWords return the number of memberships changed.
+	AddWords(ctx context.Context, id int64, wordIDs []int64) (int, error)
+	RemoveWords(ctx context.Context, id int64, wordIDs []int64) (int, error)
+}
+
+type KanjiRepo interface {
+	// Get returns the kanji written as character.
+	Get(ctx context.Context, character string) (*models.Kanji, error)
+	// Create stores a kanji and links it to the words written with it.
+	Create(ctx context.Context, kanji *models.Kanji) error
+	// Words returns the words written with a kanji, by ID, with the user's
+	// review counts.
+	Words(ctx context.Context, userID, id int64) ([]models.WordSummary, error)
+	DeleteAll(ctx context.Context) error
+}
+
+// SessionFilter restricts a session listing. Zero fields match everything.
+type SessionFilter struct {
+	UserID     int64
+	GroupID    int64
+	ActivityID int64
+	// From and To bound the start time of the sessions, To exclusive.
+	From time.Time
+	To   time.Time
+}
+
+type SessionRepo interface {
+	// List returns a page of sessions, newest first unless sorted, with the
+	// total number of matches.
+	List(ctx context.Context, filter SessionFilter, opts ListOptions) ([]models.StudySessionDetail, int, error)
+	// ListAfter returns the sessions after a cursor, newest first. Their
+	// cursors are their start times and IDs.
+	ListAfter(ctx context.Context, filter SessionFilter, keyset Keyset) ([]models.StudySessionDetail, error)
+	Get(ctx context.Context, id int64) (*models.StudySessionDetail, error)
+	Status(ctx context.Context, id int64) (string, error)
+	// Last, Count, CountActiveGroups and StudyDays only look at the user's
+	// sessions.
+	Last(ctx context.Context, userID int64) (*models.LastStudySession, error)
+	Count(ctx context.Context, userID int64) (int, error)
+	CountActiveGroups(ctx context.Context, userID int64) (int, error)
+	// StudyDays returns the distinct UTC days on which the user started
+	// sessions, most recent first.
+	StudyDays(ctx context.Context, userID int64) ([]time.Time, error)
+	Create(ctx context.Context, session *models.StudySession) error
+	// End completes an active session. It returns models.ErrSessionClosed if
+	// the session has already ended.
+	End(ctx context.Context, id int64, at time.Time) error
+	// CloseIdle abandons active sessions whose last activity is before
+	// cutoff and returns how many were closed.
+	CloseIdle(ctx context.Context, cutoff time.Time) (int64, error)
+	DeleteByUser(ctx context.Context, userID int64) error
+	DeleteAll(ctx context.Context) error
+}
+
+type ReviewRepo interface {
+	Create(ctx context.Context, review *models.WordReview) error
+	// ListBySession returns the words reviewed in a session after a cursor,
+	// in review order. Their cursors are their review times and IDs.
+	ListBySession(ctx context.Context, sessionID int64, keyset Keyset) ([]models.SessionWord, error)
+	// ListByWord returns the user's reviews of a word after a cursor, newest
+	// first. Their cursors are their times and IDs.
+	ListByWord(ctx context.Context, userID, wordID int64, keyset Keyset) ([]models.WordReview, error)
+	// Totals returns the number of the user's correct reviews and of all
+	// their reviews.
+	Totals(ctx context.Context, userID int64) (correct int, total int, err error)
+	CountStudiedWords(ctx context.Context, userID int64) (int, error)
+	GetSchedule(ctx context.Context, userID, wordID int64) (*models.WordSchedule, error)
+	SaveSchedule(ctx context.Context, schedule *models.WordSchedule) error
+	// DueWords returns words due for the user at now, most overdue first,
+	// followed by words they never reviewed. A groupID of 0 means all words.
+	DueWords(ctx context.Context, userID, groupID int64, now time.Time, limit int) ([]models.DueWord, error)
+	// DeleteByUser removes the user's reviews and schedules.
+	DeleteByUser(ctx context.Context, userID int64) error
+	// DeleteAll removes every review and schedule.
+	DeleteAll(ctx context.Context) error
+}
+
+type ActivityRepo interface {
+	List(ctx context.Context) ([]models.StudyActivity, error)
+	Get(ctx context.Context, id int64) (*models.StudyActivity, error)
+	FindBySlug(ctx context.Context, slug string) (*models.StudyActivity, error)
+	// Create and Update return models.ErrDuplicateActivitySlug if the slug
+	// is taken.
+	Create(ctx context.Context, activity *models.StudyActivity) error
+	Update(ctx context.Context, activity *models.StudyActivity) error
+	// Delete removes an activity. It returns models.ErrActivityInUse if
+	// study sessions were recorded with it.
+	Delete(ctx context.Context, id int64) error
+	DeleteAll(ctx context.Context) error
+}
+
+type UserRepo interface {
+	List(ctx context.Context) ([]models.User, error)
+	Get(ctx context.Context, id int64) (*models.User, error)
+	FindByName(ctx context.Context, name string) (*models.User, error)
+	// Create returns models.ErrDuplicateUserName if the name is taken.
+	Create(ctx context.Context, user *models.User) error
+	SetPassword(ctx context.Context, id int64, hash string) error
+	SetRole(ctx context.Context, id int64, role string) error
+}
+
+type TokenRepo interface {
+	Create(ctx context.Context, token *models.AuthToken) error
+	// Get returns the token with the given hash, expired or not.
+	Get(ctx context.Context, hash string) (*models.AuthToken, error)
+	Delete(ctx context.Context, hash string) error
+	// DeleteUser revokes every token of a user.
+	DeleteUser(ctx context.Context, userID int64) error
+	DeleteExpired(ctx context.Context, now time.Time) error
+}
+
+// StatementFilter restricts a statement listing. Zero fields match
+// everything.
+type StatementFilter struct {
+	UserID       int64
+	ActorKey     string
+	VerbID       string
+	ObjectID     string
+	Registration string
+	// Since and Until bound the time the statements were stored, Since
+	// exclusive.
+	Since time.Time
+	Until time.Time
+	// Ascending lists the oldest statements first.
+	Ascending bool
+}
+
+// StateKey names an xAPI state document. A StateKey without a StateID
+// stands for all documents of its activity, agent and registration.
+type StateKey struct {
+	UserID       int64
+	ActivityID   string
+	AgentKey     string
+	Registration string
+	StateID      string
+}
+
+type XAPIRepo interface {
+	// CreateStatement returns models.ErrDuplicateStatement if the statement
+	// ID is taken.
+	CreateStatement(ctx context.Context, statement *models.StoredStatement) error
+	// GetStatement returns one of the user's statements, voided or not.
+	GetStatement(ctx context.Context, userID int64, statementID string) (*models.StoredStatement, error)
+	// ListStatements returns the statements after a cursor that match
+	// filter and have not been voided, newest first unless ascending. Their
+	// cursors are their stored times and IDs.
+	ListStatements(ctx context.Context, filter StatementFilter, keyset Keyset) ([]models.StoredStatement, error)
+	// VoidStatement marks one of the user's statements as voided.
+	VoidStatement(ctx context.Context, userID int64, statementID string) error
+	GetState(ctx context.Context, key StateKey) (*models.XAPIState, error)
+	// StateIDs returns, in order, the IDs of the documents of key's
+	// activity, agent and registration updated after since.
+	StateIDs(ctx context.Context, key StateKey, since time.Time) ([]string, error)
+	// SaveState creates or replaces a state document.
+	SaveState(ctx context.Context, state *models.XAPIState) error
+	// DeleteState removes the documents key stands for.
+	DeleteState(ctx context.Context, key StateKey) error
+	// DeleteByUser removes the user's statements and state documents.
+	DeleteByUser(ctx context.Context, userID int64) error
+	DeleteAll(ctx context.Context) error
+}
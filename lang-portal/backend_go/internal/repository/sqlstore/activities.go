@@ -0,0 +1,166 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	"lang-portal/backend_go/internal/models"
+)
+
+type activityRepo struct {
+	s *Store
+}
+
+const activityColumns = `
+	id, slug, name, description, thumbnail_url, launch_url, supported_group_types, required_word_fields, created_at
+`
+
+func scanActivity(row rowScanner) (*models.StudyActivity, error) {
+	var activity models.StudyActivity
+	var description, thumbnailURL sql.NullString
+	err := row.Scan(
+		&activity.ID,
+		&activity.Slug,
+		&activity.Name,
+		&description,
+		&thumbnailURL,
+		&activity.LaunchURL,
+		&activity.SupportedGroupTypes,
+		&activity.RequiredWordFields,
+		&activity.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	activity.Description = description.String
+	activity.ThumbnailURL = thumbnailURL.String
+	return &activity, nil
+}
+
+func (r *activityRepo) List(ctx context.Context) ([]models.StudyActivity, error) {
+	rows, err := r.s.q.QueryContext(ctx, `
+		SELECT`+activityColumns+`
+		FROM study_activities
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	activities := []models.StudyActivity{}
+	for rows.Next() {
+		activity, err := scanActivity(rows)
+		if err != nil {
+			return nil, err
+		}
+		activities = append(activities, *activity)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return activities, nil
+}
+
+func (r *activityRepo) Get(ctx context.Context, id int64) (*models.StudyActivity, error) {
+	return r.find(ctx, "id = ?", id)
+}
+
+func (r *activityRepo) FindBySlug(ctx context.Context, slug string) (*models.StudyActivity, error) {
+	return r.find(ctx, "slug = ?", slug)
+}
+
+func (r *activityRepo) find(ctx context.Context, where string, arg any) (*models.StudyActivity, error) {
+	row := r.s.q.QueryRowContext(ctx, `
+		SELECT`+activityColumns+`
+		FROM study_activities
+		WHERE `+where, arg)
+
+	activity, err := scanActivity(row)
+	if err == sql.ErrNoRows {
+		return nil, models.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return activity, nil
+}
+
+func (r *activityRepo) Create(ctx context.Context, activity *models.StudyActivity) error {
+	err := r.s.q.QueryRowContext(ctx, `
+		INSERT INTO study_activities (slug, name, description, thumbnail_url, launch_url, supported_group_types, required_word_fields)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`,
+		activity.Slug,
+		activity.Name,
+		activity.Description,
+		activity.ThumbnailURL,
+		activity.LaunchURL,
+		activity.SupportedGroupTypes,
+		activity.RequiredWordFields,
+	).Scan(&activity.ID)
+	if r.s.dialect.isUniqueViolation(err) {
+		return models.ErrDuplicateActivitySlug
+	}
+	if err != nil {
+		return err
+	}
+
+	return r.s.q.QueryRowContext(ctx, "SELECT created_at FROM study_activities WHERE id = ?", activity.ID).Scan(&activity.CreatedAt)
+}
+
+func (r *activityRepo) Update(ctx context.Context, activity *models.StudyActivity) error {
+	result, err := r.s.q.ExecContext(ctx, `
+		UPDATE study_activities
+		SET slug = ?, name = ?, description = ?, thumbnail_url = ?, launch_url = ?, supported_group_types = ?, required_word_fields = ?
+		WHERE id = ?
+	`,
+		activity.Slug,
+		activity.Name,
+		activity.Description,
+		activity.ThumbnailURL,
+		activity.LaunchURL,
+		activity.SupportedGroupTypes,
+		activity.RequiredWordFields,
+		activity.ID,
+	)
+	if r.s.dialect.isUniqueViolation(err) {
+		return models.ErrDuplicateActivitySlug
+	}
+	if err != nil {
+		return err
+	}
+
+	return affectedOrNotFound(result, models.ErrNotFound)
+}
+
+func (r *activityRepo) Delete(ctx context.Context, id int64) error {
+	return r.s.inTx(ctx, func(q querier) error {
+		// The learners' history is kept, so an activity with sessions stays
+		var inUse bool
+		err := q.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM study_sessions WHERE study_activity_id = ?)", id).Scan(&inUse)
+		if err != nil {
+			return err
+		}
+		if inUse {
+			return models.ErrActivityInUse
+		}
+
+		result, err := q.ExecContext(ctx, "DELETE FROM study_activities WHERE id = ?", id)
+		if err != nil {
+			return err
+		}
+
+		return affectedOrNotFound(result, models.ErrNotFound)
+	})
+}
+
+func (r *activityRepo) DeleteAll(ctx context.Context) error {
+	_, err := r.s.q.ExecContext(ctx, "DELETE FROM study_activities")
+	return err
+}
@@ -0,0 +1,114 @@
+package sqlstore
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"lang-portal/backend_go/internal/textnorm"
+
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+// Dialect describes the differences between the databases the store runs
+// on. Queries are written with SQLite's ? placeholders and rewritten for the
+// target database by Rebind.
+type Dialect struct {
+	// Name identifies the dialect in configuration, e.g. DB_DRIVER.
+	Name string
+	// Driver is the database/sql driver name.
+	Driver string
+	// MigrationDir is the directory in migrations.FS holding the dialect's
+	// schema migrations.
+	MigrationDir string
+
+	numbered          bool
+	migrationsTable   string
+	matchWords        func(terms []textnorm.Term) (from, where string, args []any)
+	day               func(column string) string
+	isUniqueViolation func(err error) bool
+}
+
+var SQLite = Dialect{
+	Name:         "sqlite",
+	Driver:       "sqlite3_textnorm", // go-sqlite3 with the search functions
+	MigrationDir: ".",
+	migrationsTable: `
+		CREATE TABLE IF NOT EXISTS migrations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE,
+			applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+	matchWords: matchFTS,
+	day: func(column string) string {
+		return "date(" + column + ")"
+	},
+	isUniqueViolation: func(err error) bool {
+		var sqliteErr sqlite3.Error
+		return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+	},
+}
+
+var Postgres = Dialect{
+	Name:         "postgres",
+	Driver:       "postgres",
+	MigrationDir: "postgres",
+	numbered:     true,
+	migrationsTable: `
+		CREATE TABLE IF NOT EXISTS migrations (
+			id SERIAL PRIMARY KEY,
+			name TEXT NOT NULL UNIQUE,
+			applied_at TIMESTAMP DEFAULT (now() AT TIME ZONE 'UTC')
+		)`,
+	matchWords: matchLike,
+	day: func(column string) string {
+		return "to_char(" + column + ", 'YYYY-MM-DD')"
+	},
+	isUniqueViolation: func(err error) bool {
+		var pqErr *pq.Error
+		return errors.As(err, &pqErr) && pqErr.Code == "23505"
+	},
+}
+
+// DialectFor returns the dialect with the given name.
+func DialectFor(name string) (Dialect, error) {
+	for _, dialect := range []Dialect{SQLite, Postgres} {
+		if dialect.Name == name {
+			return dialect, nil
+		}
+	}
+	return Dialect{}, fmt.Errorf("unknown database driver %q: must be sqlite or postgres", name)
+}
+
+// Rebind rewrites the ? placeholders in query into the dialect's form.
+// Question marks inside quoted strings are left alone.
+func (d Dialect) Rebind(query string) string {
+	if !d.numbered {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 16)
+
+	n := 0
+	var quote rune
+	for _, r := range query {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '?':
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,239 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+)
+
+type groupRepo struct {
+	s *Store
+}
+
+var groupSortColumns = map[string]string{
+	"id":         "g.id",
+	"name":       "lower(g.name)",
+	"word_count": "word_count",
+}
+
+func (r *groupRepo) List(ctx context.Context, opts repository.ListOptions) ([]models.GroupSummary, int, error) {
+	order, err := orderBy(opts, groupSortColumns, "g.id")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	if err := r.s.q.QueryRowContext(ctx, "SELECT COUNT(*) FROM groups").Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.s.q.QueryContext(ctx, `
+		SELECT
+			g.id,
+			g.name,
+			COUNT(DISTINCT wg.word_id) as word_count
+		FROM groups g
+		LEFT JOIN word_groups wg ON wg.group_id = g.id
+		GROUP BY g.id
+		ORDER BY `+order+`
+		LIMIT ? OFFSET ?
+	`, opts.Limit, opts.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	groups := []models.GroupSummary{}
+	for rows.Next() {
+		var group models.GroupSummary
+		if err := rows.Scan(&group.ID, &group.Name, &group.WordCount); err != nil {
+			return nil, 0, err
+		}
+		groups = append(groups, group)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return groups, total, nil
+}
+
+func (r *groupRepo) Get(ctx context.Context, id int64) (*models.Group, error) {
+	var group models.Group
+	err := r.s.q.QueryRowContext(ctx, `
+		SELECT id, name
+		FROM groups
+		WHERE id = ?
+	`, id).Scan(&group.ID, &group.Name)
+
+	if err == sql.ErrNoRows {
+		return nil, models.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+func (r *groupRepo) Stats(ctx context.Context, id int64) (*models.GroupStats, error) {
+	var stats models.GroupStats
+	err := r.s.q.QueryRowContext(ctx, `
+		SELECT COUNT(DISTINCT word_id)
+		FROM word_groups
+		WHERE group_id = ?
+	`, id).Scan(&stats.TotalWordCount)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
+func (r *groupRepo) Words(ctx context.Context, userID, id int64, opts repository.ListOptions) ([]models.GroupWord, int, error) {
+	order, err := orderBy(opts, wordSortColumns, "w.id")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	err = r.s.q.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM word_groups
+		WHERE group_id = ?
+	`, id).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.s.q.QueryContext(ctx, `
+		SELECT w.id, w.japanese, w.romaji, w.english, w.parts,`+wordStatsColumns+`
+		FROM words w
+		JOIN word_groups wg ON wg.word_id = w.id`+wordStatsJoin+`
+		WHERE wg.group_id = ?
+		ORDER BY `+order+`
+		LIMIT ? OFFSET ?
+	`, userID, userID, id, opts.Limit, opts.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	words := []models.GroupWord{}
+	for rows.Next() {
+		word := models.GroupWord{WordStats: models.WordStats{UserID: userID}}
+		err := scanWordStats(rows, &word.WordStats, &word.ID, &word.Japanese, &word.Romaji, &word.English, &word.Parts)
+		word.WordID = word.ID
+		if err != nil {
+			return nil, 0, err
+		}
+		words = append(words, word)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return words, total, nil
+}
+
+func (r *groupRepo) Create(ctx context.Context, group *models.Group) error {
+	err := r.s.q.QueryRowContext(ctx, "INSERT INTO groups (name) VALUES (?) RETURNING id", group.Name).Scan(&group.ID)
+	if r.s.dialect.isUniqueViolation(err) {
+		return models.ErrDuplicateGroupName
+	}
+	return err
+}
+
+func (r *groupRepo) Rename(ctx context.Context, id int64, name string) error {
+	result, err := r.s.q.ExecContext(ctx, "UPDATE groups SET name = ? WHERE id = ?", name, id)
+	if r.s.dialect.isUniqueViolation(err) {
+		return models.ErrDuplicateGroupName
+	}
+	if err != nil {
+		return err
+	}
+
+	return affectedOrNotFound(result, models.ErrNotFound)
+}
+
+func (r *groupRepo) Delete(ctx context.Context, id int64) error {
+	return r.s.inTx(ctx, func(q querier) error {
+		// Mirror the ON DELETE CASCADE rules, foreign key enforcement is off
+		// unless the connection enables it
+		dependents := []string{
+			"DELETE FROM word_groups WHERE group_id = ?",
+			`DELETE FROM word_review_items WHERE study_session_id IN (
+				SELECT id FROM study_sessions WHERE group_id = ?
+			)`,
+			"DELETE FROM study_sessions WHERE group_id = ?",
+		}
+		for _, stmt := range dependents {
+			if _, err := q.ExecContext(ctx, stmt, id); err != nil {
+				return err
+			}
+		}
+
+		result, err := q.ExecContext(ctx, "DELETE FROM groups WHERE id = ?", id)
+		if err != nil {
+			return err
+		}
+
+		return affectedOrNotFound(result, models.ErrNotFound)
+	})
+}
+
+func (r *groupRepo) DeleteAll(ctx context.Context) error {
+	return r.s.inTx(ctx, func(q querier) error {
+		if _, err := q.ExecContext(ctx, "DELETE FROM word_groups"); err != nil {
+			return err
+		}
+		_, err := q.ExecContext(ctx, "DELETE FROM groups")
+		return err
+	})
+}
+
+func (r *groupRepo) AddWords(ctx context.Context, id int64, wordIDs []int64) (int, error) {
+	return r.updateWords(ctx, `
+		INSERT INTO word_groups (word_id, group_id)
+		VALUES (?, ?)
+		ON CONFLICT DO NOTHING
+	`, id, wordIDs)
+}
+
+func (r *groupRepo) RemoveWords(ctx context.Context, id int64, wordIDs []int64) (int, error) {
+	return r.updateWords(ctx, `
+		DELETE FROM word_groups
+		WHERE word_id = ? AND group_id = ?
+	`, id, wordIDs)
+}
+
+// updateWords runs stmt for every word and returns the total number of rows
+// it affected.
+func (r *groupRepo) updateWords(ctx context.Context, stmt string, id int64, wordIDs []int64) (int, error) {
+	changed := 0
+	err := r.s.inTx(ctx, func(q querier) error {
+		for _, wordID := range wordIDs {
+			result, err := q.ExecContext(ctx, stmt, wordID, id)
+			if err != nil {
+				return err
+			}
+
+			affected, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+			changed += int(affected)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return changed, nil
+}
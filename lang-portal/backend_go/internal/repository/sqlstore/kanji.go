@@ -0,0 +1,84 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	"lang-portal/backend_go/internal/models"
+)
+
+type kanjiRepo struct {
+	s *Store
+}
+
+func (r *kanjiRepo) Get(ctx context.Context, character string) (*models.Kanji, error) {
+	var kanji models.Kanji
+	err := r.s.q.QueryRowContext(ctx, `
+		SELECT id, literal, on_readings, kun_readings, meanings, stroke_count, jlpt_level
+		FROM kanji
+		WHERE literal = ?
+	`, character).Scan(
+		&kanji.ID,
+		&kanji.Character,
+		&kanji.OnReadings,
+		&kanji.KunReadings,
+		&kanji.Meanings,
+		&kanji.StrokeCount,
+		&kanji.JLPTLevel,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, models.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &kanji, nil
+}
+
+// Create relies on the word_kanji triggers to link the kanji to its words.
+func (r *kanjiRepo) Create(ctx context.Context, kanji *models.Kanji) error {
+	return r.s.q.QueryRowContext(ctx, `
+		INSERT INTO kanji (literal, on_readings, kun_readings, meanings, stroke_count, jlpt_level)
+		VALUES (?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`, kanji.Character, kanji.OnReadings, kanji.KunReadings, kanji.Meanings, kanji.StrokeCount, kanji.JLPTLevel).Scan(&kanji.ID)
+}
+
+func (r *kanjiRepo) Words(ctx context.Context, userID, id int64) ([]models.WordSummary, error) {
+	rows, err := r.s.q.QueryContext(ctx, `
+		SELECT w.id, w.japanese, w.romaji, w.english, w.parts,`+wordStatsColumns+`
+		FROM words w
+		JOIN word_kanji wk ON wk.word_id = w.id`+wordStatsJoin+`
+		WHERE wk.kanji_id = ?
+		ORDER BY w.id
+	`, userID, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	words := []models.WordSummary{}
+	for rows.Next() {
+		word := models.WordSummary{WordStats: models.WordStats{UserID: userID}}
+		err := scanWordStats(rows, &word.WordStats, &word.ID, &word.Japanese, &word.Romaji, &word.English, &word.Parts)
+		word.WordID = word.ID
+		if err != nil {
+			return nil, err
+		}
+		words = append(words, word)
+	}
+
+	return words, rows.Err()
+}
+
+func (r *kanjiRepo) DeleteAll(ctx context.Context) error {
+	return r.s.inTx(ctx, func(q querier) error {
+		if _, err := q.ExecContext(ctx, "DELETE FROM word_kanji"); err != nil {
+			return err
+		}
+		_, err := q.ExecContext(ctx, "DELETE FROM kanji")
+		return err
+	})
+}
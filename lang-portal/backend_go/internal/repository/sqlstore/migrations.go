@@ -0,0 +1,234 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"lang-portal/backend_go/db/migrations"
+)
+
+// Migration is a schema change. Name is the file name of the up migration,
+// as recorded in the migrations table.
+type Migration struct {
+	Name string
+	Up   string
+	// Down reverts Up. It is only set when Reversible.
+	Down       string
+	Reversible bool
+}
+
+// MigrationStatus tells whether and when a migration was applied.
+type MigrationStatus struct {
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// LoadMigrations returns the dialect's embedded migrations in the order they
+// are applied.
+func (d Dialect) LoadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrations.FS, d.MigrationDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Migration
+	downs := map[string]string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".sql") {
+			continue
+		}
+
+		content, err := fs.ReadFile(migrations.FS, path.Join(d.MigrationDir, name))
+		if err != nil {
+			return nil, err
+		}
+		if up, ok := strings.CutSuffix(name, ".down.sql"); ok {
+			downs[up+".sql"] = string(content)
+			continue
+		}
+		list = append(list, Migration{Name: name, Up: string(content)})
+	}
+
+	for i := range list {
+		list[i].Down, list[i].Reversible = downs[list[i].Name]
+		delete(downs, list[i].Name)
+	}
+	for name := range downs {
+		return nil, fmt.Errorf("down migration for %s has no up migration", name)
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// Migrate applies the pending migrations, each in a transaction of its
+// own, and returns their names.
+func (s *Store) Migrate(ctx context.Context) ([]string, error) {
+	list, err := s.dialect.LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.createMigrationsTable(ctx); err != nil {
+		return nil, err
+	}
+	applied, err := s.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	done := []string{}
+	for _, m := range list {
+		if _, ok := applied[m.Name]; ok {
+			continue
+		}
+		if err := s.runMigration(ctx, m.Name, m.Up, "INSERT INTO migrations (name) VALUES (?)"); err != nil {
+			return done, err
+		}
+		done = append(done, m.Name)
+	}
+	return done, nil
+}
+
+// Rollback reverts the last steps applied migrations, newest first, and
+// returns their names.
+func (s *Store) Rollback(ctx context.Context, steps int) ([]string, error) {
+	list, err := s.dialect.LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.createMigrationsTable(ctx); err != nil {
+		return nil, err
+	}
+	applied, err := s.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	done := []string{}
+	for i := len(list) - 1; i >= 0 && len(done) < steps; i-- {
+		m := list[i]
+		if _, ok := applied[m.Name]; !ok {
+			continue
+		}
+		if !m.Reversible {
+			return done, fmt.Errorf("migration %s cannot be reverted: it has no down migration", m.Name)
+		}
+		if err := s.runMigration(ctx, m.Name, m.Down, "DELETE FROM migrations WHERE name = ?"); err != nil {
+			return done, err
+		}
+		done = append(done, m.Name)
+	}
+	return done, nil
+}
+
+// runMigration runs script and records the change with the record query in
+// one transaction.
+func (s *Store) runMigration(ctx context.Context, name, script, record string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range splitStatements(script) {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("error executing migration %s: %v", name, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, s.dialect.Rebind(record), name); err != nil {
+		return fmt.Errorf("error recording migration %s: %v", name, err)
+	}
+
+	return tx.Commit()
+}
+
+// MigrationStatus lists the embedded migrations and whether they have been
+// applied.
+func (s *Store) MigrationStatus(ctx context.Context) ([]MigrationStatus, error) {
+	if err := s.createMigrationsTable(ctx); err != nil {
+		return nil, err
+	}
+	return s.migrationStatus(ctx)
+}
+
+// migrationStatus is MigrationStatus for a database that has a migrations
+// table.
+func (s *Store) migrationStatus(ctx context.Context) ([]MigrationStatus, error) {
+	list, err := s.dialect.LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := s.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := make([]MigrationStatus, len(list))
+	for i, m := range list {
+		status[i].Name = m.Name
+		status[i].AppliedAt, status[i].Applied = applied[m.Name]
+	}
+	return status, nil
+}
+
+func (s *Store) createMigrationsTable(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, s.dialect.migrationsTable); err != nil {
+		return fmt.Errorf("error creating migrations table: %v", err)
+	}
+	return nil
+}
+
+// appliedMigrations maps the names in the migrations table to when they
+// were applied.
+func (s *Store) appliedMigrations(ctx context.Context) (map[string]time.Time, error) {
+	rows, err := s.q.QueryContext(ctx, "SELECT name, applied_at FROM migrations")
+	if err != nil {
+		return nil, fmt.Errorf("error reading applied migrations: %v", err)
+	}
+	defer rows.Close()
+
+	applied := map[string]time.Time{}
+	for rows.Next() {
+		var name string
+		var appliedAt sql.NullTime
+		if err := rows.Scan(&name, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[name] = appliedAt.Time
+	}
+	return applied, rows.Err()
+}
+
+// Ready checks that the database answers and that every embedded migration
+// has been applied.
+func (s *Store) Ready(ctx context.Context) error {
+	if err := s.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("database unreachable: %v", err)
+	}
+
+	// Readiness probes must not write, so a missing migrations table is
+	// reported rather than created
+	status, err := s.migrationStatus(ctx)
+	if err != nil {
+		return err
+	}
+
+	var pending []string
+	for _, m := range status {
+		if !m.Applied {
+			pending = append(pending, m.Name)
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("migrations not applied: %s", strings.Join(pending, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"lang-portal/backend_go/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name:   "Plain statements",
+			script: "CREATE TABLE a (id INTEGER);\nCREATE TABLE b (id INTEGER);\n",
+			want:   []string{"CREATE TABLE a (id INTEGER)", "CREATE TABLE b (id INTEGER)"},
+		},
+		{
+			name:   "No trailing semicolon",
+			script: "DROP TABLE a",
+			want:   []string{"DROP TABLE a"},
+		},
+		{
+			name:   "Semicolons in strings and identifiers",
+			script: `INSERT INTO a (note) VALUES ('one; two', 'it''s; fine');UPDATE "odd;name" SET x = 1;`,
+			want:   []string{`INSERT INTO a (note) VALUES ('one; two', 'it''s; fine')`, `UPDATE "odd;name" SET x = 1`},
+		},
+		{
+			name:   "Semicolons in comments",
+			script: "-- first; not a split\nSELECT 1; /* block; comment */ SELECT 2;\n-- trailing; comment\n",
+			want:   []string{"-- first; not a split\nSELECT 1", "/* block; comment */ SELECT 2"},
+		},
+		{
+			name: "Trigger body",
+			script: `CREATE TRIGGER words_ai AFTER INSERT ON words BEGIN
+    INSERT INTO log (word_id, kind) VALUES (new.id, CASE WHEN new.english = '' THEN 'empty' ELSE 'word' END);
+    UPDATE counts SET n = n + 1;
+END;
+CREATE TEMP TRIGGER t AFTER DELETE ON words BEGIN DELETE FROM log WHERE word_id = old.id; END;
+SELECT 1;`,
+			want: []string{
+				`CREATE TRIGGER words_ai AFTER INSERT ON words BEGIN
+    INSERT INTO log (word_id, kind) VALUES (new.id, CASE WHEN new.english = '' THEN 'empty' ELSE 'word' END);
+    UPDATE counts SET n = n + 1;
+END`,
+				"CREATE TEMP TRIGGER t AFTER DELETE ON words BEGIN DELETE FROM log WHERE word_id = old.id; END",
+				"SELECT 1",
+			},
+		},
+		{
+			name:   "Columns named like keywords outside triggers",
+			script: "CREATE TABLE t (trigger TEXT, begin TEXT);SELECT 1;",
+			want:   []string{"CREATE TABLE t (trigger TEXT, begin TEXT)", "SELECT 1"},
+		},
+		{
+			name:   "Dollar-quoted body",
+			script: "CREATE FUNCTION f() RETURNS trigger AS $body$ BEGIN NEW.x := 1; RETURN NEW; END; $body$ LANGUAGE plpgsql;SELECT $$a;b$$;",
+			want:   []string{"CREATE FUNCTION f() RETURNS trigger AS $body$ BEGIN NEW.x := 1; RETURN NEW; END; $body$ LANGUAGE plpgsql", "SELECT $$a;b$$"},
+		},
+		{
+			name:   "Only comments",
+			script: "-- Nothing to revert; really\n",
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, splitStatements(tt.script))
+		})
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	for _, dialect := range []Dialect{SQLite, Postgres} {
+		list, err := dialect.LoadMigrations()
+		require.NoError(t, err)
+		require.NotEmpty(t, list)
+
+		for i, m := range list {
+			assert.False(t, strings.HasSuffix(m.Name, ".down.sql"), m.Name)
+			assert.True(t, m.Reversible, m.Name)
+			if i > 0 {
+				assert.Less(t, list[i-1].Name, m.Name)
+			}
+		}
+	}
+}
+
+func TestMigrateUpAndDown(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB, s *Store) {
+		ctx := context.Background()
+		list, err := s.dialect.LoadMigrations()
+		require.NoError(t, err)
+
+		// forEachDialect already migrated
+		applied, err := s.Migrate(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, applied)
+		require.NoError(t, s.Ready(ctx))
+
+		// Keep some history so that down migrations run against data
+		word := createWord(t, s, "こんにちは", "konnichiwa", "hello")
+		activityID := createActivity(t, db, s.dialect, "Vocabulary Quiz")
+		_, err = db.Exec(s.dialect.Rebind("INSERT INTO groups (name) VALUES (?)"), "Basic Greetings")
+		require.NoError(t, err)
+		_, err = db.Exec(s.dialect.Rebind("INSERT INTO study_sessions (group_id, study_activity_id) VALUES (1, ?)"), activityID)
+		require.NoError(t, err)
+		_, err = db.Exec(s.dialect.Rebind("INSERT INTO word_review_items (word_id, study_session_id, correct) VALUES (?, 1, ?)"), word.ID, true)
+		require.NoError(t, err)
+
+		reverted, err := s.Rollback(ctx, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{list[len(list)-1].Name, list[len(list)-2].Name}, reverted)
+		assert.ErrorContains(t, s.Ready(ctx), "migrations not applied: "+list[len(list)-2].Name)
+
+		status, err := s.MigrationStatus(ctx)
+		require.NoError(t, err)
+		require.Len(t, status, len(list))
+		assert.True(t, status[0].Applied)
+		assert.False(t, status[0].AppliedAt.IsZero())
+		assert.False(t, status[len(list)-1].Applied)
+
+		// Back up again, then all the way down to an empty schema
+		applied, err = s.Migrate(ctx)
+		require.NoError(t, err)
+		assert.Len(t, applied, 2)
+		require.NoError(t, s.Ready(ctx))
+
+		reverted, err = s.Rollback(ctx, len(list)+1)
+		require.NoError(t, err)
+		assert.Len(t, reverted, len(list))
+		_, err = db.Exec("SELECT 1 FROM words")
+		assert.Error(t, err)
+
+		applied, err = s.Migrate(ctx)
+		require.NoError(t, err)
+		assert.Len(t, applied, len(list))
+	})
+}
+
+func TestReady(t *testing.T) {
+	db, err := sql.Open(SQLite.Driver, ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	s := New(db, SQLite)
+	ctx := context.Background()
+
+	// Probes do not create the migrations table
+	assert.ErrorContains(t, s.Ready(ctx), "error reading applied migrations")
+	assert.ErrorContains(t, s.Ready(ctx), "error reading applied migrations")
+
+	_, err = s.Migrate(ctx)
+	require.NoError(t, err)
+	assert.NoError(t, s.Ready(ctx))
+
+	db.Close()
+	assert.ErrorContains(t, s.Ready(ctx), "database unreachable")
+}
+
+func TestStructureWordParts(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB, s *Store) {
+		ctx := context.Background()
+		rollbackTo := func(name string) {
+			for {
+				reverted, err := s.Rollback(ctx, 1)
+				require.NoError(t, err)
+				require.Len(t, reverted, 1)
+				if reverted[0] == name {
+					return
+				}
+			}
+		}
+		rollbackTo("0015_structure_word_parts.sql")
+
+		one := 1
+		legacy := []struct {
+			japanese string
+			parts    string
+			want     models.Parts
+		}{
+			{"こんにちは", `{"type": "greeting", "politeness": "neutral", "note": "x"}`, models.Parts{Type: "greeting", Formality: "neutral"}},
+			{"一", `{"type": "number", "value": 1, "category": "cardinal"}`, models.Parts{Type: "number", Category: "cardinal", Number: &one}},
+			{"食べる", `[{"kanji": "食", "romaji": "ta"}, {"kanji": "べる", "romaji": "beru"}]`, models.Parts{Segments: []models.Segment{{Kanji: "食", Romaji: "ta"}, {Kanji: "べる", Romaji: "beru"}}}},
+			{"晴れ", `[{"kanji": "晴", "romaji": ["ha", "re"]}, "れ"]`, models.Parts{Segments: []models.Segment{{Kanji: "晴", Romaji: "hare"}}}},
+			{"水", `[]`, models.Parts{}},
+			{"火", `{"type": 5, "value": "five"}`, models.Parts{}},
+			{"木", `"tree"`, models.Parts{}},
+			{"山", `{not json`, models.Parts{}},
+		}
+		for _, word := range legacy {
+			_, err := db.Exec(s.dialect.Rebind("INSERT INTO words (japanese, romaji, english, parts) VALUES (?, '', '', ?)"), word.japanese, word.parts)
+			require.NoError(t, err)
+		}
+
+		_, err := s.Migrate(ctx)
+		require.NoError(t, err)
+
+		for i, word := range legacy {
+			got, err := s.Words().Get(ctx, int64(i+1))
+			require.NoError(t, err)
+			assert.Equal(t, word.want, got.Parts, word.japanese)
+		}
+
+		// Reverting turns bare segments back into an array
+		rollbackTo("0015_structure_word_parts.sql")
+		var parts string
+		require.NoError(t, db.QueryRow("SELECT parts FROM words WHERE id = 3").Scan(&parts))
+		assert.JSONEq(t, `[{"kanji": "食", "romaji": "ta"}, {"kanji": "べる", "romaji": "beru"}]`, parts)
+	})
+}
@@ -0,0 +1,315 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+)
+
+type reviewRepo struct {
+	s *Store
+}
+
+func (r *reviewRepo) Create(ctx context.Context, review *models.WordReview) error {
+	err := r.s.q.QueryRowContext(ctx, `
+		INSERT INTO word_review_items (
+			user_id, word_id, study_session_id, correct, answer_given, response_ms, quality, direction, hints_used
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`,
+		review.UserID,
+		review.WordID,
+		review.StudySessionID,
+		review.Correct,
+		review.AnswerGiven,
+		review.ResponseMS,
+		review.Quality,
+		review.Direction,
+		review.HintsUsed,
+	).Scan(&review.ID)
+	if err != nil {
+		return err
+	}
+
+	return r.s.q.QueryRowContext(ctx, "SELECT created_at FROM word_review_items WHERE id = ?", review.ID).Scan(&review.CreatedAt)
+}
+
+func (r *reviewRepo) ListBySession(ctx context.Context, sessionID int64, keyset repository.Keyset) ([]models.SessionWord, error) {
+	cond, args := after("wri.created_at", "wri.id", keyset.After, false)
+	rows, err := r.s.q.QueryContext(ctx, `
+		SELECT
+			w.id,
+			w.japanese,
+			w.romaji,
+			w.english,
+			w.parts,
+			wri.id,
+			wri.correct,
+			wri.answer_given,
+			wri.response_ms,
+			wri.quality,
+			wri.direction,
+			wri.hints_used,
+			wri.created_at as reviewed_at
+		FROM words w
+		JOIN word_review_items wri ON wri.word_id = w.id
+		WHERE wri.study_session_id = ?`+cond+`
+		ORDER BY wri.created_at, wri.id
+		LIMIT ?
+	`, append(append([]any{sessionID}, args...), keyset.Limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	words := []models.SessionWord{}
+	for rows.Next() {
+		var word models.SessionWord
+		err := rows.Scan(
+			&word.ID,
+			&word.Japanese,
+			&word.Romaji,
+			&word.English,
+			&word.Parts,
+			&word.ReviewID,
+			&word.Correct,
+			&word.AnswerGiven,
+			&word.ResponseMS,
+			&word.Quality,
+			&word.Direction,
+			&word.HintsUsed,
+			&word.ReviewedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		words = append(words, word)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return words, nil
+}
+
+func (r *reviewRepo) ListByWord(ctx context.Context, userID, wordID int64, keyset repository.Keyset) ([]models.WordReview, error) {
+	cond, args := after("wri.created_at", "wri.id", keyset.After, true)
+	rows, err := r.s.q.QueryContext(ctx, `
+		SELECT
+			wri.id,
+			wri.user_id,
+			wri.word_id,
+			wri.study_session_id,
+			wri.correct,
+			wri.answer_given,
+			wri.response_ms,
+			wri.quality,
+			wri.direction,
+			wri.hints_used,
+			wri.created_at
+		FROM word_review_items wri
+		WHERE wri.user_id = ? AND wri.word_id = ?`+cond+`
+		ORDER BY wri.created_at DESC, wri.id DESC
+		LIMIT ?
+	`, append(append([]any{userID, wordID}, args...), keyset.Limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := []models.WordReview{}
+	for rows.Next() {
+		var review models.WordReview
+		err := rows.Scan(
+			&review.ID,
+			&review.UserID,
+			&review.WordID,
+			&review.StudySessionID,
+			&review.Correct,
+			&review.AnswerGiven,
+			&review.ResponseMS,
+			&review.Quality,
+			&review.Direction,
+			&review.HintsUsed,
+			&review.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+
+	return reviews, rows.Err()
+}
+
+func (r *reviewRepo) Totals(ctx context.Context, userID int64) (int, int, error) {
+	var correct, total int
+	err := r.s.q.QueryRowContext(ctx, `
+		SELECT
+			COALESCE(SUM(correct_count), 0),
+			COALESCE(SUM(correct_count + wrong_count), 0)
+		FROM word_stats
+		WHERE user_id = ?
+	`, userID).Scan(&correct, &total)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return correct, total, nil
+}
+
+func (r *reviewRepo) CountStudiedWords(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := r.s.q.QueryRowContext(ctx, "SELECT COUNT(*) FROM word_stats WHERE user_id = ?", userID).Scan(&count)
+	return count, err
+}
+
+func (r *reviewRepo) GetSchedule(ctx context.Context, userID, wordID int64) (*models.WordSchedule, error) {
+	var schedule models.WordSchedule
+	err := r.s.q.QueryRowContext(ctx, `
+		SELECT user_id, word_id, ease_factor, interval_days, repetitions, due_at, last_reviewed_at
+		FROM word_schedules
+		WHERE user_id = ? AND word_id = ?
+	`, userID, wordID).Scan(
+		&schedule.UserID,
+		&schedule.WordID,
+		&schedule.EaseFactor,
+		&schedule.IntervalDays,
+		&schedule.Repetitions,
+		&schedule.DueAt,
+		&schedule.LastReviewedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, models.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &schedule, nil
+}
+
+func (r *reviewRepo) SaveSchedule(ctx context.Context, schedule *models.WordSchedule) error {
+	_, err := r.s.q.ExecContext(ctx, `
+		INSERT INTO word_schedules (user_id, word_id, ease_factor, interval_days, repetitions, due_at, last_reviewed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id, word_id) DO UPDATE SET
+			ease_factor = excluded.ease_factor,
+			interval_days = excluded.interval_days,
+			repetitions = excluded.repetitions,
+			due_at = excluded.due_at,
+			last_reviewed_at = excluded.last_reviewed_at
+	`,
+		schedule.UserID,
+		schedule.WordID,
+		schedule.EaseFactor,
+		schedule.IntervalDays,
+		schedule.Repetitions,
+		formatTime(schedule.DueAt),
+		formatTime(schedule.LastReviewedAt),
+	)
+	return err
+}
+
+func (r *reviewRepo) DueWords(ctx context.Context, userID, groupID int64, now time.Time, limit int) ([]models.DueWord, error) {
+	query := `
+		SELECT
+			w.id, w.japanese, w.romaji, w.english, w.parts,
+			ws.ease_factor, ws.interval_days, ws.repetitions, ws.due_at, ws.last_reviewed_at
+		FROM words w
+		LEFT JOIN word_schedules ws ON ws.word_id = w.id AND ws.user_id = ?
+	`
+	params := []any{userID}
+	if groupID != 0 {
+		query += " JOIN word_groups wg ON wg.word_id = w.id AND wg.group_id = ?"
+		params = append(params, groupID)
+	}
+	query += `
+		WHERE ws.word_id IS NULL OR ws.due_at <= ?
+		ORDER BY ws.due_at IS NULL, ws.due_at, w.id
+		LIMIT ?
+	`
+	params = append(params, formatTime(now), limit)
+
+	rows, err := r.s.q.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	words := []models.DueWord{}
+	for rows.Next() {
+		var word models.DueWord
+		var (
+			easeFactor     sql.NullFloat64
+			intervalDays   sql.NullInt64
+			repetitions    sql.NullInt64
+			dueAt          sql.NullTime
+			lastReviewedAt sql.NullTime
+		)
+		err := rows.Scan(
+			&word.ID,
+			&word.Japanese,
+			&word.Romaji,
+			&word.English,
+			&word.Parts,
+			&easeFactor,
+			&intervalDays,
+			&repetitions,
+			&dueAt,
+			&lastReviewedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if dueAt.Valid {
+			word.Schedule = &models.WordSchedule{
+				UserID:         userID,
+				WordID:         word.ID,
+				EaseFactor:     easeFactor.Float64,
+				IntervalDays:   int(intervalDays.Int64),
+				Repetitions:    int(repetitions.Int64),
+				DueAt:          dueAt.Time,
+				LastReviewedAt: lastReviewedAt.Time,
+			}
+		}
+		words = append(words, word)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return words, nil
+}
+
+func (r *reviewRepo) DeleteByUser(ctx context.Context, userID int64) error {
+	return r.s.inTx(ctx, func(q querier) error {
+		// Clearing the stats first spares the triggers updating them
+		// review by review
+		for _, table := range []string{"word_schedules", "word_stats", "word_review_items"} {
+			if _, err := q.ExecContext(ctx, "DELETE FROM "+table+" WHERE user_id = ?", userID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *reviewRepo) DeleteAll(ctx context.Context) error {
+	return r.s.inTx(ctx, func(q querier) error {
+		for _, table := range []string{"word_schedules", "word_stats", "word_review_items"} {
+			if _, err := q.ExecContext(ctx, "DELETE FROM "+table); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,113 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"lang-portal/backend_go/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tableModels maps every table the migrations create to the model holding
+// its rows. A model's db tags name the table's columns.
+var tableModels = map[string]any{
+	"words":             models.Word{},
+	"groups":            models.Group{},
+	"kanji":             models.Kanji{},
+	"study_activities":  models.StudyActivity{},
+	"study_sessions":    models.StudySession{},
+	"word_review_items": models.WordReview{},
+	"word_schedules":    models.WordSchedule{},
+	"word_stats":        models.WordStats{},
+	"users":             models.User{},
+	"auth_tokens":       models.AuthToken{},
+	"xapi_statements":   models.StoredStatement{},
+	"xapi_states":       models.XAPIState{},
+}
+
+// tablesWithoutModels are never read into a model of their own.
+var tablesWithoutModels = map[string][]string{
+	"word_groups": {"id", "word_id", "group_id"},
+	"word_kanji":  {"word_id", "kanji_id"},
+	"migrations":  {"id", "name", "applied_at"},
+	"word_search": {"word_id", "japanese", "romaji", "english"},
+}
+
+// TestSchemaMatchesModels fails when a migration adds, drops or renames a
+// table or column without the models following, or the other way round.
+func TestSchemaMatchesModels(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB, s *Store) {
+		schema := tableColumns(t, db, s.dialect)
+
+		for table, columns := range schema {
+			if want, ok := tablesWithoutModels[table]; ok {
+				assert.ElementsMatch(t, want, columns, table)
+				continue
+			}
+
+			model, ok := tableModels[table]
+			if !assert.True(t, ok, "table %s has no model, add it to tableModels", table) {
+				continue
+			}
+			assert.ElementsMatch(t, modelColumns(model), columns,
+				"columns of table %s differ from the db tags of %T", table, model)
+		}
+
+		for table := range tableModels {
+			assert.Contains(t, schema, table, "no migration creates table %s", table)
+		}
+	})
+}
+
+// tableColumns returns the columns of every table in the database.
+func tableColumns(t *testing.T, db *sql.DB, dialect Dialect) map[string][]string {
+	query := `
+		SELECT m.name, p.name
+		FROM sqlite_master m, pragma_table_info(m.name) p
+		WHERE m.type = 'table' AND m.name NOT LIKE 'sqlite_%'
+		-- Full-text indexes and their shadow tables belong to the FTS module
+		AND NOT EXISTS (
+			SELECT 1 FROM sqlite_master v
+			WHERE v.sql LIKE 'CREATE VIRTUAL TABLE%'
+			AND (m.name = v.name OR m.name LIKE v.name || '_%')
+		)
+	`
+	if dialect.Name == Postgres.Name {
+		query = `
+			SELECT table_name, column_name
+			FROM information_schema.columns
+			WHERE table_schema = current_schema()
+		`
+	}
+
+	rows, err := db.Query(query)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	schema := map[string][]string{}
+	for rows.Next() {
+		var table, column string
+		require.NoError(t, rows.Scan(&table, &column))
+		schema[table] = append(schema[table], column)
+	}
+	require.NoError(t, rows.Err())
+	return schema
+}
+
+// modelColumns returns the db tags of a model's fields.
+func modelColumns(model any) []string {
+	var columns []string
+	typ := reflect.TypeOf(model)
+	for i := 0; i < typ.NumField(); i++ {
+		if column, _, _ := strings.Cut(typ.Field(i).Tag.Get("db"), ","); column != "" {
+			columns = append(columns, column)
+		}
+	}
+	sort.Strings(columns)
+	return columns
+}
@@ -0,0 +1,93 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"strings"
+	"unicode/utf8"
+
+	"lang-portal/backend_go/internal/textnorm"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// The word search index holds the textnorm keys of each word. SQLite builds
+// them in triggers calling search_japanese, search_romaji and
+// search_english, which only exist on connections of this driver.
+func init() {
+	sql.Register(SQLite.Driver, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			for _, field := range textnorm.Fields {
+				if err := conn.RegisterFunc("search_"+field.String(), field.Key, true); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
+
+// The dialects' matchWords return the words w joined with their search
+// keys s and the condition selecting the words that match every term.
+
+// matchFTS selects the words matching every term from SQLite's full-text
+// index. Japanese terms are phrases of single characters, romaji and
+// English terms match token prefixes. FTS4 cannot limit a phrase to a
+// column inside a query, so each column is matched on its own.
+func matchFTS(terms []textnorm.Term) (string, string, []any) {
+	conds := make([]string, len(terms))
+	var args []any
+	for i, term := range terms {
+		conds[i] = `w.id IN (
+			SELECT docid FROM word_search WHERE japanese MATCH ?
+			UNION SELECT docid FROM word_search WHERE romaji MATCH ?
+			UNION SELECT docid FROM word_search WHERE english MATCH ?
+		)`
+		// Keys hold no double quotes, they would have been folded into
+		// token separators
+		args = append(args,
+			`"`+term.Key(textnorm.Japanese)+`"`,
+			`"`+term.Key(textnorm.Romaji)+`*"`,
+			`"`+term.Key(textnorm.English)+`*"`)
+	}
+	return "words w JOIN word_search s ON s.docid = w.id",
+		" WHERE " + strings.Join(conds, " AND "),
+		args
+}
+
+// matchLike selects the words matching every term from a plain table of
+// search keys, with the same semantics as matchFTS.
+func matchLike(terms []textnorm.Term) (string, string, []any) {
+	conds := make([]string, len(terms))
+	var args []any
+	for i, term := range terms {
+		// Keys hold no % or _, they would have been folded into token
+		// separators
+		conds[i] = `(' ' || s.japanese || ' ' LIKE ? OR ' ' || s.romaji LIKE ? OR ' ' || s.english LIKE ?)`
+		args = append(args,
+			"% "+term.Key(textnorm.Japanese)+" %",
+			"% "+term.Key(textnorm.Romaji)+"%",
+			"% "+term.Key(textnorm.English)+"%")
+	}
+	return "word_search s JOIN words w ON w.id = s.word_id",
+		" WHERE " + strings.Join(conds, " AND "),
+		args
+}
+
+// rankWords orders search results like textnorm.Term.Rank: words with a
+// field equal to the query first, then words with a field starting with it.
+func rankWords(query textnorm.Term) (string, []any) {
+	var exact, prefix []string
+	var exactArgs, prefixArgs []any
+	for _, field := range textnorm.Fields {
+		column := "s." + field.String()
+		key := query.Key(field)
+		exact = append(exact, column+" = ?")
+		exactArgs = append(exactArgs, key)
+		prefix = append(prefix, "substr("+column+", 1, ?) = ?")
+		prefixArgs = append(prefixArgs, utf8.RuneCountInString(key), key)
+	}
+
+	rank := "CASE WHEN " + strings.Join(exact, " OR ") + " THEN 0" +
+		" WHEN " + strings.Join(prefix, " OR ") + " THEN 1 ELSE 2 END"
+	return rank, append(exactArgs, prefixArgs...)
+}
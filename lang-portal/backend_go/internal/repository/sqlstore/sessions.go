@@ -0,0 +1,340 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+)
+
+type sessionRepo struct {
+	s *Store
+}
+
+const sessionDetailSelect = `
+	SELECT
+		ss.id,
+		ss.user_id,
+		sa.name as activity_name,
+		g.name as group_name,
+		ss.status,
+		ss.created_at as start_time,
+		ss.ended_at as end_time,
+		COUNT(wri.id) as review_items_count
+	FROM study_sessions ss
+	JOIN study_activities sa ON sa.id = ss.study_activity_id
+	JOIN groups g ON g.id = ss.group_id
+	LEFT JOIN word_review_items wri ON wri.study_session_id = ss.id
+`
+
+// sessionDetailGroupBy lists every non-aggregated column, as PostgreSQL
+// requires.
+const sessionDetailGroupBy = `
+	GROUP BY ss.id, sa.name, g.name
+`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSessionDetail(row rowScanner) (*models.StudySessionDetail, error) {
+	var session models.StudySessionDetail
+	err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.ActivityName,
+		&session.GroupName,
+		&session.Status,
+		&session.StartTime,
+		&session.EndTime,
+		&session.ReviewItemCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+var sessionSortColumns = map[string]string{
+	"id":                 "ss.id",
+	"start_time":         "ss.created_at",
+	"activity_name":      "lower(sa.name)",
+	"group_name":         "lower(g.name)",
+	"review_items_count": "review_items_count",
+}
+
+// sessionWhere returns the WHERE clause selecting the sessions of filter.
+func sessionWhere(filter repository.SessionFilter) (string, []any) {
+	where := " WHERE 1 = 1"
+	var params []any
+	if filter.UserID != 0 {
+		where += " AND ss.user_id = ?"
+		params = append(params, filter.UserID)
+	}
+	if filter.GroupID != 0 {
+		where += " AND ss.group_id = ?"
+		params = append(params, filter.GroupID)
+	}
+	if filter.ActivityID != 0 {
+		where += " AND ss.study_activity_id = ?"
+		params = append(params, filter.ActivityID)
+	}
+	if !filter.From.IsZero() {
+		where += " AND ss.created_at >= ?"
+		params = append(params, formatTime(filter.From))
+	}
+	if !filter.To.IsZero() {
+		where += " AND ss.created_at < ?"
+		params = append(params, formatTime(filter.To))
+	}
+	return where, params
+}
+
+func (r *sessionRepo) List(ctx context.Context, filter repository.SessionFilter, opts repository.ListOptions) ([]models.StudySessionDetail, int, error) {
+	order, err := orderBy(opts, sessionSortColumns, "ss.created_at DESC, ss.id DESC")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	where, params := sessionWhere(filter)
+
+	var total int
+	err = r.s.q.QueryRowContext(ctx, "SELECT COUNT(*) FROM study_sessions ss"+where, params...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := sessionDetailSelect + where + sessionDetailGroupBy + `
+		ORDER BY ` + order + `
+		LIMIT ? OFFSET ?
+	`
+	rows, err := r.s.q.QueryContext(ctx, query, append(params, opts.Limit, opts.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	sessions := []models.StudySessionDetail{}
+	for rows.Next() {
+		session, err := scanSessionDetail(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		sessions = append(sessions, *session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return sessions, total, nil
+}
+
+func (r *sessionRepo) ListAfter(ctx context.Context, filter repository.SessionFilter, keyset repository.Keyset) ([]models.StudySessionDetail, error) {
+	where, params := sessionWhere(filter)
+	cond, args := after("ss.created_at", "ss.id", keyset.After, true)
+
+	query := sessionDetailSelect + where + cond + sessionDetailGroupBy + `
+		ORDER BY ss.created_at DESC, ss.id DESC
+		LIMIT ?
+	`
+	params = append(append(params, args...), keyset.Limit)
+	rows, err := r.s.q.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []models.StudySessionDetail{}
+	for rows.Next() {
+		session, err := scanSessionDetail(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *session)
+	}
+
+	return sessions, rows.Err()
+}
+
+func (r *sessionRepo) Get(ctx context.Context, id int64) (*models.StudySessionDetail, error) {
+	row := r.s.q.QueryRowContext(ctx, sessionDetailSelect+`
+		WHERE ss.id = ?
+	`+sessionDetailGroupBy, id)
+
+	session, err := scanSessionDetail(row)
+	if err == sql.ErrNoRows {
+		return nil, models.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (r *sessionRepo) Status(ctx context.Context, id int64) (string, error) {
+	var status string
+	err := r.s.q.QueryRowContext(ctx, "SELECT status FROM study_sessions WHERE id = ?", id).Scan(&status)
+	if err == sql.ErrNoRows {
+		return "", models.ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return status, nil
+}
+
+func (r *sessionRepo) Last(ctx context.Context, userID int64) (*models.LastStudySession, error) {
+	var session models.LastStudySession
+	err := r.s.q.QueryRowContext(ctx, `
+		SELECT
+			ss.id,
+			ss.group_id,
+			g.name,
+			ss.study_activity_id,
+			ss.created_at
+		FROM study_sessions ss
+		JOIN groups g ON g.id = ss.group_id
+		WHERE ss.user_id = ?
+		ORDER BY ss.created_at DESC
+		LIMIT 1
+	`, userID).Scan(
+		&session.ID,
+		&session.GroupID,
+		&session.GroupName,
+		&session.StudyActivityID,
+		&session.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, models.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (r *sessionRepo) Count(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := r.s.q.QueryRowContext(ctx, "SELECT COUNT(*) FROM study_sessions WHERE user_id = ?", userID).Scan(&count)
+	return count, err
+}
+
+func (r *sessionRepo) CountActiveGroups(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := r.s.q.QueryRowContext(ctx, "SELECT COUNT(DISTINCT group_id) FROM study_sessions WHERE user_id = ?", userID).Scan(&count)
+	return count, err
+}
+
+func (r *sessionRepo) StudyDays(ctx context.Context, userID int64) ([]time.Time, error) {
+	rows, err := r.s.q.QueryContext(ctx, `
+		SELECT DISTINCT `+r.s.dialect.day("created_at")+` as day
+		FROM study_sessions
+		WHERE user_id = ?
+		ORDER BY day DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	days := []time.Time{}
+	for rows.Next() {
+		var day string
+		if err := rows.Scan(&day); err != nil {
+			return nil, err
+		}
+
+		t, err := time.Parse("2006-01-02", day)
+		if err != nil {
+			return nil, err
+		}
+		days = append(days, t)
+	}
+
+	return days, rows.Err()
+}
+
+func (r *sessionRepo) Create(ctx context.Context, session *models.StudySession) error {
+	if session.Status == "" {
+		session.Status = models.SessionActive
+	}
+
+	err := r.s.q.QueryRowContext(ctx, `
+		INSERT INTO study_sessions (user_id, group_id, study_activity_id, status)
+		VALUES (?, ?, ?, ?)
+		RETURNING id
+	`, session.UserID, session.GroupID, session.StudyActivityID, session.Status).Scan(&session.ID)
+	if err != nil {
+		return err
+	}
+
+	return r.s.q.QueryRowContext(ctx, "SELECT created_at FROM study_sessions WHERE id = ?", session.ID).Scan(&session.CreatedAt)
+}
+
+func (r *sessionRepo) End(ctx context.Context, id int64, at time.Time) error {
+	result, err := r.s.q.ExecContext(ctx, `
+		UPDATE study_sessions
+		SET status = ?, ended_at = ?
+		WHERE id = ? AND status = ?
+	`, models.SessionCompleted, formatTime(at), id, models.SessionActive)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	if _, err := r.Status(ctx, id); err != nil {
+		return err
+	}
+	return models.ErrSessionClosed
+}
+
+func (r *sessionRepo) CloseIdle(ctx context.Context, cutoff time.Time) (int64, error) {
+	// A session's last activity is its last review, or its start if it has
+	// none. Abandoned sessions end at their last activity.
+	result, err := r.s.q.ExecContext(ctx, `
+		WITH last_activity AS (
+			SELECT
+				ss.id,
+				COALESCE(MAX(wri.created_at), ss.created_at) as at
+			FROM study_sessions ss
+			LEFT JOIN word_review_items wri ON wri.study_session_id = ss.id
+			WHERE ss.status = ?
+			GROUP BY ss.id
+		)
+		UPDATE study_sessions
+		SET
+			status = ?,
+			ended_at = (SELECT at FROM last_activity WHERE last_activity.id = study_sessions.id)
+		WHERE id IN (SELECT id FROM last_activity WHERE at < ?)
+	`, models.SessionActive, models.SessionAbandoned, formatTime(cutoff))
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (r *sessionRepo) DeleteByUser(ctx context.Context, userID int64) error {
+	_, err := r.s.q.ExecContext(ctx, "DELETE FROM study_sessions WHERE user_id = ?", userID)
+	return err
+}
+
+func (r *sessionRepo) DeleteAll(ctx context.Context) error {
+	_, err := r.s.q.ExecContext(ctx, "DELETE FROM study_sessions")
+	return err
+}
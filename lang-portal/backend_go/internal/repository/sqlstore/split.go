@@ -0,0 +1,115 @@
+package sqlstore
+
+import (
+	"regexp"
+	"strings"
+)
+
+var dollarTag = regexp.MustCompile(`^\$[A-Za-z_]*\$`)
+
+// splitStatements splits a SQL script into its statements. Semicolons only
+// end a statement outside of string literals, quoted identifiers, comments,
+// PostgreSQL dollar-quoted bodies and the BEGIN ... END body of a trigger.
+// Statements consisting of nothing but comments are dropped.
+func splitStatements(script string) []string {
+	var statements []string
+	start := 0
+	hasCode := false
+
+	// Trigger bodies are detected from the statement's leading keywords
+	var words []string
+	depth := 0
+
+	flush := func(end int) {
+		if hasCode {
+			statements = append(statements, strings.TrimSpace(script[start:end]))
+		}
+		start = end + 1
+		hasCode = false
+		words = words[:0]
+		depth = 0
+	}
+
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case c == '-' && strings.HasPrefix(script[i:], "--"):
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				end = len(script) - i
+			}
+			i += end
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				i = len(script)
+			} else {
+				i += end + 3
+			}
+		case c == '\'' || c == '"' || c == '`':
+			hasCode = true
+			// A doubled quote is an escaped quote and simply reopens
+			end := strings.IndexByte(script[i+1:], c)
+			if end < 0 {
+				i = len(script)
+			} else {
+				i += end + 1
+			}
+		case c == '$' && dollarTag.MatchString(script[i:]):
+			hasCode = true
+			tag := dollarTag.FindString(script[i:])
+			end := strings.Index(script[i+len(tag):], tag)
+			if end < 0 {
+				i = len(script)
+			} else {
+				i += len(tag) + end + len(tag) - 1
+			}
+		case isWordByte(c):
+			hasCode = true
+			j := i
+			for j < len(script) && isWordByte(script[j]) {
+				j++
+			}
+			word := strings.ToUpper(script[i:j])
+			i = j - 1
+
+			if len(words) < 4 {
+				words = append(words, word)
+			}
+			if isTrigger(words) {
+				switch word {
+				case "BEGIN", "CASE":
+					depth++
+				case "END":
+					depth--
+				}
+			}
+		case c == ';':
+			if depth <= 0 {
+				flush(i)
+			}
+		case c != ' ' && c != '\t' && c != '\n' && c != '\r':
+			hasCode = true
+		}
+	}
+	flush(len(script))
+
+	return statements
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z'
+}
+
+// isTrigger reports whether a statement starting with words creates a
+// SQLite trigger, e.g. CREATE TEMP TRIGGER.
+func isTrigger(words []string) bool {
+	switch {
+	case len(words) < 2 || words[0] != "CREATE":
+		return false
+	case words[1] == "TRIGGER":
+		return true
+	default:
+		return len(words) > 2 && (words[1] == "TEMP" || words[1] == "TEMPORARY") && words[2] == "TRIGGER"
+	}
+}
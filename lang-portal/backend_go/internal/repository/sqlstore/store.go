@@ -0,0 +1,163 @@
+// Package sqlstore implements the repository interfaces on top of
+// database/sql, for SQLite and PostgreSQL.
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"lang-portal/backend_go/internal/repository"
+)
+
+// querier is satisfied by both *sql.DB and *sql.Tx.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// rebinder rewrites queries for the dialect before running them.
+type rebinder struct {
+	q       querier
+	dialect Dialect
+}
+
+func (r rebinder) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return r.q.ExecContext(ctx, r.dialect.Rebind(query), args...)
+}
+
+func (r rebinder) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return r.q.QueryContext(ctx, r.dialect.Rebind(query), args...)
+}
+
+func (r rebinder) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return r.q.QueryRowContext(ctx, r.dialect.Rebind(query), args...)
+}
+
+// Store is a repository.Store backed by a SQL database.
+type Store struct {
+	db      *sql.DB
+	tx      *sql.Tx
+	q       querier
+	dialect Dialect
+}
+
+func New(db *sql.DB, dialect Dialect) *Store {
+	return &Store{db: db, q: rebinder{db, dialect}, dialect: dialect}
+}
+
+// Open connects to the database for the named dialect.
+func Open(driver, dsn string) (*sql.DB, *Store, error) {
+	dialect, err := DialectFor(driver)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db, err := sql.Open(dialect.Driver, dsn)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return db, New(db, dialect), nil
+}
+
+func (s *Store) Words() repository.WordRepo          { return &wordRepo{s} }
+func (s *Store) Groups() repository.GroupRepo        { return &groupRepo{s} }
+func (s *Store) Kanji() repository.KanjiRepo         { return &kanjiRepo{s} }
+func (s *Store) Sessions() repository.SessionRepo    { return &sessionRepo{s} }
+func (s *Store) Reviews() repository.ReviewRepo      { return &reviewRepo{s} }
+func (s *Store) Activities() repository.ActivityRepo { return &activityRepo{s} }
+func (s *Store) Users() repository.UserRepo          { return &userRepo{s} }
+func (s *Store) Tokens() repository.TokenRepo        { return &tokenRepo{s} }
+func (s *Store) XAPI() repository.XAPIRepo           { return &xapiRepo{s} }
+
+func (s *Store) WithTx(ctx context.Context, fn func(tx repository.Store) error) error {
+	// Already inside a transaction, join it
+	if s.tx != nil {
+		return fn(s)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(&Store{db: s.db, tx: tx, q: rebinder{tx, s.dialect}, dialect: s.dialect}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// inTx runs fn against a transaction, reusing the current one if any, for
+// repository methods that need several statements to be atomic.
+func (s *Store) inTx(ctx context.Context, fn func(q querier) error) error {
+	return s.WithTx(ctx, func(tx repository.Store) error {
+		return fn(tx.(*Store).q)
+	})
+}
+
+// timeFormat matches SQLite's CURRENT_TIMESTAMP so that stored times compare
+// correctly as strings. PostgreSQL parses it as a timestamp.
+const timeFormat = "2006-01-02 15:04:05"
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeFormat)
+}
+
+// keysetTimeFormat extends timeFormat with the microseconds PostgreSQL
+// keeps. It compares with SQLite's whole-second times as strings in time
+// order.
+const keysetTimeFormat = "2006-01-02 15:04:05.999999"
+
+// after returns the condition, starting with AND, that selects the rows
+// following cursor in a listing ordered by the time column at, then by the
+// id column, newest first when desc.
+func after(at, id string, cursor repository.Cursor, desc bool) (string, []any) {
+	if cursor.IsZero() {
+		return "", nil
+	}
+
+	op := ">"
+	if desc {
+		op = "<"
+	}
+	t := cursor.Time.UTC().Format(keysetTimeFormat)
+	return " AND (" + at + " " + op + " ? OR " + at + " = ? AND " + id + " " + op + " ?)",
+		[]any{t, t, cursor.ID}
+}
+
+// affectedOrNotFound turns a zero-row result into notFound.
+func affectedOrNotFound(result sql.Result, notFound error) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// orderBy returns the ORDER BY list for opts. columns maps the listing's
+// sort fields, including "id", to SQL expressions; fallback is the
+// listing's default order.
+func orderBy(opts repository.ListOptions, columns map[string]string, fallback string) (string, error) {
+	if opts.Sort == "" {
+		return fallback, nil
+	}
+	column, ok := columns[opts.Sort]
+	if !ok {
+		return "", fmt.Errorf("cannot sort by %q", opts.Sort)
+	}
+
+	// NULL sorts first in SQLite and last in PostgreSQL unless told
+	dir, nulls := " ASC", " NULLS FIRST"
+	if opts.Desc {
+		dir, nulls = " DESC", " NULLS LAST"
+	}
+	return column + dir + nulls + ", " + columns["id"] + dir, nil
+}
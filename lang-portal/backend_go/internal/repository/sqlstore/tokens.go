@@ -0,0 +1,54 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"lang-portal/backend_go/internal/models"
+)
+
+type tokenRepo struct {
+	s *Store
+}
+
+func (r *tokenRepo) Create(ctx context.Context, token *models.AuthToken) error {
+	_, err := r.s.q.ExecContext(ctx, `
+		INSERT INTO auth_tokens (hash, user_id, created_at, expires_at)
+		VALUES (?, ?, ?, ?)
+	`, token.Hash, token.UserID, formatTime(token.CreatedAt), formatTime(token.ExpiresAt))
+	return err
+}
+
+func (r *tokenRepo) Get(ctx context.Context, hash string) (*models.AuthToken, error) {
+	var token models.AuthToken
+	err := r.s.q.QueryRowContext(ctx, `
+		SELECT hash, user_id, created_at, expires_at
+		FROM auth_tokens
+		WHERE hash = ?
+	`, hash).Scan(&token.Hash, &token.UserID, &token.CreatedAt, &token.ExpiresAt)
+
+	if err == sql.ErrNoRows {
+		return nil, models.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (r *tokenRepo) Delete(ctx context.Context, hash string) error {
+	_, err := r.s.q.ExecContext(ctx, "DELETE FROM auth_tokens WHERE hash = ?", hash)
+	return err
+}
+
+func (r *tokenRepo) DeleteUser(ctx context.Context, userID int64) error {
+	_, err := r.s.q.ExecContext(ctx, "DELETE FROM auth_tokens WHERE user_id = ?", userID)
+	return err
+}
+
+func (r *tokenRepo) DeleteExpired(ctx context.Context, now time.Time) error {
+	_, err := r.s.q.ExecContext(ctx, "DELETE FROM auth_tokens WHERE expires_at <= ?", formatTime(now))
+	return err
+}
@@ -0,0 +1,100 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	"lang-portal/backend_go/internal/models"
+)
+
+type userRepo struct {
+	s *Store
+}
+
+const userSelect = "SELECT id, name, role, password_hash, created_at FROM users"
+
+func scanUser(row rowScanner) (*models.User, error) {
+	var user models.User
+	err := row.Scan(&user.ID, &user.Name, &user.Role, &user.PasswordHash, &user.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepo) List(ctx context.Context) ([]models.User, error) {
+	rows, err := r.s.q.QueryContext(ctx, userSelect+" ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *user)
+	}
+
+	return users, rows.Err()
+}
+
+func (r *userRepo) Get(ctx context.Context, id int64) (*models.User, error) {
+	return r.find(ctx, " WHERE id = ?", id)
+}
+
+func (r *userRepo) FindByName(ctx context.Context, name string) (*models.User, error) {
+	return r.find(ctx, " WHERE name = ?", name)
+}
+
+func (r *userRepo) find(ctx context.Context, where string, args ...any) (*models.User, error) {
+	user, err := scanUser(r.s.q.QueryRowContext(ctx, userSelect+where, args...))
+	if err == sql.ErrNoRows {
+		return nil, models.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (r *userRepo) Create(ctx context.Context, user *models.User) error {
+	if user.Role == "" {
+		user.Role = models.RoleStudent
+	}
+
+	err := r.s.q.QueryRowContext(ctx, `
+		INSERT INTO users (name, role, password_hash)
+		VALUES (?, ?, ?)
+		RETURNING id
+	`, user.Name, user.Role, user.PasswordHash).Scan(&user.ID)
+	if r.s.dialect.isUniqueViolation(err) {
+		return models.ErrDuplicateUserName
+	}
+	if err != nil {
+		return err
+	}
+
+	return r.s.q.QueryRowContext(ctx, "SELECT created_at FROM users WHERE id = ?", user.ID).Scan(&user.CreatedAt)
+}
+
+func (r *userRepo) SetPassword(ctx context.Context, id int64, hash string) error {
+	result, err := r.s.q.ExecContext(ctx, "UPDATE users SET password_hash = ? WHERE id = ?", hash, id)
+	if err != nil {
+		return err
+	}
+
+	return affectedOrNotFound(result, models.ErrNotFound)
+}
+
+func (r *userRepo) SetRole(ctx context.Context, id int64, role string) error {
+	result, err := r.s.q.ExecContext(ctx, "UPDATE users SET role = ? WHERE id = ?", role, id)
+	if err != nil {
+		return err
+	}
+
+	return affectedOrNotFound(result, models.ErrNotFound)
+}
@@ -0,0 +1,348 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+	"lang-portal/backend_go/internal/textnorm"
+)
+
+type wordRepo struct {
+	s *Store
+}
+
+// wordSortColumns maps repository.WordSorts to the columns of List and
+// groupRepo.Words.
+var wordSortColumns = map[string]string{
+	"id":               "w.id",
+	"japanese":         "w.japanese",
+	"romaji":           "lower(w.romaji)",
+	"english":          "lower(w.english)",
+	"correct_count":    "correct_count",
+	"wrong_count":      "wrong_count",
+	"last_reviewed_at": "st.last_reviewed_at",
+}
+
+// wordStatsJoin joins the word_stats st and word_schedules ws of the words w
+// for a user, whose ID it takes twice.
+const wordStatsJoin = `
+	LEFT JOIN word_stats st ON st.word_id = w.id AND st.user_id = ?
+	LEFT JOIN word_schedules ws ON ws.word_id = w.id AND ws.user_id = ?
+`
+
+// wordStatsColumns selects the statistics of the words w from the tables
+// of wordStatsJoin, to be read with scanWordStats.
+const wordStatsColumns = `
+	COALESCE(st.correct_count, 0) as correct_count,
+	COALESCE(st.wrong_count, 0) as wrong_count,
+	st.last_reviewed_at,
+	COALESCE(ws.interval_days, 0) as interval_days
+`
+
+// scanWordStats scans a row into dest followed by the wordStatsColumns of
+// stats.
+func scanWordStats(row rowScanner, stats *models.WordStats, dest ...any) error {
+	var interval int
+	err := row.Scan(append(dest, &stats.CorrectCount, &stats.WrongCount, &stats.LastReviewedAt, &interval)...)
+	stats.Mastery = models.Mastery(stats.CorrectCount+stats.WrongCount, interval)
+	return err
+}
+
+func (r *wordRepo) List(ctx context.Context, userID int64, query string, opts repository.ListOptions) ([]models.WordSummary, int, error) {
+	from := "words w"
+	where := ""
+	order := "w.id"
+	var params, orderParams []any
+
+	// Search the index of folded keys, best matches first
+	if query != "" {
+		terms := textnorm.Terms(query)
+		if len(terms) == 0 {
+			return []models.WordSummary{}, 0, nil
+		}
+		from, where, params = r.s.dialect.matchWords(terms)
+
+		var rank string
+		rank, orderParams = rankWords(textnorm.NewTerm(query))
+		order = rank + ", w.id"
+	}
+
+	if opts.Sort != "" {
+		var err error
+		if order, err = orderBy(opts, wordSortColumns, order); err != nil {
+			return nil, 0, err
+		}
+		orderParams = nil
+	}
+
+	var total int
+	if err := r.s.q.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+from+where, params...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	selectQuery := `
+		SELECT w.id, w.japanese, w.romaji, w.english, w.parts,` + wordStatsColumns + `
+		FROM ` + from + wordStatsJoin + where + `
+		ORDER BY ` + order + `
+		LIMIT ? OFFSET ?
+	`
+	params = append([]any{userID, userID}, params...)
+	params = append(append(params, orderParams...), opts.Limit, opts.Offset)
+
+	rows, err := r.s.q.QueryContext(ctx, selectQuery, params...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	words := []models.WordSummary{}
+	for rows.Next() {
+		word := models.WordSummary{WordStats: models.WordStats{UserID: userID}}
+		err := scanWordStats(rows, &word.WordStats, &word.ID, &word.Japanese, &word.Romaji, &word.English, &word.Parts)
+		word.WordID = word.ID
+		if err != nil {
+			return nil, 0, err
+		}
+		words = append(words, word)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return words, total, nil
+}
+
+func (r *wordRepo) Get(ctx context.Context, id int64) (*models.Word, error) {
+	var word models.Word
+	err := r.s.q.QueryRowContext(ctx, `
+		SELECT id, japanese, romaji, english, parts
+		FROM words
+		WHERE id = ?
+	`, id).Scan(&word.ID, &word.Japanese, &word.Romaji, &word.English, &word.Parts)
+
+	if err == sql.ErrNoRows {
+		return nil, models.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &word, nil
+}
+
+func (r *wordRepo) FindByText(ctx context.Context, japanese, english string) (*models.Word, error) {
+	var word models.Word
+	err := r.s.q.QueryRowContext(ctx, `
+		SELECT id, japanese, romaji, english, parts
+		FROM words
+		WHERE japanese = ? AND LOWER(english) = LOWER(?)
+		ORDER BY id
+		LIMIT 1
+	`, japanese, english).Scan(&word.ID, &word.Japanese, &word.Romaji, &word.English, &word.Parts)
+
+	if err == sql.ErrNoRows {
+		return nil, models.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &word, nil
+}
+
+func (r *wordRepo) MissingIDs(ctx context.Context, ids []int64) ([]int64, error) {
+	missing := []int64{}
+	for _, id := range ids {
+		var exists bool
+		err := r.s.q.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM words WHERE id = ?)", id).Scan(&exists)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			missing = append(missing, id)
+		}
+	}
+
+	return missing, nil
+}
+
+func (r *wordRepo) Count(ctx context.Context) (int, error) {
+	var count int
+	err := r.s.q.QueryRowContext(ctx, "SELECT COUNT(*) FROM words").Scan(&count)
+	return count, err
+}
+
+func (r *wordRepo) Stats(ctx context.Context, userID, id int64) (*models.WordStats, error) {
+	stats := models.WordStats{UserID: userID, WordID: id}
+	row := r.s.q.QueryRowContext(ctx, `
+		SELECT`+wordStatsColumns+`
+		FROM words w`+wordStatsJoin+`
+		WHERE w.id = ?
+	`, userID, userID, id)
+
+	err := scanWordStats(row, &stats)
+	if err == sql.ErrNoRows {
+		return nil, models.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
+func (r *wordRepo) Groups(ctx context.Context, id int64) ([]models.Group, error) {
+	rows, err := r.s.q.QueryContext(ctx, `
+		SELECT g.id, g.name
+		FROM groups g
+		JOIN word_groups wg ON wg.group_id = g.id
+		WHERE wg.word_id = ?
+		ORDER BY g.id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []models.Group{}
+	for rows.Next() {
+		var group models.Group
+		if err := rows.Scan(&group.ID, &group.Name); err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+
+	return groups, rows.Err()
+}
+
+func (r *wordRepo) Export(ctx context.Context, userID, groupID int64, withStats bool) ([]models.ExportWord, error) {
+	query := `
+		SELECT w.id, w.japanese, w.romaji, w.english, w.parts,` + wordStatsColumns + `
+		FROM words w` + wordStatsJoin
+	params := []any{userID, userID}
+	if groupID != 0 {
+		query += " WHERE w.id IN (SELECT word_id FROM word_groups WHERE group_id = ?)"
+		params = append(params, groupID)
+	}
+	query += " ORDER BY w.id"
+
+	rows, err := r.s.q.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	words := []models.ExportWord{}
+	index := map[int64]int{}
+	for rows.Next() {
+		var word models.ExportWord
+		stats := models.WordStats{UserID: userID}
+		err := scanWordStats(rows, &stats, &word.ID, &word.Japanese, &word.Romaji, &word.English, &word.Parts)
+		stats.WordID = word.ID
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		word.Groups = []string{}
+		if withStats {
+			word.Stats = &stats
+		}
+		index[word.ID] = len(words)
+		words = append(words, word)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, err
+	}
+	rows.Close()
+
+	// Attach group names in a second pass
+	rows, err = r.s.q.QueryContext(ctx, `
+		SELECT wg.word_id, g.name
+		FROM word_groups wg
+		JOIN groups g ON g.id = wg.group_id
+		ORDER BY g.name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var wordID int64
+		var name string
+		if err := rows.Scan(&wordID, &name); err != nil {
+			return nil, err
+		}
+		if i, ok := index[wordID]; ok {
+			words[i].Groups = append(words[i].Groups, name)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return words, nil
+}
+
+func (r *wordRepo) Create(ctx context.Context, word *models.Word) error {
+	return r.s.q.QueryRowContext(ctx, `
+		INSERT INTO words (japanese, romaji, english, parts)
+		VALUES (?, ?, ?, ?)
+		RETURNING id
+	`, word.Japanese, word.Romaji, word.English, word.Parts).Scan(&word.ID)
+}
+
+func (r *wordRepo) Update(ctx context.Context, word *models.Word) error {
+	result, err := r.s.q.ExecContext(ctx, `
+		UPDATE words
+		SET japanese = ?, romaji = ?, english = ?, parts = ?
+		WHERE id = ?
+	`, word.Japanese, word.Romaji, word.English, word.Parts, word.ID)
+
+	if err != nil {
+		return err
+	}
+
+	return affectedOrNotFound(result, models.ErrNotFound)
+}
+
+func (r *wordRepo) Delete(ctx context.Context, id int64) error {
+	return r.s.inTx(ctx, func(q querier) error {
+		// Clean up dependent rows explicitly, foreign key enforcement is off
+		// unless the connection enables it
+		dependents := []string{
+			"DELETE FROM word_groups WHERE word_id = ?",
+			"DELETE FROM word_kanji WHERE word_id = ?",
+			"DELETE FROM word_review_items WHERE word_id = ?",
+			"DELETE FROM word_schedules WHERE word_id = ?",
+		}
+		for _, stmt := range dependents {
+			if _, err := q.ExecContext(ctx, stmt, id); err != nil {
+				return err
+			}
+		}
+
+		result, err := q.ExecContext(ctx, "DELETE FROM words WHERE id = ?", id)
+		if err != nil {
+			return err
+		}
+
+		return affectedOrNotFound(result, models.ErrNotFound)
+	})
+}
+
+func (r *wordRepo) DeleteAll(ctx context.Context) error {
+	return r.s.inTx(ctx, func(q querier) error {
+		if _, err := q.ExecContext(ctx, "DELETE FROM word_kanji"); err != nil {
+			return err
+		}
+		_, err := q.ExecContext(ctx, "DELETE FROM words")
+		return err
+	})
+}
@@ -0,0 +1,258 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+)
+
+type xapiRepo struct {
+	s *Store
+}
+
+const statementColumns = `
+	id, statement_id, user_id, actor_key, verb_id, object_id, registration, voided, stored, statement
+`
+
+func scanStatement(row rowScanner, statement *models.StoredStatement) error {
+	return row.Scan(
+		&statement.ID,
+		&statement.StatementID,
+		&statement.UserID,
+		&statement.ActorKey,
+		&statement.VerbID,
+		&statement.ObjectID,
+		&statement.Registration,
+		&statement.Voided,
+		&statement.Stored,
+		&statement.Statement,
+	)
+}
+
+// CreateStatement keeps the stored time to the microsecond, so that the
+// statements sent in one second stay in order.
+func (r *xapiRepo) CreateStatement(ctx context.Context, statement *models.StoredStatement) error {
+	err := r.s.q.QueryRowContext(ctx, `
+		INSERT INTO xapi_statements (statement_id, user_id, actor_key, verb_id, object_id, registration, voided, stored, statement)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`,
+		statement.StatementID,
+		statement.UserID,
+		statement.ActorKey,
+		statement.VerbID,
+		statement.ObjectID,
+		statement.Registration,
+		statement.Voided,
+		statement.Stored.UTC().Format(keysetTimeFormat),
+		statement.Statement,
+	).Scan(&statement.ID)
+	if r.s.dialect.isUniqueViolation(err) {
+		return models.ErrDuplicateStatement
+	}
+	return err
+}
+
+func (r *xapiRepo) GetStatement(ctx context.Context, userID int64, statementID string) (*models.StoredStatement, error) {
+	var statement models.StoredStatement
+	err := scanStatement(r.s.q.QueryRowContext(ctx, `
+		SELECT`+statementColumns+`
+		FROM xapi_statements
+		WHERE user_id = ? AND statement_id = ?
+	`, userID, statementID), &statement)
+
+	if err == sql.ErrNoRows {
+		return nil, models.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &statement, nil
+}
+
+func (r *xapiRepo) ListStatements(ctx context.Context, filter repository.StatementFilter, keyset repository.Keyset) ([]models.StoredStatement, error) {
+	query := `
+		SELECT` + statementColumns + `
+		FROM xapi_statements
+		WHERE user_id = ? AND NOT voided
+	`
+	params := []any{filter.UserID}
+	for _, match := range []struct{ column, value string }{
+		{"actor_key", filter.ActorKey},
+		{"verb_id", filter.VerbID},
+		{"object_id", filter.ObjectID},
+		{"registration", filter.Registration},
+	} {
+		if match.value != "" {
+			query += " AND " + match.column + " = ?"
+			params = append(params, match.value)
+		}
+	}
+	if !filter.Since.IsZero() {
+		query += " AND stored > ?"
+		params = append(params, filter.Since.UTC().Format(keysetTimeFormat))
+	}
+	if !filter.Until.IsZero() {
+		query += " AND stored <= ?"
+		params = append(params, filter.Until.UTC().Format(keysetTimeFormat))
+	}
+
+	cond, args := after("stored", "id", keyset.After, !filter.Ascending)
+	query += cond
+	params = append(params, args...)
+
+	if filter.Ascending {
+		query += " ORDER BY stored, id"
+	} else {
+		query += " ORDER BY stored DESC, id DESC"
+	}
+	query += " LIMIT ?"
+	params = append(params, keyset.Limit)
+
+	rows, err := r.s.q.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	statements := []models.StoredStatement{}
+	for rows.Next() {
+		var statement models.StoredStatement
+		if err := scanStatement(rows, &statement); err != nil {
+			return nil, err
+		}
+		statements = append(statements, statement)
+	}
+
+	return statements, rows.Err()
+}
+
+func (r *xapiRepo) VoidStatement(ctx context.Context, userID int64, statementID string) error {
+	result, err := r.s.q.ExecContext(ctx, `
+		UPDATE xapi_statements SET voided = TRUE
+		WHERE user_id = ? AND statement_id = ?
+	`, userID, statementID)
+	if err != nil {
+		return err
+	}
+
+	return affectedOrNotFound(result, models.ErrNotFound)
+}
+
+func (r *xapiRepo) GetState(ctx context.Context, key repository.StateKey) (*models.XAPIState, error) {
+	var state models.XAPIState
+	err := r.s.q.QueryRowContext(ctx, `
+		SELECT user_id, activity_id, agent_key, registration, state_id, content_type, document, updated_at
+		FROM xapi_states
+		WHERE user_id = ? AND activity_id = ? AND agent_key = ? AND registration = ? AND state_id = ?
+	`, key.UserID, key.ActivityID, key.AgentKey, key.Registration, key.StateID).Scan(
+		&state.UserID,
+		&state.ActivityID,
+		&state.AgentKey,
+		&state.Registration,
+		&state.StateID,
+		&state.ContentType,
+		&state.Document,
+		&state.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, models.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+func (r *xapiRepo) StateIDs(ctx context.Context, key repository.StateKey, since time.Time) ([]string, error) {
+	query := `
+		SELECT state_id
+		FROM xapi_states
+		WHERE user_id = ? AND activity_id = ? AND agent_key = ? AND registration = ?
+	`
+	params := []any{key.UserID, key.ActivityID, key.AgentKey, key.Registration}
+	if !since.IsZero() {
+		query += " AND updated_at > ?"
+		params = append(params, since.UTC().Format(keysetTimeFormat))
+	}
+	query += " ORDER BY state_id"
+
+	rows, err := r.s.q.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func (r *xapiRepo) SaveState(ctx context.Context, state *models.XAPIState) error {
+	_, err := r.s.q.ExecContext(ctx, `
+		INSERT INTO xapi_states (user_id, activity_id, agent_key, registration, state_id, content_type, document, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id, activity_id, agent_key, registration, state_id) DO UPDATE SET
+			content_type = excluded.content_type,
+			document = excluded.document,
+			updated_at = excluded.updated_at
+	`,
+		state.UserID,
+		state.ActivityID,
+		state.AgentKey,
+		state.Registration,
+		state.StateID,
+		state.ContentType,
+		state.Document,
+		state.UpdatedAt.UTC().Format(keysetTimeFormat),
+	)
+	return err
+}
+
+func (r *xapiRepo) DeleteState(ctx context.Context, key repository.StateKey) error {
+	query := `
+		DELETE FROM xapi_states
+		WHERE user_id = ? AND activity_id = ? AND agent_key = ? AND registration = ?
+	`
+	params := []any{key.UserID, key.ActivityID, key.AgentKey, key.Registration}
+	if key.StateID != "" {
+		query += " AND state_id = ?"
+		params = append(params, key.StateID)
+	}
+
+	_, err := r.s.q.ExecContext(ctx, query, params...)
+	return err
+}
+
+func (r *xapiRepo) DeleteByUser(ctx context.Context, userID int64) error {
+	return r.s.inTx(ctx, func(q querier) error {
+		if _, err := q.ExecContext(ctx, "DELETE FROM xapi_states WHERE user_id = ?", userID); err != nil {
+			return err
+		}
+		_, err := q.ExecContext(ctx, "DELETE FROM xapi_statements WHERE user_id = ?", userID)
+		return err
+	})
+}
+
+func (r *xapiRepo) DeleteAll(ctx context.Context) error {
+	return r.s.inTx(ctx, func(q querier) error {
+		if _, err := q.ExecContext(ctx, "DELETE FROM xapi_states"); err != nil {
+			return err
+		}
+		_, err := q.ExecContext(ctx, "DELETE FROM xapi_statements")
+		return err
+	})
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// DefaultTokenTTL is how long issued tokens stay valid.
+const DefaultTokenTTL = 7 * 24 * time.Hour
+
+var (
+	ErrInvalidCredentials = errors.New("invalid user name or password")
+	ErrInvalidToken       = errors.New("invalid or expired token")
+)
+
+// passwordCost is the bcrypt cost of new password hashes.
+var passwordCost = bcrypt.DefaultCost
+
+type AuthService struct {
+	store repository.Store
+
+	// TokenTTL is how long issued tokens stay valid.
+	TokenTTL time.Duration
+	// LaunchKey signs launch tokens, and LaunchTokenTTL is how long they
+	// stay valid.
+	LaunchKey      []byte
+	LaunchTokenTTL time.Duration
+}
+
+// Login is the result of a successful login. Token is only ever shown to the
+// client, the store keeps a hash of it.
+type Login struct {
+	Token     string
+	ExpiresAt time.Time
+	User      *models.User
+}
+
+// Login checks a user's password and issues a bearer token.
+func (s *AuthService) Login(ctx context.Context, name, password string, now time.Time) (*Login, error) {
+	user, err := s.store.Users().FindByName(ctx, name)
+	if err == models.ErrNotFound {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Accounts without a password cannot log in
+	if user.PasswordHash == "" {
+		return nil, ErrInvalidCredentials
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	secret := base64.RawURLEncoding.EncodeToString(raw)
+
+	token := models.AuthToken{
+		Hash:      hashToken(secret),
+		UserID:    user.ID,
+		CreatedAt: now.UTC(),
+		ExpiresAt: now.UTC().Add(s.TokenTTL),
+	}
+	err = s.store.WithTx(ctx, func(tx repository.Store) error {
+		if err := tx.Tokens().DeleteExpired(ctx, now); err != nil {
+			return err
+		}
+		return tx.Tokens().Create(ctx, &token)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Login{Token: secret, ExpiresAt: token.ExpiresAt, User: user}, nil
+}
+
+// Authenticate returns the user a bearer token was issued to.
+func (s *AuthService) Authenticate(ctx context.Context, secret string, now time.Time) (*models.User, error) {
+	token, err := s.store.Tokens().Get(ctx, hashToken(secret))
+	if err == models.ErrNotFound {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !token.ExpiresAt.After(now) {
+		return nil, ErrInvalidToken
+	}
+
+	user, err := s.store.Users().Get(ctx, token.UserID)
+	if err == models.ErrNotFound {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// Logout revokes a bearer token.
+func (s *AuthService) Logout(ctx context.Context, secret string) error {
+	return s.store.Tokens().Delete(ctx, hashToken(secret))
+}
+
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func hashPassword(password string) (string, error) {
+	if len(password) < 8 {
+		return "", invalid("Password must be at least 8 characters")
+	}
+	// bcrypt ignores everything past 72 bytes
+	if len(password) > 72 {
+		return "", invalid("Password must be at most 72 bytes")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), passwordCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
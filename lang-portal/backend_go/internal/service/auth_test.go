@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"lang-portal/backend_go/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthServiceLogin(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	user, err := f.svc.Users.Create(ctx, "hana", "correct horse", models.RoleTeacher)
+	require.NoError(t, err)
+
+	_, err = f.svc.Auth.Login(ctx, "hana", "wrong horse", f.start)
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	_, err = f.svc.Auth.Login(ctx, "nobody", "correct horse", f.start)
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	// The default user has no password until one is set
+	_, err = f.svc.Auth.Login(ctx, "default", "", f.start)
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	login, err := f.svc.Auth.Login(ctx, "hana", "correct horse", f.start)
+	require.NoError(t, err)
+	assert.NotEmpty(t, login.Token)
+	assert.Equal(t, f.start.Add(DefaultTokenTTL), login.ExpiresAt)
+
+	got, err := f.svc.Auth.Authenticate(ctx, login.Token, f.start.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, got.ID)
+	assert.True(t, got.HasRole(models.RoleTeacher))
+	assert.False(t, got.HasRole(models.RoleAdmin))
+
+	_, err = f.svc.Auth.Authenticate(ctx, login.Token, login.ExpiresAt)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = f.svc.Auth.Authenticate(ctx, "forged", f.start)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	require.NoError(t, f.svc.Auth.Logout(ctx, login.Token))
+	_, err = f.svc.Auth.Authenticate(ctx, login.Token, f.start)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestUserServiceSetPasswordRevokesTokens(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	require.NoError(t, f.svc.Users.SetPassword(ctx, f.user, "correct horse"))
+	login, err := f.svc.Auth.Login(ctx, "default", "correct horse", f.start)
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleAdmin, login.User.Role)
+
+	require.NoError(t, f.svc.Users.SetPassword(ctx, f.user, "battery staple"))
+	_, err = f.svc.Auth.Authenticate(ctx, login.Token, f.start)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	assert.ErrorIs(t, f.svc.Users.SetPassword(ctx, 999, "battery staple"), ErrUserNotFound)
+}
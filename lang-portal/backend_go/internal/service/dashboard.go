@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+)
+
+type DashboardService struct {
+	store repository.Store
+}
+
+// LastSession returns the user's most recent study session, or
+// ErrSessionNotFound before they have studied anything.
+func (s *DashboardService) LastSession(ctx context.Context, userID int64) (*models.LastStudySession, error) {
+	session, err := s.store.Sessions().Last(ctx, userID)
+	if err != nil {
+		return nil, notFound(err, ErrSessionNotFound)
+	}
+
+	return session, nil
+}
+
+// Progress compares the number of words the user has studied with the size
+// of the library.
+func (s *DashboardService) Progress(ctx context.Context, userID int64) (*models.StudyProgress, error) {
+	studied, err := s.store.Reviews().CountStudiedWords(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := s.store.Words().Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.StudyProgress{
+		TotalWordsStudied:   studied,
+		TotalAvailableWords: total,
+	}, nil
+}
+
+// QuickStats summarizes the user's study history.
+func (s *DashboardService) QuickStats(ctx context.Context, userID int64) (*models.QuickStats, error) {
+	var stats models.QuickStats
+
+	correct, total, err := s.store.Reviews().Totals(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if total > 0 {
+		stats.SuccessRate = float64(correct) / float64(total) * 100
+	}
+
+	if stats.TotalStudySessions, err = s.store.Sessions().Count(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if stats.TotalActiveGroups, err = s.store.Sessions().CountActiveGroups(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	days, err := s.store.Sessions().StudyDays(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	stats.StudyStreakDays = streak(days)
+
+	return &stats, nil
+}
+
+// streak counts the consecutive days ending with the most recent one. days
+// must be distinct and sorted most recent first.
+func streak(days []time.Time) int {
+	if len(days) == 0 {
+		return 0
+	}
+
+	count := 1
+	for i := 1; i < len(days); i++ {
+		if !days[i].Equal(days[i-1].AddDate(0, 0, -1)) {
+			break
+		}
+		count++
+	}
+
+	return count
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDashboardQuickStats(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	_, _, err := f.svc.Reviews.Record(ctx, f.user, f.session.ID, f.words[0].ID, Answer{Correct: true}, f.start)
+	assert.NoError(t, err)
+	_, _, err = f.svc.Reviews.Record(ctx, f.user, f.session.ID, f.words[1].ID, Answer{Correct: false}, f.start)
+	assert.NoError(t, err)
+
+	// Study on the two days before the fixture session, then skip a day
+	for _, daysAgo := range []int{1, 2, 4} {
+		f.store.Now = func() time.Time { return f.start.AddDate(0, 0, -daysAgo) }
+		_, err := f.svc.Sessions.Start(ctx, f.user, f.group.ID, f.activity.ID)
+		assert.NoError(t, err)
+	}
+
+	stats, err := f.svc.Dashboard.QuickStats(ctx, f.user)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(50), stats.SuccessRate)
+	assert.Equal(t, 4, stats.TotalStudySessions)
+	assert.Equal(t, 1, stats.TotalActiveGroups)
+	assert.Equal(t, 3, stats.StudyStreakDays)
+
+	last, err := f.svc.Dashboard.LastSession(ctx, f.user)
+	assert.NoError(t, err)
+	assert.Equal(t, f.session.ID, last.ID)
+}
+
+func TestDashboardEmpty(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	assert.NoError(t, f.svc.Settings.FullReset(ctx))
+
+	stats, err := f.svc.Dashboard.QuickStats(ctx, f.user)
+	assert.NoError(t, err)
+	assert.Zero(t, stats.SuccessRate)
+	assert.Zero(t, stats.StudyStreakDays)
+
+	_, err = f.svc.Dashboard.LastSession(ctx, f.user)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+}
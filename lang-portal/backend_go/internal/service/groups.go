@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+)
+
+type GroupService struct {
+	store repository.Store
+}
+
+// MissingWordsError is returned when adding words that do not exist to a
+// group.
+type MissingWordsError struct {
+	IDs []int64
+}
+
+func (e *MissingWordsError) Error() string {
+	return fmt.Sprintf("words not found: %v", e.IDs)
+}
+
+func (s *GroupService) List(ctx context.Context, opts repository.ListOptions) ([]models.GroupSummary, int, error) {
+	return s.store.Groups().List(ctx, opts)
+}
+
+func (s *GroupService) Get(ctx context.Context, id int64) (*models.Group, *models.GroupStats, error) {
+	group, err := s.store.Groups().Get(ctx, id)
+	if err != nil {
+		return nil, nil, notFound(err, ErrGroupNotFound)
+	}
+
+	stats, err := s.store.Groups().Stats(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return group, stats, nil
+}
+
+// Words returns a page of the group's words with the user's review counts.
+func (s *GroupService) Words(ctx context.Context, userID, id int64, opts repository.ListOptions) ([]models.GroupWord, int, error) {
+	return s.store.Groups().Words(ctx, userID, id, opts)
+}
+
+// Sessions returns a page of the user's sessions for the group, started
+// within the period of filter.
+func (s *GroupService) Sessions(ctx context.Context, userID, id int64, filter repository.SessionFilter, opts repository.ListOptions) ([]models.StudySessionDetail, int, error) {
+	filter.UserID, filter.GroupID, filter.ActivityID = userID, id, 0
+	return s.store.Sessions().List(ctx, filter, opts)
+}
+
+// Create adds a group. It returns models.ErrDuplicateGroupName if the name is
+// already taken.
+func (s *GroupService) Create(ctx context.Context, name string) (*models.Group, error) {
+	name, err := groupName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	group := models.Group{Name: name}
+	if err := s.store.Groups().Create(ctx, &group); err != nil {
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+func (s *GroupService) Rename(ctx context.Context, id int64, name string) (*models.Group, error) {
+	name, err := groupName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Groups().Rename(ctx, id, name); err != nil {
+		return nil, notFound(err, ErrGroupNotFound)
+	}
+
+	return &models.Group{ID: id, Name: name}, nil
+}
+
+// Delete removes a group along with its word memberships and the study
+// sessions recorded against it. Words themselves are kept.
+func (s *GroupService) Delete(ctx context.Context, id int64) error {
+	return notFound(s.store.Groups().Delete(ctx, id), ErrGroupNotFound)
+}
+
+// AddWords links existing words to a group, ignoring words that are already
+// members. It returns the number of new memberships, or a
+// *MissingWordsError if any of the words does not exist.
+func (s *GroupService) AddWords(ctx context.Context, id int64, wordIDs []int64) (int, error) {
+	added := 0
+	err := s.store.WithTx(ctx, func(tx repository.Store) error {
+		if _, err := tx.Groups().Get(ctx, id); err != nil {
+			return notFound(err, ErrGroupNotFound)
+		}
+
+		missing, err := tx.Words().MissingIDs(ctx, wordIDs)
+		if err != nil {
+			return err
+		}
+		if len(missing) > 0 {
+			return &MissingWordsError{IDs: missing}
+		}
+
+		added, err = tx.Groups().AddWords(ctx, id, wordIDs)
+		return err
+	})
+
+	return added, err
+}
+
+// RemoveWords unlinks words from a group without deleting them. It returns
+// the number of memberships removed.
+func (s *GroupService) RemoveWords(ctx context.Context, id int64, wordIDs []int64) (int, error) {
+	removed := 0
+	err := s.store.WithTx(ctx, func(tx repository.Store) error {
+		if _, err := tx.Groups().Get(ctx, id); err != nil {
+			return notFound(err, ErrGroupNotFound)
+		}
+
+		var err error
+		removed, err = tx.Groups().RemoveWords(ctx, id, wordIDs)
+		return err
+	})
+
+	return removed, err
+}
+
+// Import adds words to a group in a single transaction. A word that already
+// exists, matched on its japanese text and case-insensitive english text, is
+// linked instead of duplicated. Invalid words are skipped and reported
+// without failing the whole import.
+func (s *GroupService) Import(ctx context.Context, id int64, words []models.Word) ([]models.ImportResult, error) {
+	results := make([]models.ImportResult, len(words))
+	err := s.store.WithTx(ctx, func(tx repository.Store) error {
+		if _, err := tx.Groups().Get(ctx, id); err != nil {
+			return notFound(err, ErrGroupNotFound)
+		}
+
+		for i, word := range words {
+			word.Japanese = strings.TrimSpace(word.Japanese)
+			word.Romaji = strings.TrimSpace(word.Romaji)
+			word.English = strings.TrimSpace(word.English)
+
+			result := models.ImportResult{Index: i, Japanese: word.Japanese}
+
+			if word.Japanese == "" || word.Romaji == "" || word.English == "" {
+				result.Status = models.ImportSkipped
+				result.Reason = "japanese, romaji and english are required"
+				results[i] = result
+				continue
+			}
+			if err := models.ValidateParts(word.Japanese, word.Parts); err != nil {
+				result.Status = models.ImportSkipped
+				result.Reason = err.Error()
+				results[i] = result
+				continue
+			}
+
+			existing, err := tx.Words().FindByText(ctx, word.Japanese, word.English)
+			switch {
+			case err == models.ErrNotFound:
+				if err := tx.Words().Create(ctx, &word); err != nil {
+					return err
+				}
+				result.Status = models.ImportCreated
+			case err != nil:
+				return err
+			default:
+				word.ID = existing.ID
+				result.Status = models.ImportLinked
+			}
+			result.WordID = word.ID
+
+			added, err := tx.Groups().AddWords(ctx, id, []int64{word.ID})
+			if err != nil {
+				return err
+			}
+			if added == 0 {
+				result.Status = models.ImportSkipped
+				result.Reason = "already in group"
+			}
+
+			results[i] = result
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func groupName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", invalid("Group name must not be empty")
+	}
+	return name, nil
+}
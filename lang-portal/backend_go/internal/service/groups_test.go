@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupServiceNames(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	var invalid *ValidationError
+	_, err := f.svc.Groups.Create(ctx, "   ")
+	assert.True(t, errors.As(err, &invalid))
+
+	_, err = f.svc.Groups.Create(ctx, "Basic Greetings")
+	assert.ErrorIs(t, err, models.ErrDuplicateGroupName)
+
+	group, err := f.svc.Groups.Rename(ctx, f.group.ID, "  Greetings ")
+	assert.NoError(t, err)
+	assert.Equal(t, "Greetings", group.Name)
+
+	_, err = f.svc.Groups.Rename(ctx, 999, "Numbers")
+	assert.ErrorIs(t, err, ErrGroupNotFound)
+}
+
+func TestGroupServiceAddWords(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	group, err := f.svc.Groups.Create(ctx, "Farewells")
+	assert.NoError(t, err)
+
+	// Nothing is linked when one of the words is missing
+	_, err = f.svc.Groups.AddWords(ctx, group.ID, []int64{f.words[1].ID, 999})
+	var missing *MissingWordsError
+	assert.True(t, errors.As(err, &missing))
+	assert.Equal(t, []int64{999}, missing.IDs)
+
+	_, stats, err := f.svc.Groups.Get(ctx, group.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, stats.TotalWordCount)
+
+	added, err := f.svc.Groups.AddWords(ctx, group.ID, []int64{f.words[1].ID, f.words[1].ID})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, added)
+
+	_, err = f.svc.Groups.AddWords(ctx, 999, []int64{f.words[1].ID})
+	assert.ErrorIs(t, err, ErrGroupNotFound)
+}
+
+func TestGroupServiceImport(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	group, err := f.svc.Groups.Create(ctx, "Mixed")
+	assert.NoError(t, err)
+
+	results, err := f.svc.Groups.Import(ctx, group.ID, []models.Word{
+		{Japanese: "こんにちは", Romaji: "konnichiwa", English: "Hello"},
+		{Japanese: "水", Romaji: "mizu", English: "water", Parts: models.Parts{Segments: []models.Segment{{Kanji: "水", Romaji: "mizu"}}}},
+		{Japanese: "水", Romaji: "mizu", English: "WATER"},
+		{Japanese: "", Romaji: "kara", English: "empty"},
+		{Japanese: "火", Romaji: "hi", English: "fire", Parts: models.Parts{Segments: []models.Segment{{Kanji: "火山", Romaji: "kazan"}}}},
+	})
+	assert.NoError(t, err)
+
+	statuses := make([]string, len(results))
+	for i, result := range results {
+		statuses[i] = result.Status
+	}
+	assert.Equal(t, []string{
+		models.ImportLinked,
+		models.ImportCreated,
+		models.ImportSkipped,
+		models.ImportSkipped,
+		models.ImportSkipped,
+	}, statuses)
+	assert.Equal(t, f.words[0].ID, results[0].WordID)
+	assert.Equal(t, results[1].WordID, results[2].WordID)
+	assert.Equal(t, "already in group", results[2].Reason)
+	assert.Equal(t, `parts segments spell "火山" instead of "火"`, results[4].Reason)
+
+	words, _, err := f.svc.Groups.Words(ctx, f.user, group.ID, repository.ListOptions{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, words, 2)
+	assert.Equal(t, []models.Segment{{Kanji: "水", Romaji: "mizu"}}, words[1].Parts.Segments)
+
+	_, err = f.svc.Groups.Import(ctx, 999, []models.Word{{Japanese: "木", Romaji: "ki", English: "tree"}})
+	assert.ErrorIs(t, err, ErrGroupNotFound)
+}
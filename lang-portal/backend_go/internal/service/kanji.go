@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+)
+
+type KanjiService struct {
+	store repository.Store
+}
+
+// KanjiDetail is a kanji with the words written with it and a user's review
+// statistics over those words.
+type KanjiDetail struct {
+	models.Kanji
+	Words []models.WordSummary
+	Stats models.KanjiStats
+}
+
+// Get returns the kanji written as character with its words.
+func (s *KanjiService) Get(ctx context.Context, userID int64, character string) (*KanjiDetail, error) {
+	kanji, err := s.store.Kanji().Get(ctx, character)
+	if err != nil {
+		return nil, notFound(err, ErrKanjiNotFound)
+	}
+
+	words, err := s.store.Kanji().Words(ctx, userID, kanji.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &KanjiDetail{Kanji: *kanji, Words: words}
+	for _, word := range words {
+		detail.Stats.CorrectCount += word.CorrectCount
+		detail.Stats.WrongCount += word.WrongCount
+	}
+	if total := detail.Stats.CorrectCount + detail.Stats.WrongCount; total > 0 {
+		detail.Stats.SuccessRate = float64(detail.Stats.CorrectCount) / float64(total) * 100
+	}
+
+	return detail, nil
+}
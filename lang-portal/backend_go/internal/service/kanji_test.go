@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"lang-portal/backend_go/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKanjiService(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	require.NoError(t, f.store.Kanji().Create(ctx, &models.Kanji{Character: "水", Meanings: models.StringList{"water"}, StrokeCount: 4}))
+	water := models.Word{Japanese: "水", Romaji: "mizu", English: "water"}
+	require.NoError(t, f.svc.Words.Create(ctx, &water))
+	waterfall := models.Word{Japanese: "滝水", Romaji: "takimizu", English: "waterfall water"}
+	require.NoError(t, f.svc.Words.Create(ctx, &waterfall))
+
+	for _, correct := range []bool{true, true, false} {
+		_, _, err := f.svc.Reviews.Record(ctx, f.user, f.session.ID, water.ID, Answer{Correct: correct}, f.start)
+		require.NoError(t, err)
+	}
+	_, _, err := f.svc.Reviews.Record(ctx, f.user, f.session.ID, waterfall.ID, Answer{Correct: true}, f.start)
+	require.NoError(t, err)
+
+	detail, err := f.svc.Kanji.Get(ctx, f.user, "水")
+	require.NoError(t, err)
+	assert.Equal(t, "水", detail.Character)
+	if assert.Len(t, detail.Words, 2) {
+		assert.Equal(t, water.ID, detail.Words[0].ID)
+		assert.Equal(t, waterfall.ID, detail.Words[1].ID)
+	}
+	assert.Equal(t, models.KanjiStats{CorrectCount: 3, WrongCount: 1, SuccessRate: 75}, detail.Stats)
+
+	// Another user has not reviewed any of the words
+	detail, err = f.svc.Kanji.Get(ctx, f.user+100, "水")
+	require.NoError(t, err)
+	assert.Equal(t, models.KanjiStats{}, detail.Stats)
+
+	_, err = f.svc.Kanji.Get(ctx, f.user, "火")
+	assert.ErrorIs(t, err, ErrKanjiNotFound)
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+)
+
+// DefaultLaunchTokenTTL is how long launch tokens stay valid.
+const DefaultLaunchTokenTTL = 2 * time.Hour
+
+// ErrOutsideLaunch is returned when a launch token is used for something
+// other than the session and group it was issued for.
+var ErrOutsideLaunch = errors.New("launch token does not cover this request")
+
+// LaunchClaims are what a launch token grants an activity app: acting for a
+// user in one study session, over the words of the session's group.
+type LaunchClaims struct {
+	UserID    int64     `json:"user_id"`
+	SessionID int64     `json:"session_id"`
+	GroupID   int64     `json:"group_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Launch is a started study session together with the address of the
+// activity app to run it in.
+type Launch struct {
+	Session   *models.StudySession
+	URL       string
+	Token     string
+	ExpiresAt time.Time
+}
+
+// newLaunchKey returns a random key to sign launch tokens with. Tokens
+// signed with it do not survive a restart, so servers with more than one
+// instance configure a key instead.
+func newLaunchKey() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic("service: cannot generate launch key: " + err.Error())
+	}
+	return key
+}
+
+// IsLaunchToken reports whether a bearer token is a launch token rather
+// than a login token. Login tokens never contain a dot.
+func IsLaunchToken(token string) bool {
+	return strings.Contains(token, ".")
+}
+
+// IssueLaunchToken signs claims into a launch token. Unlike login tokens,
+// launch tokens are not stored: they are the claims and their HMAC-SHA256,
+// both base64url encoded and joined by a dot.
+func (s *AuthService) IssueLaunchToken(claims LaunchClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.signLaunch(encoded)), nil
+}
+
+func (s *AuthService) signLaunch(encoded string) []byte {
+	mac := hmac.New(sha256.New, s.LaunchKey)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
+
+// AuthenticateLaunch checks the signature and expiry of a launch token and
+// returns the user it was issued to with its claims.
+func (s *AuthService) AuthenticateLaunch(ctx context.Context, token string, now time.Time) (*models.User, *LaunchClaims, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, nil, ErrInvalidToken
+	}
+	sum, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(sum, s.signLaunch(encoded)) {
+		return nil, nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, nil, ErrInvalidToken
+	}
+	var claims LaunchClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, nil, ErrInvalidToken
+	}
+	if !claims.ExpiresAt.After(now) {
+		return nil, nil, ErrInvalidToken
+	}
+
+	user, err := s.store.Users().Get(ctx, claims.UserID)
+	if err == models.ErrNotFound {
+		return nil, nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return user, &claims, nil
+}
+
+// Launch starts a study session of the activity over a group and returns
+// the activity's launch URL for it, carrying a launch token the app sends
+// its results back with. The group needs a word the activity can use, see
+// models.StudyActivity.Fits. The placeholders {session_id}, {group_id},
+// {activity_id} and {token} of the URL are filled in, and the token is
+// added as the token query parameter if the URL has no place for it.
+func (s *ActivityService) Launch(ctx context.Context, userID, id, groupID int64, now time.Time) (*Launch, error) {
+	activity, err := s.store.Activities().Get(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrActivityNotFound)
+	}
+	if activity.LaunchURL == "" {
+		return nil, invalid("Study activity %q has no launch URL", activity.Name)
+	}
+	group, err := s.store.Groups().Get(ctx, groupID)
+	if err != nil {
+		return nil, notFound(err, ErrGroupNotFound)
+	}
+	words, err := s.store.Words().Export(ctx, userID, groupID, false)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.ContainsFunc(words, func(word models.ExportWord) bool { return activity.Fits(&word.Word) }) {
+		return nil, invalid("Group %q has no words %s can practise", group.Name, activity.Name)
+	}
+
+	launch := Launch{
+		Session: &models.StudySession{
+			UserID:          userID,
+			GroupID:         groupID,
+			StudyActivityID: id,
+			Status:          models.SessionActive,
+		},
+		ExpiresAt: now.UTC().Add(s.auth.LaunchTokenTTL),
+	}
+	// A launch URL that cannot be filled in leaves no session behind
+	err = s.store.WithTx(ctx, func(tx repository.Store) error {
+		if err := tx.Sessions().Create(ctx, launch.Session); err != nil {
+			return err
+		}
+
+		launch.Token, err = s.auth.IssueLaunchToken(LaunchClaims{
+			UserID:    userID,
+			SessionID: launch.Session.ID,
+			GroupID:   groupID,
+			ExpiresAt: launch.ExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		launch.URL, err = expandLaunchURL(activity.LaunchURL, map[string]string{
+			"session_id":  strconv.FormatInt(launch.Session.ID, 10),
+			"group_id":    strconv.FormatInt(groupID, 10),
+			"activity_id": strconv.FormatInt(id, 10),
+			"token":       launch.Token,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &launch, nil
+}
+
+// expandLaunchURL fills the {name} placeholders of a launch URL template
+// with the query escaped values, adding the token as a query parameter if
+// the template has no {token}.
+func expandLaunchURL(template string, values map[string]string) (string, error) {
+	replacements := make([]string, 0, 2*len(values))
+	for name, value := range values {
+		replacements = append(replacements, "{"+name+"}", url.QueryEscape(value))
+	}
+	expanded := strings.NewReplacer(replacements...).Replace(template)
+
+	u, err := url.Parse(expanded)
+	if err != nil {
+		return "", invalid("Launch URL %q is not a valid URL", template)
+	}
+	if !strings.Contains(template, "{token}") {
+		query := u.Query()
+		query.Set("token", values["token"])
+		u.RawQuery = query.Encode()
+	}
+	return u.String(), nil
+}
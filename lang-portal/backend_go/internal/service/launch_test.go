@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"lang-portal/backend_go/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActivityLaunch(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	activity := models.StudyActivity{Slug: "flashcards", Name: "Flashcards", LaunchURL: "https://cards.example.com/#/{activity_id}/{session_id}/{token}"}
+	require.NoError(t, f.store.Activities().Create(ctx, &activity))
+
+	launch, err := f.svc.Activities.Launch(ctx, f.user, activity.ID, f.group.ID, f.start)
+	require.NoError(t, err)
+	assert.Equal(t, f.start.Add(DefaultLaunchTokenTTL), launch.ExpiresAt)
+	assert.Equal(t, models.SessionActive, launch.Session.Status)
+	assert.Equal(t, fmt.Sprintf("https://cards.example.com/#/%d/%d/%s", activity.ID, launch.Session.ID, launch.Token), launch.URL)
+
+	// The token is good for its session until it expires, and only with the
+	// key it was signed with
+	user, claims, err := f.svc.Auth.AuthenticateLaunch(ctx, launch.Token, f.start.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, f.user, user.ID)
+	assert.Equal(t, LaunchClaims{UserID: f.user, SessionID: launch.Session.ID, GroupID: f.group.ID, ExpiresAt: launch.ExpiresAt}, *claims)
+
+	_, _, err = f.svc.Auth.AuthenticateLaunch(ctx, launch.Token, launch.ExpiresAt)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	other := New(f.store)
+	_, _, err = other.Auth.AuthenticateLaunch(ctx, launch.Token, f.start)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	// Reviews sent with it stay within the session's group
+	_, _, err = f.svc.Reviews.RecordLaunched(ctx, claims, f.words[0].ID, Answer{Correct: true}, f.start)
+	require.NoError(t, err)
+	outside := models.Word{Japanese: "ありがとう", Romaji: "arigatou", English: "thank you", Parts: models.Parts{Type: "greeting"}}
+	require.NoError(t, f.svc.Words.Create(ctx, &outside))
+	_, _, err = f.svc.Reviews.RecordLaunched(ctx, claims, outside.ID, Answer{Correct: true}, f.start)
+	assert.ErrorIs(t, err, ErrOutsideLaunch)
+	_, _, err = f.svc.Reviews.RecordLaunched(ctx, claims, 999, Answer{Correct: true}, f.start)
+	assert.ErrorIs(t, err, ErrWordNotFound)
+	_, _, _, err = f.svc.Reviews.CheckLaunched(ctx, claims, 999, Attempt{Input: "hello", Direction: models.DirectionJapaneseToEnglish}, f.start)
+	assert.ErrorIs(t, err, ErrWordNotFound)
+
+	// Activities without a launch URL cannot be launched, nor those that
+	// have no use for any word of the group
+	_, err = f.svc.Activities.Launch(ctx, f.user, f.activity.ID, f.group.ID, f.start)
+	var validation *ValidationError
+	assert.ErrorAs(t, err, &validation)
+	verbs := models.StudyActivity{Slug: "verb-drills", Name: "Verb Drills", LaunchURL: "https://drills.example.com/", SupportedGroupTypes: models.StringList{"verb"}}
+	require.NoError(t, f.store.Activities().Create(ctx, &verbs))
+	_, err = f.svc.Activities.Launch(ctx, f.user, verbs.ID, f.group.ID, f.start)
+	if assert.ErrorAs(t, err, &validation) {
+		assert.Equal(t, `Group "Basic Greetings" has no words Verb Drills can practise`, validation.Message)
+	}
+	_, err = f.svc.Activities.Launch(ctx, f.user, activity.ID, 999, f.start)
+	assert.ErrorIs(t, err, ErrGroupNotFound)
+}
+
+func TestExpandLaunchURL(t *testing.T) {
+	values := map[string]string{"session_id": "7", "group_id": "3", "activity_id": "2", "token": "abc.def"}
+	tests := map[string]string{
+		"https://cards.example.com/study/{session_id}":            "https://cards.example.com/study/7?token=abc.def",
+		"https://cards.example.com/?lang=ja&group={group_id}":     "https://cards.example.com/?group=3&lang=ja&token=abc.def",
+		"https://cards.example.com/?s={session_id}&auth={token}":  "https://cards.example.com/?s=7&auth=abc.def",
+		"https://cards.example.com/{activity_id}?unknown={other}": "https://cards.example.com/2?token=abc.def&unknown=%7Bother%7D",
+	}
+	for template, want := range tests {
+		got, err := expandLaunchURL(template, values)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, template)
+	}
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"lang-portal/backend_go/internal/grading"
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+)
+
+type ReviewService struct {
+	store repository.Store
+	xapi  *XAPIService
+}
+
+// maxAnswerLength is the most runes of a learner's answer that are kept.
+const maxAnswerLength = 500
+
+// Answer is how a learner answered the prompt of a review.
+type Answer struct {
+	Correct bool
+	// AnswerGiven is what the learner typed or picked.
+	AnswerGiven string
+	// ResponseMS is how long the learner took in milliseconds, nil if
+	// unknown.
+	ResponseMS *int64
+	// Quality is the SM-2 grade (0-5). Nil grades the answer by Correct
+	// alone.
+	Quality *int
+	// Direction is one of models.Directions, or empty.
+	Direction string
+	HintsUsed int
+}
+
+// review checks the answer and returns the review it makes.
+func (a Answer) review(userID, sessionID, wordID int64) (models.WordReview, error) {
+	review := models.WordReview{
+		UserID:         userID,
+		WordID:         wordID,
+		StudySessionID: sessionID,
+		Correct:        a.Correct,
+		AnswerGiven:    a.AnswerGiven,
+		ResponseMS:     a.ResponseMS,
+		Quality:        models.QualityFromCorrect(a.Correct),
+		Direction:      a.Direction,
+		HintsUsed:      a.HintsUsed,
+	}
+
+	if utf8.RuneCountInString(review.AnswerGiven) > maxAnswerLength {
+		return review, invalid("Answer must be at most %d characters", maxAnswerLength)
+	}
+	if review.ResponseMS != nil && *review.ResponseMS < 0 {
+		return review, invalid("Response time must not be negative")
+	}
+	if a.Quality != nil {
+		// Grades below 3 are SM-2 lapses, so they go with wrong answers
+		if *a.Quality < 0 || *a.Quality > 5 {
+			return review, invalid("Quality must be between 0 and 5")
+		}
+		if (*a.Quality >= 3) != a.Correct {
+			return review, invalid("Quality %d does not fit correct: %t, correct answers are graded 3 to 5 and wrong ones 0 to 2", *a.Quality, a.Correct)
+		}
+		review.Quality = *a.Quality
+	}
+	if review.Direction != "" && !slices.Contains(models.Directions, review.Direction) {
+		return review, invalid("Unknown direction %q, must be one of %s", review.Direction, strings.Join(models.Directions, ", "))
+	}
+	if review.HintsUsed < 0 {
+		return review, invalid("Hints used must not be negative")
+	}
+
+	return review, nil
+}
+
+// Record stores a review of a word made during one of the user's active
+// sessions and moves the user's spaced-repetition schedule for the word by
+// the answer's quality. The review is also stored as an xAPI statement. It
+// returns models.ErrSessionClosed if the session has already ended.
+func (s *ReviewService) Record(ctx context.Context, userID, sessionID, wordID int64, answer Answer, now time.Time) (*models.WordReview, *models.WordSchedule, error) {
+	review, err := answer.review(userID, sessionID, wordID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var schedule *models.WordSchedule
+
+	err = s.store.WithTx(ctx, func(tx repository.Store) error {
+		word, recorded, err := recordReview(ctx, tx, &review, now)
+		if err != nil {
+			return err
+		}
+		schedule = recorded
+
+		return s.xapi.emitReview(ctx, tx, &review, word, now)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &review, schedule, nil
+}
+
+// RecordLaunched records a review sent by an activity app with a launch
+// token, which covers only the words of the group of the token's session.
+func (s *ReviewService) RecordLaunched(ctx context.Context, launch *LaunchClaims, wordID int64, answer Answer, now time.Time) (*models.WordReview, *models.WordSchedule, error) {
+	if err := s.inLaunch(ctx, launch, wordID); err != nil {
+		return nil, nil, err
+	}
+
+	return s.Record(ctx, launch.UserID, launch.SessionID, wordID, answer, now)
+}
+
+// inLaunch returns ErrWordNotFound if the word does not exist, and
+// ErrOutsideLaunch unless it is in the group of the launched session.
+func (s *ReviewService) inLaunch(ctx context.Context, launch *LaunchClaims, wordID int64) error {
+	if _, err := s.store.Words().Get(ctx, wordID); err != nil {
+		return notFound(err, ErrWordNotFound)
+	}
+	groups, err := s.store.Words().Groups(ctx, wordID)
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(groups, func(g models.Group) bool { return g.ID == launch.GroupID }) {
+		return ErrOutsideLaunch
+	}
+	return nil
+}
+
+// Attempt is a learner's own answer to a review prompt, for the server to
+// grade.
+type Attempt struct {
+	Input string
+	// Direction is one of models.Directions. It decides whether the word's
+	// English or Japanese is expected.
+	Direction  string
+	ResponseMS *int64
+	HintsUsed  int
+}
+
+// quality grades a verdict on the SM-2 scale: 4 for an exact answer, 3 for
+// one that needed hints or was close, 1 for a wrong one and 0 for none.
+func (a Attempt) quality(verdict grading.Verdict) int {
+	switch {
+	case verdict.Result == grading.Exact && a.HintsUsed == 0:
+		return 4
+	case verdict.Correct:
+		return 3
+	case strings.TrimSpace(a.Input) == "":
+		return 0
+	default:
+		return 1
+	}
+}
+
+// Check grades a learner's answer to a word against the word's accepted
+// answers, see grading.Check, and records it as a review like Record.
+func (s *ReviewService) Check(ctx context.Context, userID, sessionID, wordID int64, attempt Attempt, now time.Time) (*grading.Verdict, *models.WordReview, *models.WordSchedule, error) {
+	if !slices.Contains(models.Directions, attempt.Direction) {
+		return nil, nil, nil, invalid("Unknown direction %q, must be one of %s", attempt.Direction, strings.Join(models.Directions, ", "))
+	}
+
+	word, err := s.store.Words().Get(ctx, wordID)
+	if err != nil {
+		return nil, nil, nil, notFound(err, ErrWordNotFound)
+	}
+
+	verdict := grading.Check(word, attempt.Direction, attempt.Input)
+	quality := attempt.quality(verdict)
+	review, schedule, err := s.Record(ctx, userID, sessionID, wordID, Answer{
+		Correct:     verdict.Correct,
+		AnswerGiven: attempt.Input,
+		ResponseMS:  attempt.ResponseMS,
+		Quality:     &quality,
+		Direction:   attempt.Direction,
+		HintsUsed:   attempt.HintsUsed,
+	}, now)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return &verdict, review, schedule, nil
+}
+
+// CheckLaunched checks an answer sent by an activity app with a launch
+// token, like RecordLaunched.
+func (s *ReviewService) CheckLaunched(ctx context.Context, launch *LaunchClaims, wordID int64, attempt Attempt, now time.Time) (*grading.Verdict, *models.WordReview, *models.WordSchedule, error) {
+	if err := s.inLaunch(ctx, launch, wordID); err != nil {
+		return nil, nil, nil, err
+	}
+
+	return s.Check(ctx, launch.UserID, launch.SessionID, wordID, attempt, now)
+}
+
+// recordReview stores a review made during one of the user's active
+// sessions and moves the user's schedule for the word. It returns the
+// reviewed word and its new schedule.
+func recordReview(ctx context.Context, tx repository.Store, review *models.WordReview, now time.Time) (*models.Word, *models.WordSchedule, error) {
+	session, err := ownSession(ctx, tx, review.UserID, review.StudySessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if session.Status != models.SessionActive {
+		return nil, nil, models.ErrSessionClosed
+	}
+
+	word, err := tx.Words().Get(ctx, review.WordID)
+	if err != nil {
+		return nil, nil, notFound(err, ErrWordNotFound)
+	}
+
+	if err := tx.Reviews().Create(ctx, review); err != nil {
+		return nil, nil, err
+	}
+
+	schedule, err := tx.Reviews().GetSchedule(ctx, review.UserID, review.WordID)
+	if err == models.ErrNotFound {
+		schedule = models.NewWordSchedule(review.UserID, review.WordID)
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	schedule.Apply(review.Quality, now)
+	if err := tx.Reviews().SaveSchedule(ctx, schedule); err != nil {
+		return nil, nil, err
+	}
+
+	return word, schedule, nil
+}
+
+// Due returns up to limit words that are due for the user at now, most
+// overdue first, followed by words they have never reviewed. A groupID of 0
+// means all words.
+func (s *ReviewService) Due(ctx context.Context, userID, groupID int64, limit int, now time.Time) ([]models.DueWord, error) {
+	if groupID != 0 {
+		if _, err := s.store.Groups().Get(ctx, groupID); err != nil {
+			return nil, notFound(err, ErrGroupNotFound)
+		}
+	}
+
+	return s.store.Reviews().DueWords(ctx, userID, groupID, now, limit)
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"lang-portal/backend_go/internal/grading"
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReviewServiceRecord(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	review, schedule, err := f.svc.Reviews.Record(ctx, f.user, f.session.ID, f.words[0].ID, Answer{Correct: true}, f.start)
+	assert.NoError(t, err)
+	assert.True(t, review.Correct)
+	assert.Equal(t, 1, schedule.Repetitions)
+	assert.Equal(t, f.start.AddDate(0, 0, 1), schedule.DueAt)
+
+	// A second correct answer moves the word six days out
+	_, schedule, err = f.svc.Reviews.Record(ctx, f.user, f.session.ID, f.words[0].ID, Answer{Correct: true}, f.start)
+	assert.NoError(t, err)
+	assert.Equal(t, 6, schedule.IntervalDays)
+
+	_, _, err = f.svc.Reviews.Record(ctx, f.user, 999, f.words[0].ID, Answer{Correct: true}, f.start)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+
+	_, _, err = f.svc.Reviews.Record(ctx, f.user, f.session.ID, 999, Answer{Correct: true}, f.start)
+	assert.ErrorIs(t, err, ErrWordNotFound)
+
+	_, err = f.svc.Sessions.End(ctx, f.user, f.session.ID, f.start)
+	assert.NoError(t, err)
+
+	_, _, err = f.svc.Reviews.Record(ctx, f.user, f.session.ID, f.words[1].ID, Answer{Correct: true}, f.start)
+	assert.ErrorIs(t, err, models.ErrSessionClosed)
+
+	words, next, err := f.svc.Sessions.Words(ctx, f.user, f.session.ID, repository.Keyset{Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, words, 2)
+	assert.True(t, next.IsZero())
+	assert.Equal(t, f.words[0].Parts, words[0].Parts)
+}
+
+func TestReviewServiceAnswerDetails(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	// A quick perfect answer is graded by its quality rather than as a plain
+	// correct one
+	responseMS, quality := int64(1850), 5
+	review, schedule, err := f.svc.Reviews.Record(ctx, f.user, f.session.ID, f.words[0].ID, Answer{
+		Correct:     true,
+		AnswerGiven: "hello",
+		ResponseMS:  &responseMS,
+		Quality:     &quality,
+		Direction:   models.DirectionJapaneseToEnglish,
+		HintsUsed:   1,
+	}, f.start)
+	require.NoError(t, err)
+	assert.Equal(t, 5, review.Quality)
+	assert.InDelta(t, 2.6, schedule.EaseFactor, 0.001)
+
+	review, _, err = f.svc.Reviews.Record(ctx, f.user, f.session.ID, f.words[1].ID, Answer{Correct: false}, f.start)
+	require.NoError(t, err)
+	assert.Equal(t, models.QualityFromCorrect(false), review.Quality)
+
+	words, _, err := f.svc.Sessions.Words(ctx, f.user, f.session.ID, repository.Keyset{Limit: 10})
+	require.NoError(t, err)
+	if assert.Len(t, words, 2) {
+		assert.Equal(t, "hello", words[0].AnswerGiven)
+		assert.Equal(t, &responseMS, words[0].ResponseMS)
+		assert.Equal(t, models.DirectionJapaneseToEnglish, words[0].Direction)
+		assert.Equal(t, 1, words[0].HintsUsed)
+		assert.Nil(t, words[1].ResponseMS)
+	}
+
+	// The answer and response time travel with the xAPI statement
+	statements, _, err := f.svc.XAPI.List(ctx, f.user, repository.StatementFilter{ObjectID: f.svc.XAPI.WordIRI(f.words[0].ID)}, repository.Keyset{Limit: 10})
+	require.NoError(t, err)
+	if assert.Len(t, statements, 1) {
+		assert.Equal(t, "hello", statements[0].Result.Response)
+		assert.Equal(t, "PT1.85S", statements[0].Result.Duration)
+		assert.Equal(t, &responseMS, durationMS(statements[0].Result.Duration))
+	}
+
+	low, negative := 2, int64(-1)
+	var validation *ValidationError
+	for _, answer := range []Answer{
+		{Correct: true, Quality: &low},
+		{Correct: false, Quality: &quality},
+		{Correct: true, ResponseMS: &negative},
+		{Correct: true, Direction: "ja-fr"},
+		{Correct: true, HintsUsed: -1},
+	} {
+		_, _, err := f.svc.Reviews.Record(ctx, f.user, f.session.ID, f.words[0].ID, answer, f.start)
+		assert.ErrorAs(t, err, &validation, answer)
+	}
+}
+
+func TestReviewServiceCheck(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	// Romaji with a long vowel spelled out answers さようなら
+	verdict, review, schedule, err := f.svc.Reviews.Check(ctx, f.user, f.session.ID, f.words[1].ID, Attempt{Input: "Sayōnara", Direction: models.DirectionEnglishToJapanese}, f.start)
+	require.NoError(t, err)
+	assert.Equal(t, grading.Exact, verdict.Result)
+	assert.Equal(t, "さようなら", verdict.Expected)
+	assert.True(t, review.Correct)
+	assert.Equal(t, "Sayōnara", review.AnswerGiven)
+	assert.Equal(t, 4, review.Quality)
+	assert.Equal(t, 1, schedule.Repetitions)
+
+	// A typo is close, and graded lower
+	verdict, review, _, err = f.svc.Reviews.Check(ctx, f.user, f.session.ID, f.words[0].ID, Attempt{Input: "helo", Direction: models.DirectionJapaneseToEnglish}, f.start)
+	require.NoError(t, err)
+	assert.Equal(t, grading.Close, verdict.Result)
+	assert.Equal(t, "hello", verdict.Expected)
+	assert.True(t, review.Correct)
+	assert.Equal(t, 3, review.Quality)
+
+	verdict, review, _, err = f.svc.Reviews.Check(ctx, f.user, f.session.ID, f.words[0].ID, Attempt{Input: "goodbye", Direction: models.DirectionJapaneseToEnglish}, f.start)
+	require.NoError(t, err)
+	assert.Equal(t, grading.Wrong, verdict.Result)
+	assert.False(t, review.Correct)
+	assert.Equal(t, 1, review.Quality)
+
+	var validation *ValidationError
+	_, _, _, err = f.svc.Reviews.Check(ctx, f.user, f.session.ID, f.words[0].ID, Attempt{Input: "hello"}, f.start)
+	assert.ErrorAs(t, err, &validation)
+	_, _, _, err = f.svc.Reviews.Check(ctx, f.user, f.session.ID, 999, Attempt{Input: "hello", Direction: models.DirectionJapaneseToEnglish}, f.start)
+	assert.ErrorIs(t, err, ErrWordNotFound)
+}
+
+func TestReviewServiceDue(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	_, _, err := f.svc.Reviews.Record(ctx, f.user, f.session.ID, f.words[0].ID, Answer{Correct: true}, f.start)
+	assert.NoError(t, err)
+
+	// Right after the review only the unseen word is due
+	due, err := f.svc.Reviews.Due(ctx, f.user, f.group.ID, 10, f.start)
+	assert.NoError(t, err)
+	if assert.Len(t, due, 1) {
+		assert.Equal(t, f.words[1].ID, due[0].ID)
+		assert.Nil(t, due[0].Schedule)
+	}
+
+	// Two days later the reviewed word comes first
+	due, err = f.svc.Reviews.Due(ctx, f.user, 0, 10, f.start.Add(48*time.Hour))
+	assert.NoError(t, err)
+	if assert.Len(t, due, 2) {
+		assert.Equal(t, f.words[0].ID, due[0].ID)
+		assert.NotNil(t, due[0].Schedule)
+	}
+
+	_, err = f.svc.Reviews.Due(ctx, f.user, 999, 10, f.start)
+	assert.ErrorIs(t, err, ErrGroupNotFound)
+}
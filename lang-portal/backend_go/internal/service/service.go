@@ -0,0 +1,103 @@
+// Package service holds the business rules of the portal. Services sit
+// between the HTTP handlers and the repositories and know nothing about
+// either HTTP or SQL.
+package service
+
+import (
+	"fmt"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+)
+
+// Not found errors for each kind of record. They all wrap models.ErrNotFound.
+var (
+	ErrWordNotFound      = fmt.Errorf("word %w", models.ErrNotFound)
+	ErrGroupNotFound     = fmt.Errorf("group %w", models.ErrNotFound)
+	ErrKanjiNotFound     = fmt.Errorf("kanji %w", models.ErrNotFound)
+	ErrSessionNotFound   = fmt.Errorf("study session %w", models.ErrNotFound)
+	ErrActivityNotFound  = fmt.Errorf("study activity %w", models.ErrNotFound)
+	ErrUserNotFound      = fmt.Errorf("user %w", models.ErrNotFound)
+	ErrStatementNotFound = fmt.Errorf("statement %w", models.ErrNotFound)
+	ErrStateNotFound     = fmt.Errorf("state document %w", models.ErrNotFound)
+)
+
+// ValidationError reports input that breaks a business rule.
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func invalid(format string, args ...any) error {
+	return &ValidationError{Message: fmt.Sprintf(format, args...)}
+}
+
+// Services bundles every service, all sharing one store.
+type Services struct {
+	Words      *WordService
+	Groups     *GroupService
+	Kanji      *KanjiService
+	Sessions   *SessionService
+	Reviews    *ReviewService
+	Activities *ActivityService
+	Dashboard  *DashboardService
+	Settings   *SettingsService
+	Users      *UserService
+	Auth       *AuthService
+	XAPI       *XAPIService
+}
+
+func New(store repository.Store) *Services {
+	xapi := &XAPIService{store: store, BaseIRI: DefaultXAPIBaseIRI}
+	auth := &AuthService{
+		store:          store,
+		TokenTTL:       DefaultTokenTTL,
+		LaunchKey:      newLaunchKey(),
+		LaunchTokenTTL: DefaultLaunchTokenTTL,
+	}
+	return &Services{
+		Words:      &WordService{store: store},
+		Groups:     &GroupService{store: store},
+		Kanji:      &KanjiService{store: store},
+		Sessions:   &SessionService{store: store},
+		Reviews:    &ReviewService{store: store, xapi: xapi},
+		Activities: &ActivityService{store: store, auth: auth},
+		Dashboard:  &DashboardService{store: store},
+		Settings:   &SettingsService{store: store},
+		Users:      &UserService{store: store},
+		Auth:       auth,
+		XAPI:       xapi,
+	}
+}
+
+// notFound replaces a repository's models.ErrNotFound with the more specific
+// err, leaving other errors alone.
+func notFound(repoErr error, err error) error {
+	if repoErr == models.ErrNotFound {
+		return err
+	}
+	return repoErr
+}
+
+// readPage reads the page of a listing selected by keyset, asking read for
+// one item more than the page holds to learn whether another page follows.
+// It returns the cursor of the page's last item if one does, or the zero
+// Cursor.
+func readPage[T any](keyset repository.Keyset, read func(repository.Keyset) ([]T, error), cursor func(T) (repository.Cursor, error)) ([]T, repository.Cursor, error) {
+	limit := keyset.Limit
+	keyset.Limit++
+	items, err := read(keyset)
+	if err != nil || len(items) <= limit {
+		return items, repository.Cursor{}, err
+	}
+
+	items = items[:limit]
+	next, err := cursor(items[limit-1])
+	if err != nil {
+		return nil, repository.Cursor{}, err
+	}
+	return items, next, nil
+}
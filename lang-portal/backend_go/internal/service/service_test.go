@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository/memory"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fixture is a small library held in the in-memory store: one group with
+// two words, one activity and an active session of the default user started
+// at start.
+type fixture struct {
+	store    *memory.Store
+	svc      *Services
+	user     int64
+	group    *models.Group
+	words    []models.Word
+	activity models.StudyActivity
+	session  *models.StudySession
+	start    time.Time
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	ctx := context.Background()
+
+	// Keep password hashing fast
+	passwordCost = bcrypt.MinCost
+
+	f := &fixture{
+		store: memory.New(),
+		user:  models.DefaultUserID,
+		start: time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC),
+	}
+	f.store.Now = func() time.Time { return f.start }
+	f.svc = New(f.store)
+
+	var err error
+	f.group, err = f.svc.Groups.Create(ctx, "Basic Greetings")
+	require.NoError(t, err)
+
+	for _, word := range []models.Word{
+		{Japanese: "こんにちは", Romaji: "konnichiwa", English: "hello", Parts: models.Parts{Type: "greeting"}},
+		{Japanese: "さようなら", Romaji: "sayounara", English: "goodbye", Parts: models.Parts{Type: "greeting"}},
+	} {
+		require.NoError(t, f.svc.Words.Create(ctx, &word))
+		f.words = append(f.words, word)
+	}
+
+	_, err = f.svc.Groups.AddWords(ctx, f.group.ID, []int64{f.words[0].ID, f.words[1].ID})
+	require.NoError(t, err)
+
+	f.activity = models.StudyActivity{Slug: "vocabulary-quiz", Name: "Vocabulary Quiz"}
+	require.NoError(t, f.store.Activities().Create(ctx, &f.activity))
+
+	f.session, err = f.svc.Sessions.Start(ctx, f.user, f.group.ID, f.activity.ID)
+	require.NoError(t, err)
+
+	return f
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+
+	"lang-portal/backend_go/internal/repository"
+)
+
+type SettingsService struct {
+	store repository.Store
+}
+
+// ResetHistory deletes the user's study sessions, reviews, schedules and
+// xAPI records but keeps the vocabulary and everyone else's history.
+func (s *SettingsService) ResetHistory(ctx context.Context, userID int64) error {
+	return s.store.WithTx(ctx, func(tx repository.Store) error {
+		if err := tx.XAPI().DeleteByUser(ctx, userID); err != nil {
+			return err
+		}
+		if err := tx.Reviews().DeleteByUser(ctx, userID); err != nil {
+			return err
+		}
+		return tx.Sessions().DeleteByUser(ctx, userID)
+	})
+}
+
+// FullReset deletes everything but the user accounts, in reverse order of
+// dependencies.
+func (s *SettingsService) FullReset(ctx context.Context) error {
+	return s.store.WithTx(ctx, func(tx repository.Store) error {
+		steps := []func(context.Context) error{
+			tx.XAPI().DeleteAll,
+			tx.Reviews().DeleteAll,
+			tx.Sessions().DeleteAll,
+			tx.Activities().DeleteAll,
+			tx.Groups().DeleteAll,
+			tx.Words().DeleteAll,
+			tx.Kanji().DeleteAll,
+		}
+		for _, step := range steps {
+			if err := step(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,289 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+)
+
+type ActivityService struct {
+	store repository.Store
+	auth  *AuthService
+}
+
+// ActivityManifest describes a study activity, such as a practice app. It
+// is the body of requests registering activities and the content of
+// manifest files.
+type ActivityManifest struct {
+	// Slug defaults to one made from the name.
+	Slug                string   `json:"slug"`
+	Name                string   `json:"name"`
+	Description         string   `json:"description"`
+	ThumbnailURL        string   `json:"thumbnail_url"`
+	LaunchURL           string   `json:"launch_url"`
+	SupportedGroupTypes []string `json:"supported_group_types"`
+	RequiredWordFields  []string `json:"required_word_fields"`
+}
+
+// ActivityChanges holds the fields to change on an activity. Nil fields are
+// kept.
+type ActivityChanges struct {
+	Slug                *string
+	Name                *string
+	Description         *string
+	ThumbnailURL        *string
+	LaunchURL           *string
+	SupportedGroupTypes *[]string
+	RequiredWordFields  *[]string
+}
+
+var (
+	slugPattern   = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	slugSeparator = regexp.MustCompile(`[^a-z0-9]+`)
+	placeholder   = regexp.MustCompile(`\{[a-z_]+\}`)
+)
+
+func (s *ActivityService) List(ctx context.Context) ([]models.StudyActivity, error) {
+	return s.store.Activities().List(ctx)
+}
+
+func (s *ActivityService) Get(ctx context.Context, id int64) (*models.StudyActivity, error) {
+	activity, err := s.store.Activities().Get(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrActivityNotFound)
+	}
+
+	return activity, nil
+}
+
+// Sessions returns a page of the user's sessions of the activity, started
+// within the period of filter.
+func (s *ActivityService) Sessions(ctx context.Context, userID, id int64, filter repository.SessionFilter, opts repository.ListOptions) ([]models.StudySessionDetail, int, error) {
+	filter.UserID, filter.GroupID, filter.ActivityID = userID, 0, id
+	return s.store.Sessions().List(ctx, filter, opts)
+}
+
+// Create registers an activity. It returns models.ErrDuplicateActivitySlug
+// if the slug is already taken.
+func (s *ActivityService) Create(ctx context.Context, manifest ActivityManifest) (*models.StudyActivity, error) {
+	activity := manifest.activity()
+	if err := validateActivity(&activity); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Activities().Create(ctx, &activity); err != nil {
+		return nil, err
+	}
+
+	return &activity, nil
+}
+
+func (s *ActivityService) Update(ctx context.Context, id int64, changes ActivityChanges) (*models.StudyActivity, error) {
+	var activity *models.StudyActivity
+	err := s.store.WithTx(ctx, func(tx repository.Store) error {
+		var err error
+		activity, err = tx.Activities().Get(ctx, id)
+		if err != nil {
+			return notFound(err, ErrActivityNotFound)
+		}
+
+		fields := []struct {
+			value *string
+			dest  *string
+		}{
+			{changes.Slug, &activity.Slug},
+			{changes.Name, &activity.Name},
+			{changes.Description, &activity.Description},
+			{changes.ThumbnailURL, &activity.ThumbnailURL},
+			{changes.LaunchURL, &activity.LaunchURL},
+		}
+		for _, field := range fields {
+			if field.value != nil {
+				*field.dest = *field.value
+			}
+		}
+		if changes.SupportedGroupTypes != nil {
+			activity.SupportedGroupTypes = *changes.SupportedGroupTypes
+		}
+		if changes.RequiredWordFields != nil {
+			activity.RequiredWordFields = *changes.RequiredWordFields
+		}
+
+		if err := validateActivity(activity); err != nil {
+			return err
+		}
+
+		return notFound(tx.Activities().Update(ctx, activity), ErrActivityNotFound)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return activity, nil
+}
+
+// Delete removes an activity. It returns models.ErrActivityInUse if study
+// sessions were recorded with it, so that no learner's history is lost.
+func (s *ActivityService) Delete(ctx context.Context, id int64) error {
+	return notFound(s.store.Activities().Delete(ctx, id), ErrActivityNotFound)
+}
+
+// LoadManifests registers the activities described by the .json files in
+// dir, updating those already registered under the same slug. Activities
+// without a manifest are left alone. Either every manifest loads or none
+// does.
+func (s *ActivityService) LoadManifests(ctx context.Context, dir string) ([]models.StudyActivity, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	activities := make([]models.StudyActivity, 0, len(paths))
+	err = s.store.WithTx(ctx, func(tx repository.Store) error {
+		for _, path := range paths {
+			activity, err := loadManifest(ctx, tx, path)
+			if err != nil {
+				return fmt.Errorf("activity manifest %s: %w", path, err)
+			}
+			activities = append(activities, *activity)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return activities, nil
+}
+
+func loadManifest(ctx context.Context, tx repository.Store, path string) (*models.StudyActivity, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unknown keys are an error so that typos do not go unnoticed
+	var manifest ActivityManifest
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&manifest); err != nil {
+		return nil, invalid("%v", err)
+	}
+
+	return register(ctx, tx, manifest)
+}
+
+// Register creates the activity of a manifest, or updates the one already
+// registered under its slug.
+func (s *ActivityService) Register(ctx context.Context, manifest ActivityManifest) (*models.StudyActivity, error) {
+	var activity *models.StudyActivity
+	err := s.store.WithTx(ctx, func(tx repository.Store) error {
+		var err error
+		activity, err = register(ctx, tx, manifest)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return activity, nil
+}
+
+func register(ctx context.Context, tx repository.Store, manifest ActivityManifest) (*models.StudyActivity, error) {
+	activity := manifest.activity()
+	if err := validateActivity(&activity); err != nil {
+		return nil, err
+	}
+
+	existing, err := tx.Activities().FindBySlug(ctx, activity.Slug)
+	switch {
+	case err == models.ErrNotFound:
+		err = tx.Activities().Create(ctx, &activity)
+	case err == nil:
+		activity.ID, activity.CreatedAt = existing.ID, existing.CreatedAt
+		err = tx.Activities().Update(ctx, &activity)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &activity, nil
+}
+
+func (m *ActivityManifest) activity() models.StudyActivity {
+	return models.StudyActivity{
+		Slug:                m.Slug,
+		Name:                m.Name,
+		Description:         m.Description,
+		ThumbnailURL:        m.ThumbnailURL,
+		LaunchURL:           m.LaunchURL,
+		SupportedGroupTypes: m.SupportedGroupTypes,
+		RequiredWordFields:  m.RequiredWordFields,
+	}
+}
+
+// validateActivity trims the fields of an activity, fills in a missing
+// slug from the name and checks the rest.
+func validateActivity(activity *models.StudyActivity) error {
+	activity.Name = strings.TrimSpace(activity.Name)
+	if activity.Name == "" {
+		return invalid("Activity name must not be empty")
+	}
+
+	activity.Slug = strings.TrimSpace(activity.Slug)
+	if activity.Slug == "" {
+		activity.Slug = strings.Trim(slugSeparator.ReplaceAllString(strings.ToLower(activity.Name), "-"), "-")
+		if activity.Slug == "" {
+			return invalid("Activity %q needs a slug, its name has no letters to make one from", activity.Name)
+		}
+	}
+	if len(activity.Slug) > 64 || !slugPattern.MatchString(activity.Slug) {
+		return invalid("Activity slug %q must be at most 64 lowercase letters, digits and single dashes", activity.Slug)
+	}
+
+	activity.Description = strings.TrimSpace(activity.Description)
+	activity.ThumbnailURL = strings.TrimSpace(activity.ThumbnailURL)
+	activity.LaunchURL = strings.TrimSpace(activity.LaunchURL)
+	if activity.LaunchURL != "" {
+		// Placeholders stand for plain values
+		example := placeholder.ReplaceAllString(activity.LaunchURL, "1")
+		u, err := url.Parse(example)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return invalid("Launch URL %q must be an http or https address", activity.LaunchURL)
+		}
+	}
+
+	types := models.StringList{}
+	for _, t := range activity.SupportedGroupTypes {
+		if t = strings.TrimSpace(t); t == "" {
+			return invalid("Supported group types must not be empty")
+		}
+		if !slices.Contains(types, t) {
+			types = append(types, t)
+		}
+	}
+	activity.SupportedGroupTypes = types
+
+	fields := models.StringList{}
+	for _, field := range activity.RequiredWordFields {
+		if !slices.Contains(models.WordFields, field) {
+			return invalid("Unknown word field %q, must be one of %s", field, strings.Join(models.WordFields, ", "))
+		}
+		if !slices.Contains(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+	activity.RequiredWordFields = fields
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"lang-portal/backend_go/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActivityRegistry(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	activity, err := f.svc.Activities.Create(ctx, ActivityManifest{
+		Name:                " Verb Drills! ",
+		SupportedGroupTypes: []string{"verb", " verb", "adjective"},
+		RequiredWordFields:  []string{"parts.type", "parts.type"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Verb Drills!", activity.Name)
+	assert.Equal(t, "verb-drills", activity.Slug)
+	assert.Equal(t, models.StringList{"verb", "adjective"}, activity.SupportedGroupTypes)
+	assert.Equal(t, models.StringList{"parts.type"}, activity.RequiredWordFields)
+
+	_, err = f.svc.Activities.Create(ctx, ActivityManifest{Name: "Verb drills"})
+	assert.ErrorIs(t, err, models.ErrDuplicateActivitySlug)
+
+	var validation *ValidationError
+	for _, manifest := range []ActivityManifest{
+		{Name: "かな"},
+		{Name: "Kana", Slug: "kana_quiz"},
+		{Name: "Kana", LaunchURL: "kana.example.com/{session_id}"},
+		{Name: "Kana", SupportedGroupTypes: []string{""}},
+		{Name: "Kana", RequiredWordFields: []string{"audio"}},
+	} {
+		_, err := f.svc.Activities.Create(ctx, manifest)
+		assert.ErrorAs(t, err, &validation, manifest)
+	}
+
+	launchURL := "https://drills.example.com/{session_id}"
+	updated, err := f.svc.Activities.Update(ctx, activity.ID, ActivityChanges{LaunchURL: &launchURL})
+	require.NoError(t, err)
+	assert.Equal(t, launchURL, updated.LaunchURL)
+	assert.Equal(t, "Verb Drills!", updated.Name)
+
+	// Registering a manifest again updates the activity with its slug
+	registered, err := f.svc.Activities.Register(ctx, ActivityManifest{Slug: "verb-drills", Name: "Verb Drills"})
+	require.NoError(t, err)
+	assert.Equal(t, activity.ID, registered.ID)
+	assert.Equal(t, "Verb Drills", registered.Name)
+	assert.Empty(t, registered.LaunchURL)
+
+	// The fixture's activity has a session, which keeps it
+	assert.ErrorIs(t, f.svc.Activities.Delete(ctx, f.activity.ID), models.ErrActivityInUse)
+	_, err = f.svc.Sessions.Get(ctx, f.user, f.session.ID)
+	assert.NoError(t, err)
+
+	require.NoError(t, f.svc.Activities.Delete(ctx, activity.ID))
+	assert.ErrorIs(t, f.svc.Activities.Delete(ctx, activity.ID), ErrActivityNotFound)
+}
+
+func TestLoadManifests(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	write("quiz.json", `{"slug": "vocabulary-quiz", "name": "Vocabulary Quiz", "launch_url": "https://quiz.example.com/"}`)
+	write("drills.json", `{"name": "Verb Drills", "supported_group_types": ["verb"]}`)
+	write("README.md", "Not a manifest")
+
+	loaded, err := f.svc.Activities.LoadManifests(ctx, dir)
+	require.NoError(t, err)
+	require.Len(t, loaded, 2)
+
+	// The manifest of an activity that is already registered updates it
+	quiz, err := f.svc.Activities.Get(ctx, f.activity.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "https://quiz.example.com/", quiz.LaunchURL)
+	activities, err := f.svc.Activities.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, activities, 2)
+
+	// Loading again changes nothing, a bad manifest nothing at all
+	_, err = f.svc.Activities.LoadManifests(ctx, dir)
+	require.NoError(t, err)
+	write("drills.json", `{"name": "Verb Drills", "launch": "https://drills.example.com/"}`)
+	write("kana.json", `{"name": "Kana"}`)
+	_, err = f.svc.Activities.LoadManifests(ctx, dir)
+	assert.ErrorContains(t, err, "drills.json")
+	activities, err = f.svc.Activities.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, activities, 2)
+}
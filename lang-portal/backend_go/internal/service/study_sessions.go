@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+)
+
+type SessionService struct {
+	store repository.Store
+}
+
+// ownSession returns one of the user's sessions. Other users' sessions are
+// reported as not found.
+func ownSession(ctx context.Context, store repository.Store, userID, id int64) (*models.StudySessionDetail, error) {
+	session, err := store.Sessions().Get(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrSessionNotFound)
+	}
+	if session.UserID != userID {
+		return nil, ErrSessionNotFound
+	}
+
+	return session, nil
+}
+
+// List returns a page of the user's sessions started within the period of
+// filter, newest first unless sorted.
+func (s *SessionService) List(ctx context.Context, userID int64, filter repository.SessionFilter, opts repository.ListOptions) ([]models.StudySessionDetail, int, error) {
+	filter.UserID, filter.GroupID, filter.ActivityID = userID, 0, 0
+	return s.store.Sessions().List(ctx, filter, opts)
+}
+
+// ListAfter returns the page of the user's sessions started within the
+// period of filter that follows keyset.After, newest first, with the cursor
+// of the next page.
+func (s *SessionService) ListAfter(ctx context.Context, userID int64, filter repository.SessionFilter, keyset repository.Keyset) ([]models.StudySessionDetail, repository.Cursor, error) {
+	filter.UserID, filter.GroupID, filter.ActivityID = userID, 0, 0
+	return readPage(keyset,
+		func(keyset repository.Keyset) ([]models.StudySessionDetail, error) {
+			return s.store.Sessions().ListAfter(ctx, filter, keyset)
+		},
+		func(session models.StudySessionDetail) (repository.Cursor, error) {
+			start, err := time.Parse(time.RFC3339Nano, session.StartTime)
+			return repository.Cursor{Time: start, ID: session.ID}, err
+		})
+}
+
+func (s *SessionService) Get(ctx context.Context, userID, id int64) (*models.StudySessionDetail, error) {
+	return ownSession(ctx, s.store, userID, id)
+}
+
+// Words returns the page of words reviewed during one of the user's
+// sessions that follows keyset.After, in review order, with the cursor of the
+// next page. Sessions that do not exist or belong to someone else have no
+// words.
+func (s *SessionService) Words(ctx context.Context, userID, id int64, keyset repository.Keyset) ([]models.SessionWord, repository.Cursor, error) {
+	_, err := ownSession(ctx, s.store, userID, id)
+	if err == ErrSessionNotFound {
+		return []models.SessionWord{}, repository.Cursor{}, nil
+	}
+	if err != nil {
+		return nil, repository.Cursor{}, err
+	}
+
+	return readPage(keyset,
+		func(keyset repository.Keyset) ([]models.SessionWord, error) {
+			return s.store.Reviews().ListBySession(ctx, id, keyset)
+		},
+		func(word models.SessionWord) (repository.Cursor, error) {
+			return repository.Cursor{Time: word.ReviewedAt, ID: word.ReviewID}, nil
+		})
+}
+
+// Start opens a new active session of an activity for a group.
+func (s *SessionService) Start(ctx context.Context, userID, groupID, activityID int64) (*models.StudySession, error) {
+	if _, err := s.store.Groups().Get(ctx, groupID); err != nil {
+		return nil, notFound(err, ErrGroupNotFound)
+	}
+	if _, err := s.store.Activities().Get(ctx, activityID); err != nil {
+		return nil, notFound(err, ErrActivityNotFound)
+	}
+
+	session := models.StudySession{
+		UserID:          userID,
+		GroupID:         groupID,
+		StudyActivityID: activityID,
+		Status:          models.SessionActive,
+	}
+	if err := s.store.Sessions().Create(ctx, &session); err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// End marks one of the user's active sessions as completed at now. It
+// returns models.ErrSessionClosed if the session has already ended.
+func (s *SessionService) End(ctx context.Context, userID, id int64, now time.Time) (*models.StudySessionDetail, error) {
+	var session *models.StudySessionDetail
+	err := s.store.WithTx(ctx, func(tx repository.Store) error {
+		if _, err := ownSession(ctx, tx, userID, id); err != nil {
+			return err
+		}
+		if err := tx.Sessions().End(ctx, id, now); err != nil {
+			return notFound(err, ErrSessionNotFound)
+		}
+
+		var err error
+		session, err = tx.Sessions().Get(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// CloseIdle marks active sessions whose last activity (their last review, or
+// their start if they have none) is older than idle as abandoned. It returns
+// the number of sessions closed.
+func (s *SessionService) CloseIdle(ctx context.Context, idle time.Duration, now time.Time) (int64, error) {
+	return s.store.Sessions().CloseIdle(ctx, now.Add(-idle))
+}
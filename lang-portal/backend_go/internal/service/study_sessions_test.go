@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionServiceStart(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	session, err := f.svc.Sessions.Start(ctx, f.user, f.group.ID, f.activity.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.SessionActive, session.Status)
+
+	_, err = f.svc.Sessions.Start(ctx, f.user, 999, f.activity.ID)
+	assert.ErrorIs(t, err, ErrGroupNotFound)
+
+	_, err = f.svc.Sessions.Start(ctx, f.user, f.group.ID, 999)
+	assert.ErrorIs(t, err, ErrActivityNotFound)
+}
+
+func TestSessionServiceEnd(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	session, err := f.svc.Sessions.End(ctx, f.user, f.session.ID, f.start.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, models.SessionCompleted, session.Status)
+	assert.NotNil(t, session.EndTime)
+
+	_, err = f.svc.Sessions.End(ctx, f.user, f.session.ID, f.start)
+	assert.ErrorIs(t, err, models.ErrSessionClosed)
+
+	_, err = f.svc.Sessions.End(ctx, f.user, 999, f.start)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+}
+
+func TestSessionServiceCloseIdle(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	// The session was last used ten minutes after it started
+	f.store.Now = func() time.Time { return f.start.Add(10 * time.Minute) }
+	_, _, err := f.svc.Reviews.Record(ctx, f.user, f.session.ID, f.words[0].ID, Answer{Correct: true}, f.start)
+	assert.NoError(t, err)
+
+	closed, err := f.svc.Sessions.CloseIdle(ctx, 30*time.Minute, f.start.Add(30*time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), closed)
+
+	closed, err = f.svc.Sessions.CloseIdle(ctx, 30*time.Minute, f.start.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), closed)
+
+	session, err := f.svc.Sessions.Get(ctx, f.user, f.session.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.SessionAbandoned, session.Status)
+	if assert.NotNil(t, session.EndTime) {
+		assert.Equal(t, f.start.Add(10*time.Minute).Format(time.RFC3339), *session.EndTime)
+	}
+}
+
+func TestSessionServiceList(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	f.store.Now = func() time.Time { return f.start.AddDate(0, 0, 1) }
+	later, err := f.svc.Sessions.Start(ctx, f.user, f.group.ID, f.activity.ID)
+	assert.NoError(t, err)
+
+	page := repository.ListOptions{Limit: 10}
+
+	// Newest first unless sorted
+	sessions, total, err := f.svc.Sessions.List(ctx, f.user, repository.SessionFilter{}, page)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+	if assert.Len(t, sessions, 2) {
+		assert.Equal(t, later.ID, sessions[0].ID)
+	}
+
+	sessions, _, err = f.svc.Sessions.List(ctx, f.user, repository.SessionFilter{}, repository.ListOptions{Limit: 10, Sort: "start_time"})
+	assert.NoError(t, err)
+	if assert.Len(t, sessions, 2) {
+		assert.Equal(t, f.session.ID, sessions[0].ID)
+	}
+
+	filter := repository.SessionFilter{From: f.start.Truncate(24 * time.Hour), To: f.start.Truncate(24*time.Hour).AddDate(0, 0, 1)}
+	sessions, total, err = f.svc.Sessions.List(ctx, f.user, filter, page)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	if assert.Len(t, sessions, 1) {
+		assert.Equal(t, f.session.ID, sessions[0].ID)
+	}
+
+	// Other users' sessions stay hidden whatever the filter asks for
+	sessions, _, err = f.svc.Sessions.List(ctx, 999, repository.SessionFilter{UserID: f.user}, page)
+	assert.NoError(t, err)
+	assert.Empty(t, sessions)
+}
+
+func TestSessionServicePages(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	// Three reviews within one second, one a second later
+	for i, word := range []models.Word{f.words[0], f.words[1], f.words[0], f.words[1]} {
+		at := f.start.Add(time.Duration(i/3) * time.Second)
+		f.store.Now = func() time.Time { return at }
+		_, _, err := f.svc.Reviews.Record(ctx, f.user, f.session.ID, word.ID, Answer{Correct: true}, f.start)
+		assert.NoError(t, err)
+	}
+
+	var reviewed []int64
+	keyset := repository.Keyset{Limit: 2}
+	for pages := 0; pages < 3; pages++ {
+		words, next, err := f.svc.Sessions.Words(ctx, f.user, f.session.ID, keyset)
+		assert.NoError(t, err)
+		for _, word := range words {
+			reviewed = append(reviewed, word.ID)
+		}
+		if next.IsZero() {
+			break
+		}
+		keyset.After = next
+	}
+	assert.Equal(t, []int64{f.words[0].ID, f.words[1].ID, f.words[0].ID, f.words[1].ID}, reviewed)
+
+	later, err := f.svc.Sessions.Start(ctx, f.user, f.group.ID, f.activity.ID)
+	assert.NoError(t, err)
+
+	sessions, next, err := f.svc.Sessions.ListAfter(ctx, f.user, repository.SessionFilter{}, repository.Keyset{Limit: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, sessions, 1) {
+		assert.Equal(t, later.ID, sessions[0].ID)
+	}
+	sessions, next, err = f.svc.Sessions.ListAfter(ctx, f.user, repository.SessionFilter{}, repository.Keyset{After: next, Limit: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, sessions, 1) {
+		assert.Equal(t, f.session.ID, sessions[0].ID)
+	}
+	assert.True(t, next.IsZero())
+}
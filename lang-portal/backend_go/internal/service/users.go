@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+)
+
+// ErrLastAdmin is returned when a change would leave no admin, whom only
+// the database could then restore.
+var ErrLastAdmin = errors.New("the last admin cannot lose the admin role")
+
+type UserService struct {
+	store repository.Store
+}
+
+// UserChanges holds the fields to change on a user. Nil fields are kept.
+type UserChanges struct {
+	Role     *string
+	Password *string
+}
+
+func (s *UserService) List(ctx context.Context) ([]models.User, error) {
+	return s.store.Users().List(ctx)
+}
+
+func (s *UserService) Get(ctx context.Context, id int64) (*models.User, error) {
+	user, err := s.store.Users().Get(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrUserNotFound)
+	}
+
+	return user, nil
+}
+
+func (s *UserService) FindByName(ctx context.Context, name string) (*models.User, error) {
+	user, err := s.store.Users().FindByName(ctx, name)
+	if err != nil {
+		return nil, notFound(err, ErrUserNotFound)
+	}
+
+	return user, nil
+}
+
+// Create adds a user with a password and role, student if empty. It returns
+// models.ErrDuplicateUserName if the name is already taken.
+func (s *UserService) Create(ctx context.Context, name, password, role string) (*models.User, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, invalid("User name must not be empty")
+	}
+
+	if role == "" {
+		role = models.RoleStudent
+	}
+	if !models.ValidRole(role) {
+		return nil, invalid("Role must be one of student, teacher, admin")
+	}
+
+	hash, err := hashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := models.User{Name: name, Role: role, PasswordHash: hash}
+	if err := s.store.Users().Create(ctx, &user); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// SetPassword changes a user's password and revokes their tokens.
+func (s *UserService) SetPassword(ctx context.Context, id int64, password string) error {
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	return s.store.WithTx(ctx, func(tx repository.Store) error {
+		if err := tx.Users().SetPassword(ctx, id, hash); err != nil {
+			return notFound(err, ErrUserNotFound)
+		}
+		return tx.Tokens().DeleteUser(ctx, id)
+	})
+}
+
+// SetRole changes a user's role. It returns ErrLastAdmin if the user is
+// the only admin and the role is another.
+func (s *UserService) SetRole(ctx context.Context, id int64, role string) (*models.User, error) {
+	return s.Update(ctx, id, UserChanges{Role: &role})
+}
+
+// Update changes a user's role and password together, revoking their tokens
+// if the password changes. Nothing changes unless every change is valid. It
+// returns ErrLastAdmin if the user is the only admin and the role is
+// another.
+func (s *UserService) Update(ctx context.Context, id int64, changes UserChanges) (*models.User, error) {
+	if changes.Role != nil && !models.ValidRole(*changes.Role) {
+		return nil, invalid("Role must be one of student, teacher, admin")
+	}
+	var hash string
+	if changes.Password != nil {
+		var err error
+		if hash, err = hashPassword(*changes.Password); err != nil {
+			return nil, err
+		}
+	}
+
+	var user *models.User
+	err := s.store.WithTx(ctx, func(tx repository.Store) error {
+		var err error
+		user, err = tx.Users().Get(ctx, id)
+		if err != nil {
+			return notFound(err, ErrUserNotFound)
+		}
+
+		if changes.Role != nil {
+			if user.Role == models.RoleAdmin && *changes.Role != models.RoleAdmin {
+				if err := keepAnAdmin(ctx, tx); err != nil {
+					return err
+				}
+			}
+			if err := tx.Users().SetRole(ctx, id, *changes.Role); err != nil {
+				return notFound(err, ErrUserNotFound)
+			}
+			user.Role = *changes.Role
+		}
+		if changes.Password != nil {
+			if err := tx.Users().SetPassword(ctx, id, hash); err != nil {
+				return notFound(err, ErrUserNotFound)
+			}
+			if err := tx.Tokens().DeleteUser(ctx, id); err != nil {
+				return err
+			}
+			user.PasswordHash = hash
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// keepAnAdmin returns ErrLastAdmin unless there is more than one admin, so
+// that one may lose the role.
+func keepAnAdmin(ctx context.Context, tx repository.Store) error {
+	users, err := tx.Users().List(ctx)
+	if err != nil {
+		return err
+	}
+
+	admins := 0
+	for _, user := range users {
+		if user.Role == models.RoleAdmin {
+			admins++
+		}
+	}
+	if admins <= 1 {
+		return ErrLastAdmin
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserServiceCreate(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	user, err := f.svc.Users.Create(ctx, "  hana ", "correct horse", "")
+	require.NoError(t, err)
+	assert.Equal(t, "hana", user.Name)
+	assert.Equal(t, models.RoleStudent, user.Role)
+	assert.NotEqual(t, "correct horse", user.PasswordHash)
+
+	_, err = f.svc.Users.Create(ctx, "hana", "correct horse", "")
+	assert.ErrorIs(t, err, models.ErrDuplicateUserName)
+
+	for _, tt := range []struct{ name, password, role string }{
+		{" ", "correct horse", ""},
+		{"kenji", "short", ""},
+		{"kenji", "correct horse", "principal"},
+	} {
+		_, err = f.svc.Users.Create(ctx, tt.name, tt.password, tt.role)
+		var invalid *ValidationError
+		assert.ErrorAs(t, err, &invalid, tt)
+	}
+
+	users, err := f.svc.Users.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, users, 2)
+
+	_, err = f.svc.Users.Get(ctx, 999)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+func TestUserServiceUpdate(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	hana, err := f.svc.Users.Create(ctx, "hana", "correct horse", "")
+	require.NoError(t, err)
+	login, err := f.svc.Auth.Login(ctx, "hana", "correct horse", f.start)
+	require.NoError(t, err)
+
+	// An invalid role leaves the password and tokens alone
+	password, role := "battery staple", "principal"
+	_, err = f.svc.Users.Update(ctx, hana.ID, UserChanges{Role: &role, Password: &password})
+	var invalid *ValidationError
+	assert.ErrorAs(t, err, &invalid)
+	_, err = f.svc.Auth.Authenticate(ctx, login.Token, f.start)
+	assert.NoError(t, err)
+
+	role = models.RoleAdmin
+	user, err := f.svc.Users.Update(ctx, hana.ID, UserChanges{Role: &role, Password: &password})
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleAdmin, user.Role)
+	_, err = f.svc.Auth.Authenticate(ctx, login.Token, f.start)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	// One of two admins may step down, the last one may not
+	user, err = f.svc.Users.SetRole(ctx, f.user, models.RoleTeacher)
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleTeacher, user.Role)
+	_, err = f.svc.Users.SetRole(ctx, hana.ID, models.RoleStudent)
+	assert.ErrorIs(t, err, ErrLastAdmin)
+	_, err = f.svc.Users.SetRole(ctx, hana.ID, models.RoleAdmin)
+	assert.NoError(t, err)
+
+	_, err = f.svc.Users.SetRole(ctx, 999, models.RoleStudent)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+func TestHistoryIsPerUser(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	_, _, err := f.svc.Reviews.Record(ctx, f.user, f.session.ID, f.words[0].ID, Answer{Correct: true}, f.start)
+	require.NoError(t, err)
+
+	other, err := f.svc.Users.Create(ctx, "hana", "correct horse", "")
+	require.NoError(t, err)
+
+	// Someone else's session cannot be seen, reviewed or ended
+	_, err = f.svc.Sessions.Get(ctx, other.ID, f.session.ID)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+	_, _, err = f.svc.Reviews.Record(ctx, other.ID, f.session.ID, f.words[0].ID, Answer{Correct: true}, f.start)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+	_, err = f.svc.Sessions.End(ctx, other.ID, f.session.ID, f.start)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+	words, _, err := f.svc.Sessions.Words(ctx, other.ID, f.session.ID, repository.Keyset{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, words)
+
+	stats, err := f.svc.Dashboard.QuickStats(ctx, other.ID)
+	require.NoError(t, err)
+	assert.Zero(t, stats.TotalStudySessions)
+	_, err = f.svc.Dashboard.LastSession(ctx, other.ID)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+
+	// Both users see the reviewed word on their own schedule
+	due, err := f.svc.Reviews.Due(ctx, other.ID, 0, 10, f.start)
+	require.NoError(t, err)
+	assert.Len(t, due, 2)
+	due, err = f.svc.Reviews.Due(ctx, f.user, 0, 10, f.start)
+	require.NoError(t, err)
+	assert.Len(t, due, 1)
+
+	// Resetting one user's history leaves the other's alone
+	session, err := f.svc.Sessions.Start(ctx, other.ID, f.group.ID, f.activity.ID)
+	require.NoError(t, err)
+	require.NoError(t, f.svc.Settings.ResetHistory(ctx, f.user))
+
+	_, err = f.svc.Sessions.Get(ctx, f.user, f.session.ID)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+	_, err = f.svc.Sessions.Get(ctx, other.ID, session.ID)
+	assert.NoError(t, err)
+}
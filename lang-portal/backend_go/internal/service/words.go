@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+	"lang-portal/backend_go/internal/textnorm"
+)
+
+type WordService struct {
+	store repository.Store
+}
+
+// WordDetail is a word with a user's review statistics and its groups.
+type WordDetail struct {
+	models.Word
+	Stats  models.WordStats
+	Groups []models.Group
+}
+
+// WordChanges holds the fields to change on a word. Nil fields are kept.
+type WordChanges struct {
+	Japanese *string
+	Romaji   *string
+	English  *string
+	Parts    *models.Parts
+}
+
+// List returns a page of the words matching query with the user's review
+// counts and the matching parts highlighted. Unless sorted the best matches
+// come first. Without a query it lists every word.
+func (s *WordService) List(ctx context.Context, userID int64, query string, opts repository.ListOptions) ([]models.WordMatch, int, error) {
+	words, total, err := s.store.Words().List(ctx, userID, query, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	terms := textnorm.Terms(query)
+	matches := make([]models.WordMatch, len(words))
+	for i, word := range words {
+		matches[i].WordSummary = word
+		if len(terms) == 0 {
+			continue
+		}
+
+		texts := map[textnorm.Field]string{
+			textnorm.Japanese: word.Japanese,
+			textnorm.Romaji:   word.Romaji,
+			textnorm.English:  word.English,
+		}
+		matches[i].Highlights = map[string]string{}
+		for _, field := range textnorm.Fields {
+			if highlighted, ok := textnorm.Highlight(field, texts[field], terms); ok {
+				matches[i].Highlights[field.String()] = highlighted
+			}
+		}
+	}
+	return matches, total, nil
+}
+
+func (s *WordService) Get(ctx context.Context, userID, id int64) (*WordDetail, error) {
+	words := s.store.Words()
+
+	word, err := words.Get(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrWordNotFound)
+	}
+
+	stats, err := words.Stats(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := words.Groups(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WordDetail{Word: *word, Stats: *stats, Groups: groups}, nil
+}
+
+// Reviews returns the page of the user's reviews of a word that follows
+// keyset.After, newest first, with the cursor of the next page.
+func (s *WordService) Reviews(ctx context.Context, userID, id int64, keyset repository.Keyset) ([]models.WordReview, repository.Cursor, error) {
+	if _, err := s.store.Words().Get(ctx, id); err != nil {
+		return nil, repository.Cursor{}, notFound(err, ErrWordNotFound)
+	}
+
+	return readPage(keyset,
+		func(keyset repository.Keyset) ([]models.WordReview, error) {
+			return s.store.Reviews().ListByWord(ctx, userID, id, keyset)
+		},
+		func(review models.WordReview) (repository.Cursor, error) {
+			return repository.Cursor{Time: review.CreatedAt, ID: review.ID}, nil
+		})
+}
+
+func (s *WordService) Create(ctx context.Context, word *models.Word) error {
+	if err := validateWord(word); err != nil {
+		return err
+	}
+
+	return s.store.Words().Create(ctx, word)
+}
+
+// Update applies changes to a word and returns the result.
+func (s *WordService) Update(ctx context.Context, id int64, changes WordChanges) (*models.Word, error) {
+	var word *models.Word
+	err := s.store.WithTx(ctx, func(tx repository.Store) error {
+		var err error
+		word, err = tx.Words().Get(ctx, id)
+		if err != nil {
+			return notFound(err, ErrWordNotFound)
+		}
+
+		fields := []struct {
+			value *string
+			dest  *string
+		}{
+			{changes.Japanese, &word.Japanese},
+			{changes.Romaji, &word.Romaji},
+			{changes.English, &word.English},
+		}
+		for _, field := range fields {
+			if field.value != nil {
+				*field.dest = *field.value
+			}
+		}
+		if changes.Parts != nil {
+			word.Parts = *changes.Parts
+		}
+
+		if err := validateWord(word); err != nil {
+			return err
+		}
+
+		return notFound(tx.Words().Update(ctx, word), ErrWordNotFound)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return word, nil
+}
+
+// Delete removes a word together with its group memberships, reviews and
+// schedule.
+func (s *WordService) Delete(ctx context.Context, id int64) error {
+	return notFound(s.store.Words().Delete(ctx, id), ErrWordNotFound)
+}
+
+// Export returns the words of a group, or of the whole library if groupID is
+// 0, with their group names and optionally the user's review statistics.
+func (s *WordService) Export(ctx context.Context, userID, groupID int64, withStats bool) ([]models.ExportWord, error) {
+	if groupID != 0 {
+		if _, err := s.store.Groups().Get(ctx, groupID); err != nil {
+			return nil, notFound(err, ErrGroupNotFound)
+		}
+	}
+
+	return s.store.Words().Export(ctx, userID, groupID, withStats)
+}
+
+func validateWord(word *models.Word) error {
+	fields := []struct {
+		name  string
+		value string
+	}{
+		{"japanese", word.Japanese},
+		{"romaji", word.Romaji},
+		{"english", word.English},
+	}
+	for _, field := range fields {
+		if field.value == "" {
+			return invalid("%s must not be empty", field.name)
+		}
+	}
+
+	if err := models.ValidateParts(word.Japanese, word.Parts); err != nil {
+		return invalid("%s", err)
+	}
+
+	return nil
+}
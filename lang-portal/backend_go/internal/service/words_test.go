@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWordServiceValidation(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	var invalid *ValidationError
+
+	parts := models.Parts{Segments: []models.Segment{{Kanji: "氷", Romaji: "koori"}}}
+	err := f.svc.Words.Create(ctx, &models.Word{Japanese: "水", Romaji: "mizu", English: "water", Parts: parts})
+	assert.True(t, errors.As(err, &invalid))
+	assert.Equal(t, `parts segments spell "氷" instead of "水"`, invalid.Message)
+
+	parts = models.Parts{Segments: []models.Segment{{Kanji: "食", Romaji: "ta"}, {Kanji: "べる"}}}
+	_, err = f.svc.Words.Update(ctx, f.words[0].ID, WordChanges{Parts: &parts})
+	assert.True(t, errors.As(err, &invalid))
+	assert.Equal(t, "parts segment 2 must have a kanji and a romaji", invalid.Message)
+
+	empty := ""
+	_, err = f.svc.Words.Update(ctx, f.words[0].ID, WordChanges{English: &empty})
+	assert.True(t, errors.As(err, &invalid))
+	assert.Equal(t, "english must not be empty", invalid.Message)
+
+	english := "good afternoon"
+	word, err := f.svc.Words.Update(ctx, f.words[0].ID, WordChanges{English: &english})
+	assert.NoError(t, err)
+	assert.Equal(t, "good afternoon", word.English)
+	assert.Equal(t, "konnichiwa", word.Romaji)
+
+	_, err = f.svc.Words.Update(ctx, 999, WordChanges{English: &english})
+	assert.ErrorIs(t, err, ErrWordNotFound)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestWordServiceSearch(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	page := repository.ListOptions{Limit: 10}
+
+	good := models.Word{Japanese: "良い", Romaji: "yoi", English: "good", Parts: models.Parts{Type: "adjective"}}
+	assert.NoError(t, f.svc.Words.Create(ctx, &good))
+
+	// The exact match ranks first, the English prefix match of goodbye next
+	matches, total, err := f.svc.Words.List(ctx, f.user, "Good", page)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+	if assert.Len(t, matches, 2) {
+		assert.Equal(t, good.ID, matches[0].ID)
+		assert.Equal(t, map[string]string{"english": "<mark>good</mark>"}, matches[0].Highlights)
+		assert.Equal(t, map[string]string{"english": "<mark>good</mark>bye"}, matches[1].Highlights)
+	}
+
+	matches, _, err = f.svc.Words.List(ctx, f.user, "サヨウナラ sayōnara", page)
+	assert.NoError(t, err)
+	if assert.Len(t, matches, 1) {
+		assert.Equal(t, map[string]string{
+			"japanese": "<mark>さようなら</mark>",
+			"romaji":   "<mark>sayounara</mark>",
+		}, matches[0].Highlights)
+	}
+
+	matches, total, err = f.svc.Words.List(ctx, f.user, "", page)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Nil(t, matches[0].Highlights)
+}
+
+func TestWordServiceDeleteCascades(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	_, _, err := f.svc.Reviews.Record(ctx, f.user, f.session.ID, f.words[0].ID, Answer{Correct: true}, f.start)
+	assert.NoError(t, err)
+
+	assert.NoError(t, f.svc.Words.Delete(ctx, f.words[0].ID))
+	assert.ErrorIs(t, f.svc.Words.Delete(ctx, f.words[0].ID), ErrWordNotFound)
+
+	_, stats, err := f.svc.Groups.Get(ctx, f.group.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, stats.TotalWordCount)
+
+	progress, err := f.svc.Dashboard.Progress(ctx, f.user)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, progress.TotalWordsStudied)
+	assert.Equal(t, 1, progress.TotalAvailableWords)
+}
+
+func TestWordServiceSort(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	_, _, err := f.svc.Reviews.Record(ctx, f.user, f.session.ID, f.words[1].ID, Answer{Correct: true}, f.start)
+	assert.NoError(t, err)
+
+	words, _, err := f.svc.Words.List(ctx, f.user, "", repository.ListOptions{Limit: 10, Sort: "correct_count", Desc: true})
+	assert.NoError(t, err)
+	if assert.Len(t, words, 2) {
+		assert.Equal(t, f.words[1].ID, words[0].ID)
+		assert.Equal(t, 1, words[0].CorrectCount)
+	}
+
+	words, _, err = f.svc.Words.List(ctx, f.user, "", repository.ListOptions{Limit: 10, Sort: "english"})
+	assert.NoError(t, err)
+	if assert.Len(t, words, 2) {
+		assert.Equal(t, "goodbye", words[0].English)
+	}
+
+	_, _, err = f.svc.Words.List(ctx, f.user, "", repository.ListOptions{Limit: 10, Sort: "parts"})
+	assert.Error(t, err)
+}
+
+func TestWordServiceReviews(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	var recorded []int64
+	for _, correct := range []bool{true, false, true} {
+		review, _, err := f.svc.Reviews.Record(ctx, f.user, f.session.ID, f.words[0].ID, Answer{Correct: correct}, f.start)
+		assert.NoError(t, err)
+		recorded = append(recorded, review.ID)
+	}
+
+	reviews, next, err := f.svc.Words.Reviews(ctx, f.user, f.words[0].ID, repository.Keyset{Limit: 2})
+	assert.NoError(t, err)
+	if assert.Len(t, reviews, 2) {
+		assert.Equal(t, recorded[2], reviews[0].ID)
+		assert.False(t, reviews[1].Correct)
+	}
+
+	reviews, next, err = f.svc.Words.Reviews(ctx, f.user, f.words[0].ID, repository.Keyset{After: next, Limit: 2})
+	assert.NoError(t, err)
+	if assert.Len(t, reviews, 1) {
+		assert.Equal(t, recorded[0], reviews[0].ID)
+	}
+	assert.True(t, next.IsZero())
+
+	reviews, _, err = f.svc.Words.Reviews(ctx, 999, f.words[0].ID, repository.Keyset{Limit: 2})
+	assert.NoError(t, err)
+	assert.Empty(t, reviews)
+
+	_, _, err = f.svc.Words.Reviews(ctx, f.user, 999, repository.Keyset{Limit: 2})
+	assert.ErrorIs(t, err, ErrWordNotFound)
+}
@@ -0,0 +1,564 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+)
+
+// DefaultXAPIBaseIRI is the address activity IRIs are built on until one is
+// configured.
+const DefaultXAPIBaseIRI = "http://localhost:4000"
+
+// ErrStatementConflict is returned for a statement whose ID is already
+// taken by a different statement.
+var ErrStatementConflict = errors.New("a different statement with this ID already exists")
+
+// Paths of the activity IRIs of words and study sessions under the base IRI.
+const (
+	wordActivityPath    = "/xapi/activities/words/"
+	sessionActivityPath = "/xapi/activities/study-sessions/"
+)
+
+// interactionType is the activity type of the words users answer.
+const interactionType = "http://adlnet.gov/expapi/activities/cmi.interaction"
+
+// durationPattern matches the ISO 8601 durations of answers, which take
+// hours at most.
+var durationPattern = regexp.MustCompile(`^PT(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?$`)
+
+var uuidPattern = regexp.MustCompile(`^(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// XAPIService is the Learning Record Store. Statements and state documents
+// belong to the user who sent them.
+type XAPIService struct {
+	store repository.Store
+
+	// BaseIRI is the address the portal is reached at. The IRIs of its
+	// activities and the home page of its users' accounts are built on it.
+	BaseIRI string
+}
+
+func (s *XAPIService) base() string {
+	return strings.TrimSuffix(s.BaseIRI, "/")
+}
+
+// WordIRI returns the activity IRI of a word.
+func (s *XAPIService) WordIRI(id int64) string {
+	return s.base() + wordActivityPath + strconv.FormatInt(id, 10)
+}
+
+// SessionIRI returns the activity IRI of a study session.
+func (s *XAPIService) SessionIRI(id int64) string {
+	return s.base() + sessionActivityPath + strconv.FormatInt(id, 10)
+}
+
+// activityID returns the ID in an activity IRI made by WordIRI or
+// SessionIRI, picked by path, and whether iri is one.
+func (s *XAPIService) activityID(iri, path string) (int64, bool) {
+	rest, ok := strings.CutPrefix(iri, s.base()+path)
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(rest, 10, 64)
+	return id, err == nil && id > 0
+}
+
+// Agent returns the agent a user appears as in statements: their account
+// on the portal.
+func (s *XAPIService) Agent(user *models.User) models.Agent {
+	return models.Agent{
+		ObjectType: "Agent",
+		Name:       user.Name,
+		Account:    &models.Account{HomePage: s.base(), Name: strconv.FormatInt(user.ID, 10)},
+	}
+}
+
+// Store validates the statements a user sent, stores them and applies them
+// to the portal:
+//   - answering a word's activity within a study session's activity, named
+//     as a parent or grouping context activity, records a review of the word
+//     like ReviewService.Record
+//   - completing a study session's activity ends the session
+//   - voiding a statement hides it from List
+//
+// Either all statements are stored or none. A statement whose ID is already
+// stored is skipped if it is the same and fails with ErrStatementConflict
+// otherwise. Store returns the IDs of the statements, assigning them where
+// missing.
+func (s *XAPIService) Store(ctx context.Context, user *models.User, statements []models.Statement, now time.Time) ([]string, error) {
+	if len(statements) == 0 {
+		return nil, invalid("no statements were sent")
+	}
+
+	now = now.UTC().Truncate(time.Millisecond)
+	ids := make([]string, len(statements))
+	seen := make(map[string]bool, len(statements))
+	err := s.store.WithTx(ctx, func(tx repository.Store) error {
+		for i, statement := range statements {
+			stored, err := s.prepare(statement, user, now)
+			if err != nil {
+				return statementError(i, err)
+			}
+			if seen[stored.StatementID] {
+				return invalid("statement %d: ID %s was sent twice", i+1, stored.StatementID)
+			}
+			seen[stored.StatementID] = true
+			ids[i] = stored.StatementID
+
+			existing, err := tx.XAPI().GetStatement(ctx, user.ID, stored.StatementID)
+			if err == nil {
+				if !sameStatement(existing.Statement, statement) {
+					return ErrStatementConflict
+				}
+				continue
+			}
+			if err != models.ErrNotFound {
+				return err
+			}
+
+			if err := s.apply(ctx, tx, user.ID, stored, now); err != nil {
+				return statementError(i, err)
+			}
+			err = tx.XAPI().CreateStatement(ctx, stored)
+			if err == models.ErrDuplicateStatement {
+				// Taken by another user's statement
+				return ErrStatementConflict
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// statementError prefixes validation errors with the position of the
+// statement in its batch. Records the statement refers to but that do not
+// exist make the statement invalid too.
+func statementError(i int, err error) error {
+	var validation *ValidationError
+	switch {
+	case errors.As(err, &validation):
+		return invalid("statement %d: %s", i+1, validation.Message)
+	case errors.Is(err, models.ErrNotFound):
+		return invalid("statement %d: %v", i+1, err)
+	}
+	return err
+}
+
+// prepare validates a statement and completes it with the fields the LRS
+// sets.
+func (s *XAPIService) prepare(statement models.Statement, user *models.User, now time.Time) (*models.StoredStatement, error) {
+	if statement.ID == "" {
+		id, err := newUUID()
+		if err != nil {
+			return nil, err
+		}
+		statement.ID = id
+	}
+	if !uuidPattern.MatchString(statement.ID) {
+		return nil, invalid("id must be a UUID")
+	}
+	statement.ID = strings.ToLower(statement.ID)
+
+	if statement.Actor.ObjectType != "" && statement.Actor.ObjectType != "Agent" {
+		return nil, invalid("actor must be an Agent, groups are not supported")
+	}
+	actorKey, err := statement.Actor.Key()
+	if err != nil {
+		return nil, invalid("actor: %v", err)
+	}
+
+	if !isIRI(statement.Verb.ID) {
+		return nil, invalid("verb id must be an IRI")
+	}
+
+	switch statement.Object.ObjectType {
+	case "", models.ObjectActivity:
+		if !isIRI(statement.Object.ID) {
+			return nil, invalid("object id must be an IRI")
+		}
+		if statement.Verb.ID == models.VerbVoided {
+			return nil, invalid("voiding statements must have a StatementRef object")
+		}
+	case models.ObjectStatementRef:
+		if statement.Verb.ID != models.VerbVoided {
+			return nil, invalid("only voiding statements may have a StatementRef object")
+		}
+		if !uuidPattern.MatchString(statement.Object.ID) {
+			return nil, invalid("object id must be the UUID of a statement")
+		}
+		statement.Object.ID = strings.ToLower(statement.Object.ID)
+	default:
+		return nil, invalid("object type %q is not supported", statement.Object.ObjectType)
+	}
+
+	var registration string
+	if statement.Context != nil && statement.Context.Registration != "" {
+		if !uuidPattern.MatchString(statement.Context.Registration) {
+			return nil, invalid("context registration must be a UUID")
+		}
+		registration = strings.ToLower(statement.Context.Registration)
+		withRegistration := *statement.Context
+		withRegistration.Registration = registration
+		statement.Context = &withRegistration
+	}
+
+	switch {
+	case statement.Version == "":
+		statement.Version = models.XAPIVersion
+	case statement.Version != "1.0" && !strings.HasPrefix(statement.Version, "1.0."):
+		return nil, invalid("version %q is not supported", statement.Version)
+	}
+
+	if statement.Timestamp == nil {
+		statement.Timestamp = &now
+	}
+	authority := s.Agent(user)
+	statement.Stored, statement.Authority = &now, &authority
+
+	return &models.StoredStatement{
+		StatementID:  statement.ID,
+		UserID:       user.ID,
+		ActorKey:     actorKey,
+		VerbID:       statement.Verb.ID,
+		ObjectID:     statement.Object.ID,
+		Registration: registration,
+		Stored:       now,
+		Statement:    statement,
+	}, nil
+}
+
+// apply makes the changes to the portal a new statement stands for.
+func (s *XAPIService) apply(ctx context.Context, tx repository.Store, userID int64, stored *models.StoredStatement, now time.Time) error {
+	statement := stored.Statement
+
+	switch statement.Verb.ID {
+	case models.VerbVoided:
+		target, err := tx.XAPI().GetStatement(ctx, userID, statement.Object.ID)
+		if err == models.ErrNotFound {
+			// Voiding statements are stored even if their target is unknown
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if target.VerbID == models.VerbVoided {
+			return invalid("voiding statements cannot be voided")
+		}
+		return tx.XAPI().VoidStatement(ctx, userID, target.StatementID)
+
+	case models.VerbAnswered:
+		wordID, ok := s.activityID(statement.Object.ID, wordActivityPath)
+		if !ok {
+			return nil
+		}
+		sessionID, ok := s.contextSession(statement.Context)
+		if !ok {
+			return invalid("answers to words must name their study session as a parent or grouping context activity")
+		}
+		if statement.Result == nil || statement.Result.Success == nil {
+			return invalid("answers to words must have a result.success")
+		}
+
+		answer := Answer{
+			Correct:     *statement.Result.Success,
+			AnswerGiven: statement.Result.Response,
+			ResponseMS:  durationMS(statement.Result.Duration),
+		}
+		review, err := answer.review(userID, sessionID, wordID)
+		if err != nil {
+			return err
+		}
+		_, _, err = recordReview(ctx, tx, &review, now)
+		return err
+
+	case models.VerbCompleted:
+		sessionID, ok := s.activityID(statement.Object.ID, sessionActivityPath)
+		if !ok {
+			return nil
+		}
+		session, err := ownSession(ctx, tx, userID, sessionID)
+		if err != nil {
+			return err
+		}
+		if session.Status != models.SessionActive {
+			return nil
+		}
+		return tx.Sessions().End(ctx, sessionID, now)
+	}
+
+	return nil
+}
+
+// contextSession returns the ID of the study session named among the
+// parent or grouping activities of a statement's context.
+func (s *XAPIService) contextSession(statementContext *models.Context) (int64, bool) {
+	if statementContext == nil || statementContext.ContextActivities == nil {
+		return 0, false
+	}
+
+	contextActivities := statementContext.ContextActivities
+	activities := append(append(models.Objects{}, contextActivities.Parent...), contextActivities.Grouping...)
+	for _, activity := range activities {
+		if id, ok := s.activityID(activity.ID, sessionActivityPath); ok {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// durationMS returns the milliseconds of an xAPI duration of hours, minutes
+// and seconds, such as PT1M2.5S, or nil for anything else.
+func durationMS(duration string) *int64 {
+	match := durationPattern.FindStringSubmatch(duration)
+	if match == nil || duration == "PT" {
+		return nil
+	}
+
+	var seconds float64
+	for i, unit := range []float64{3600, 60, 1} {
+		if match[i+1] != "" {
+			value, _ := strconv.ParseFloat(match[i+1], 64)
+			seconds += value * unit
+		}
+	}
+	ms := int64(math.Round(seconds * 1000))
+	return &ms
+}
+
+// emitReview stores the statement of a review recorded through the portal's
+// own API, so that the LRS holds every review.
+func (s *XAPIService) emitReview(ctx context.Context, tx repository.Store, review *models.WordReview, word *models.Word, now time.Time) error {
+	user, err := tx.Users().Get(ctx, review.UserID)
+	if err != nil {
+		return err
+	}
+
+	definition, err := json.Marshal(map[string]any{
+		"type": interactionType,
+		"name": map[string]string{"ja": word.Japanese, "en-US": word.English},
+	})
+	if err != nil {
+		return err
+	}
+
+	correct := review.Correct
+	result := &models.Result{Success: &correct, Response: review.AnswerGiven}
+	if review.ResponseMS != nil {
+		result.Duration = "PT" + strconv.FormatFloat(float64(*review.ResponseMS)/1000, 'f', -1, 64) + "S"
+	}
+	stored, err := s.prepare(models.Statement{
+		Actor: s.Agent(user),
+		Verb: models.Verb{
+			ID:      models.VerbAnswered,
+			Display: map[string]string{"en-US": "answered"},
+		},
+		Object: models.Object{
+			ObjectType: models.ObjectActivity,
+			ID:         s.WordIRI(word.ID),
+			Definition: definition,
+		},
+		Result: result,
+		Context: &models.Context{
+			ContextActivities: &models.ContextActivities{
+				Parent: models.Objects{{ObjectType: models.ObjectActivity, ID: s.SessionIRI(review.StudySessionID)}},
+			},
+		},
+		Timestamp: &review.CreatedAt,
+	}, user, now.UTC().Truncate(time.Millisecond))
+	if err != nil {
+		return err
+	}
+
+	return tx.XAPI().CreateStatement(ctx, stored)
+}
+
+// Get returns one of the user's statements. Voided statements are only
+// returned when asked for, and the others only when not.
+func (s *XAPIService) Get(ctx context.Context, userID int64, statementID string, voided bool) (*models.Statement, error) {
+	stored, err := s.store.XAPI().GetStatement(ctx, userID, strings.ToLower(statementID))
+	if err != nil {
+		return nil, notFound(err, ErrStatementNotFound)
+	}
+	if stored.Voided != voided {
+		return nil, ErrStatementNotFound
+	}
+
+	return &stored.Statement, nil
+}
+
+// List returns the page of the user's statements matching filter that
+// follows keyset.After, newest first unless ascending, with the cursor of
+// the next page. Voided statements are left out.
+func (s *XAPIService) List(ctx context.Context, userID int64, filter repository.StatementFilter, keyset repository.Keyset) ([]models.Statement, repository.Cursor, error) {
+	filter.UserID = userID
+	filter.Registration = strings.ToLower(filter.Registration)
+
+	page, next, err := readPage(keyset,
+		func(keyset repository.Keyset) ([]models.StoredStatement, error) {
+			return s.store.XAPI().ListStatements(ctx, filter, keyset)
+		},
+		func(statement models.StoredStatement) (repository.Cursor, error) {
+			return repository.Cursor{Time: statement.Stored, ID: statement.ID}, nil
+		})
+	if err != nil {
+		return nil, repository.Cursor{}, err
+	}
+
+	statements := make([]models.Statement, len(page))
+	for i, stored := range page {
+		statements[i] = stored.Statement
+	}
+	return statements, next, nil
+}
+
+// checkStateKey validates the activity and registration of a state key and
+// brings the registration into its stored form.
+func checkStateKey(key *repository.StateKey) error {
+	if !isIRI(key.ActivityID) {
+		return invalid("activityId must be an IRI")
+	}
+	if key.Registration != "" && !uuidPattern.MatchString(key.Registration) {
+		return invalid("registration must be a UUID")
+	}
+	key.Registration = strings.ToLower(key.Registration)
+	return nil
+}
+
+// State returns the state document key names.
+func (s *XAPIService) State(ctx context.Context, key repository.StateKey) (*models.XAPIState, error) {
+	if err := checkStateKey(&key); err != nil {
+		return nil, err
+	}
+	if key.StateID == "" {
+		return nil, invalid("stateId is required")
+	}
+
+	state, err := s.store.XAPI().GetState(ctx, key)
+	if err != nil {
+		return nil, notFound(err, ErrStateNotFound)
+	}
+	return state, nil
+}
+
+// StateIDs returns the IDs of the state documents of key's activity, agent
+// and registration updated after since, which may be zero.
+func (s *XAPIService) StateIDs(ctx context.Context, key repository.StateKey, since time.Time) ([]string, error) {
+	if err := checkStateKey(&key); err != nil {
+		return nil, err
+	}
+	return s.store.XAPI().StateIDs(ctx, key, since)
+}
+
+// SaveState stores a state document at now, replacing the document there
+// was unless merge. Merging sets the properties of a JSON object document
+// to those of the new one, which must be a JSON object too.
+func (s *XAPIService) SaveState(ctx context.Context, key repository.StateKey, contentType string, document []byte, merge bool, now time.Time) error {
+	if err := checkStateKey(&key); err != nil {
+		return err
+	}
+	if key.StateID == "" {
+		return invalid("stateId is required")
+	}
+
+	state := models.XAPIState{
+		UserID:       key.UserID,
+		ActivityID:   key.ActivityID,
+		AgentKey:     key.AgentKey,
+		Registration: key.Registration,
+		StateID:      key.StateID,
+		ContentType:  contentType,
+		Document:     document,
+		UpdatedAt:    now.UTC(),
+	}
+	if !merge {
+		return s.store.XAPI().SaveState(ctx, &state)
+	}
+
+	var update map[string]json.RawMessage
+	if json.Unmarshal(document, &update) != nil || update == nil {
+		return invalid("only JSON objects can be merged into a state document")
+	}
+
+	return s.store.WithTx(ctx, func(tx repository.Store) error {
+		existing, err := tx.XAPI().GetState(ctx, key)
+		if err == models.ErrNotFound {
+			return tx.XAPI().SaveState(ctx, &state)
+		}
+		if err != nil {
+			return err
+		}
+
+		var merged map[string]json.RawMessage
+		if json.Unmarshal(existing.Document, &merged) != nil || merged == nil {
+			return invalid("the stored state document is not a JSON object and cannot be merged into")
+		}
+		for name, value := range update {
+			merged[name] = value
+		}
+		if state.Document, err = json.Marshal(merged); err != nil {
+			return err
+		}
+		return tx.XAPI().SaveState(ctx, &state)
+	})
+}
+
+// DeleteState deletes the state documents key stands for.
+func (s *XAPIService) DeleteState(ctx context.Context, key repository.StateKey) error {
+	if err := checkStateKey(&key); err != nil {
+		return err
+	}
+	return s.store.XAPI().DeleteState(ctx, key)
+}
+
+// sameStatement reports whether a stored statement is the one sent again,
+// ignoring the fields the LRS set.
+func sameStatement(stored, sent models.Statement) bool {
+	if sent.Timestamp == nil {
+		stored.Timestamp = nil
+	}
+	if sent.Version == "" {
+		stored.Version = ""
+	}
+	stored.Stored, stored.Authority = nil, nil
+	sent.Stored, sent.Authority = nil, nil
+	stored.ID, sent.ID = "", ""
+
+	a, errA := json.Marshal(stored)
+	b, errB := json.Marshal(sent)
+	return errA == nil && errB == nil && string(a) == string(b)
+}
+
+func isIRI(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != "" && u.Opaque+u.Host+u.Path != ""
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// answer is a statement of the user answering a word during a session.
+func (f *fixture) answer(id string, wordID, sessionID int64, correct bool) models.Statement {
+	return models.Statement{
+		ID:     id,
+		Actor:  models.Agent{Mbox: "mailto:hana@example.com"},
+		Verb:   models.Verb{ID: models.VerbAnswered},
+		Object: models.Object{ID: f.svc.XAPI.WordIRI(wordID)},
+		Result: &models.Result{Success: &correct},
+		Context: &models.Context{
+			ContextActivities: &models.ContextActivities{
+				Parent: models.Objects{{ID: f.svc.XAPI.SessionIRI(sessionID)}},
+			},
+		},
+	}
+}
+
+func TestXAPIServiceStore(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	user, err := f.svc.Users.Get(ctx, f.user)
+	require.NoError(t, err)
+
+	countReviews := func() int {
+		words, _, err := f.svc.Sessions.Words(ctx, f.user, f.session.ID, repository.Keyset{Limit: 10})
+		require.NoError(t, err)
+		return len(words)
+	}
+
+	// Answers become reviews
+	const answerID = "5f2b7c1e-8a3d-4e6f-9b0c-1d2e3f4a5b6c"
+	ids, err := f.svc.XAPI.Store(ctx, user, []models.Statement{f.answer(answerID, f.words[0].ID, f.session.ID, true)}, f.start)
+	require.NoError(t, err)
+	assert.Equal(t, []string{answerID}, ids)
+	assert.Equal(t, 1, countReviews())
+	schedule, err := f.store.Reviews().GetSchedule(ctx, f.user, f.words[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, schedule.Repetitions)
+
+	// And reviews recorded through the API become statements
+	_, _, err = f.svc.Reviews.Record(ctx, f.user, f.session.ID, f.words[1].ID, Answer{Correct: false}, f.start)
+	require.NoError(t, err)
+	statements, next, err := f.svc.XAPI.List(ctx, f.user, repository.StatementFilter{}, repository.Keyset{Limit: 10})
+	require.NoError(t, err)
+	assert.True(t, next.IsZero())
+	if assert.Len(t, statements, 2) {
+		emitted := statements[0]
+		assert.Equal(t, f.svc.XAPI.Agent(user), emitted.Actor)
+		assert.Equal(t, f.svc.XAPI.WordIRI(f.words[1].ID), emitted.Object.ID)
+		assert.JSONEq(t, `{"type":"http://adlnet.gov/expapi/activities/cmi.interaction","name":{"ja":"さようなら","en-US":"goodbye"}}`, string(emitted.Object.Definition))
+		assert.False(t, *emitted.Result.Success)
+		assert.Equal(t, models.XAPIVersion, emitted.Version)
+		assert.Equal(t, answerID, statements[1].ID)
+	}
+
+	// Sending a statement again is a no-op, changing it a conflict
+	_, err = f.svc.XAPI.Store(ctx, user, []models.Statement{f.answer(answerID, f.words[0].ID, f.session.ID, true)}, f.start)
+	require.NoError(t, err)
+	assert.Equal(t, 2, countReviews())
+	_, err = f.svc.XAPI.Store(ctx, user, []models.Statement{f.answer(answerID, f.words[0].ID, f.session.ID, false)}, f.start)
+	assert.ErrorIs(t, err, ErrStatementConflict)
+
+	// A bad statement fails its whole batch
+	withoutSession := f.answer("", f.words[0].ID, f.session.ID, true)
+	withoutSession.Context = nil
+	_, err = f.svc.XAPI.Store(ctx, user, []models.Statement{f.answer("", f.words[0].ID, f.session.ID, true), withoutSession}, f.start)
+	var validation *ValidationError
+	if assert.ErrorAs(t, err, &validation) {
+		assert.Contains(t, validation.Message, "statement 2: answers to words must name their study session")
+	}
+	_, err = f.svc.XAPI.Store(ctx, user, []models.Statement{f.answer("", 999, f.session.ID, true)}, f.start)
+	if assert.ErrorAs(t, err, &validation) {
+		assert.Equal(t, "statement 1: word not found", validation.Message)
+	}
+	assert.Equal(t, 2, countReviews())
+
+	// Voided statements drop out of listings
+	_, err = f.svc.XAPI.Store(ctx, user, []models.Statement{{
+		Actor:  models.Agent{Mbox: "mailto:hana@example.com"},
+		Verb:   models.Verb{ID: models.VerbVoided},
+		Object: models.Object{ObjectType: models.ObjectStatementRef, ID: answerID},
+	}}, f.start)
+	require.NoError(t, err)
+	_, err = f.svc.XAPI.Get(ctx, f.user, answerID, false)
+	assert.ErrorIs(t, err, ErrStatementNotFound)
+	voided, err := f.svc.XAPI.Get(ctx, f.user, answerID, true)
+	require.NoError(t, err)
+	assert.Equal(t, answerID, voided.ID)
+	statements, _, err = f.svc.XAPI.List(ctx, f.user, repository.StatementFilter{ObjectID: f.svc.XAPI.WordIRI(f.words[0].ID)}, repository.Keyset{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, statements)
+
+	// Completing the session's activity ends the session
+	_, err = f.svc.XAPI.Store(ctx, user, []models.Statement{{
+		Actor:  models.Agent{Mbox: "mailto:hana@example.com"},
+		Verb:   models.Verb{ID: models.VerbCompleted},
+		Object: models.Object{ID: f.svc.XAPI.SessionIRI(f.session.ID)},
+	}}, f.start)
+	require.NoError(t, err)
+	session, err := f.svc.Sessions.Get(ctx, f.user, f.session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.SessionCompleted, session.Status)
+
+	_, err = f.svc.XAPI.Store(ctx, user, []models.Statement{f.answer("", f.words[0].ID, f.session.ID, true)}, f.start)
+	assert.ErrorIs(t, err, models.ErrSessionClosed)
+}
+
+func TestXAPIServiceValidation(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	user, err := f.svc.Users.Get(ctx, f.user)
+	require.NoError(t, err)
+
+	valid := func() models.Statement {
+		return models.Statement{
+			Actor:  models.Agent{Mbox: "mailto:hana@example.com"},
+			Verb:   models.Verb{ID: "http://adlnet.gov/expapi/verbs/experienced"},
+			Object: models.Object{ID: "http://example.com/lessons/1"},
+		}
+	}
+	tests := []struct {
+		name   string
+		change func(*models.Statement)
+		want   string
+	}{
+		{"Bad ID", func(s *models.Statement) { s.ID = "42" }, "id must be a UUID"},
+		{"Two identifiers", func(s *models.Statement) { s.Actor.OpenID = "http://hana.example.com" }, "actor: agent must have exactly one"},
+		{"Group actor", func(s *models.Statement) { s.Actor.ObjectType = "Group" }, "groups are not supported"},
+		{"Relative verb", func(s *models.Statement) { s.Verb.ID = "experienced" }, "verb id must be an IRI"},
+		{"Sub-statement", func(s *models.Statement) { s.Object.ObjectType = "SubStatement" }, `object type "SubStatement" is not supported`},
+		{"Void an activity", func(s *models.Statement) { s.Verb.ID = models.VerbVoided }, "must have a StatementRef object"},
+		{"Bad registration", func(s *models.Statement) { s.Context = &models.Context{Registration: "first"} }, "registration must be a UUID"},
+		{"Version 2", func(s *models.Statement) { s.Version = "2.0.0" }, `version "2.0.0" is not supported`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statement := valid()
+			tt.change(&statement)
+			_, err := f.svc.XAPI.Store(ctx, user, []models.Statement{statement}, f.start)
+			var validation *ValidationError
+			if assert.ErrorAs(t, err, &validation) {
+				assert.Contains(t, validation.Message, tt.want)
+			}
+		})
+	}
+
+	// Statements about other activities are only stored
+	ids, err := f.svc.XAPI.Store(ctx, user, []models.Statement{valid()}, f.start)
+	require.NoError(t, err)
+	stored, err := f.svc.XAPI.Get(ctx, f.user, ids[0], false)
+	require.NoError(t, err)
+	assert.Equal(t, f.start, *stored.Stored)
+	assert.Equal(t, f.start, *stored.Timestamp)
+	assert.Equal(t, f.svc.XAPI.Agent(user), *stored.Authority)
+}
+
+func TestXAPIServiceState(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	key := repository.StateKey{
+		UserID:     f.user,
+		ActivityID: "http://example.com/lessons/1",
+		AgentKey:   "mbox:mailto:hana@example.com",
+		StateID:    "progress",
+	}
+	require.NoError(t, f.svc.XAPI.SaveState(ctx, key, "application/json", []byte(`{"card":3,"deck":"n5"}`), false, f.start))
+	require.NoError(t, f.svc.XAPI.SaveState(ctx, key, "application/json", []byte(`{"card":4}`), true, f.start))
+	state, err := f.svc.XAPI.State(ctx, key)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"card":4,"deck":"n5"}`, string(state.Document))
+
+	// Only JSON objects merge
+	notes := key
+	notes.StateID = "notes"
+	require.NoError(t, f.svc.XAPI.SaveState(ctx, notes, "text/plain", []byte("remember kanji"), false, f.start))
+	var validation *ValidationError
+	assert.ErrorAs(t, f.svc.XAPI.SaveState(ctx, notes, "application/json", []byte(`{"page":2}`), true, f.start), &validation)
+	assert.ErrorAs(t, f.svc.XAPI.SaveState(ctx, key, "application/json", []byte(`[1]`), true, f.start), &validation)
+
+	ids, err := f.svc.XAPI.StateIDs(ctx, key, f.start.Add(-1))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"notes", "progress"}, ids)
+
+	bad := key
+	bad.Registration = "first"
+	_, err = f.svc.XAPI.State(ctx, bad)
+	assert.ErrorAs(t, err, &validation)
+
+	require.NoError(t, f.svc.XAPI.DeleteState(ctx, key))
+	_, err = f.svc.XAPI.State(ctx, key)
+	assert.ErrorIs(t, err, ErrStateNotFound)
+}
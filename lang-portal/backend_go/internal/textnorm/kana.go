@@ -0,0 +1,130 @@
+package textnorm
+
+import "strings"
+
+// Syllables pairs hiragana with its Hepburn romaji. Digraphs come before
+// the kana they start with, so that the longest match wins.
+var Syllables = [][2]string{
+	{"きゃ", "kya"}, {"きゅ", "kyu"}, {"きょ", "kyo"},
+	{"ぎゃ", "gya"}, {"ぎゅ", "gyu"}, {"ぎょ", "gyo"},
+	{"しゃ", "sha"}, {"しゅ", "shu"}, {"しぇ", "she"}, {"しょ", "sho"},
+	{"じゃ", "ja"}, {"じゅ", "ju"}, {"じぇ", "je"}, {"じょ", "jo"},
+	{"ちゃ", "cha"}, {"ちゅ", "chu"}, {"ちぇ", "che"}, {"ちょ", "cho"},
+	{"にゃ", "nya"}, {"にゅ", "nyu"}, {"にょ", "nyo"},
+	{"ひゃ", "hya"}, {"ひゅ", "hyu"}, {"ひょ", "hyo"},
+	{"びゃ", "bya"}, {"びゅ", "byu"}, {"びょ", "byo"},
+	{"ぴゃ", "pya"}, {"ぴゅ", "pyu"}, {"ぴょ", "pyo"},
+	{"みゃ", "mya"}, {"みゅ", "myu"}, {"みょ", "myo"},
+	{"りゃ", "rya"}, {"りゅ", "ryu"}, {"りょ", "ryo"},
+	{"ふぁ", "fa"}, {"ふぃ", "fi"}, {"ふぇ", "fe"}, {"ふぉ", "fo"},
+	{"てぃ", "ti"}, {"でぃ", "di"},
+	{"あ", "a"}, {"い", "i"}, {"う", "u"}, {"え", "e"}, {"お", "o"},
+	{"か", "ka"}, {"き", "ki"}, {"く", "ku"}, {"け", "ke"}, {"こ", "ko"},
+	{"が", "ga"}, {"ぎ", "gi"}, {"ぐ", "gu"}, {"げ", "ge"}, {"ご", "go"},
+	{"さ", "sa"}, {"し", "shi"}, {"す", "su"}, {"せ", "se"}, {"そ", "so"},
+	{"ざ", "za"}, {"じ", "ji"}, {"ず", "zu"}, {"ぜ", "ze"}, {"ぞ", "zo"},
+	{"た", "ta"}, {"ち", "chi"}, {"つ", "tsu"}, {"て", "te"}, {"と", "to"},
+	{"だ", "da"}, {"ぢ", "ji"}, {"づ", "zu"}, {"で", "de"}, {"ど", "do"},
+	{"な", "na"}, {"に", "ni"}, {"ぬ", "nu"}, {"ね", "ne"}, {"の", "no"},
+	{"は", "ha"}, {"ひ", "hi"}, {"ふ", "fu"}, {"へ", "he"}, {"ほ", "ho"},
+	{"ば", "ba"}, {"び", "bi"}, {"ぶ", "bu"}, {"べ", "be"}, {"ぼ", "bo"},
+	{"ぱ", "pa"}, {"ぴ", "pi"}, {"ぷ", "pu"}, {"ぺ", "pe"}, {"ぽ", "po"},
+	{"ま", "ma"}, {"み", "mi"}, {"む", "mu"}, {"め", "me"}, {"も", "mo"},
+	{"や", "ya"}, {"ゆ", "yu"}, {"よ", "yo"},
+	{"ら", "ra"}, {"り", "ri"}, {"る", "ru"}, {"れ", "re"}, {"ろ", "ro"},
+	{"わ", "wa"}, {"ゐ", "i"}, {"ゑ", "e"}, {"を", "o"},
+	{"ゔ", "vu"},
+	{"ぁ", "a"}, {"ぃ", "i"}, {"ぅ", "u"}, {"ぇ", "e"}, {"ぉ", "o"},
+	{"ゃ", "ya"}, {"ゅ", "yu"}, {"ょ", "yo"}, {"ゎ", "wa"},
+}
+
+// kanaRomaji maps each kana of Syllables to its romaji.
+var kanaRomaji = map[string]string{}
+
+func init() {
+	for _, s := range Syllables {
+		kanaRomaji[s[0]] = s[1]
+	}
+}
+
+// The most runes a kana of Syllables takes.
+const longestKana = 2
+
+// Romanize spells the hiragana in folded text in Hepburn romaji. A long
+// vowel mark repeats the vowel before it, and characters that are not
+// hiragana are kept.
+func Romanize(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	double := false
+	for i := 0; i < len(runes); {
+		switch runes[i] {
+		case 'っ':
+			double = true
+			i++
+			continue
+		case 'ん':
+			b.WriteByte('n')
+			i++
+			continue
+		case 'ー':
+			if out := b.String(); out != "" && isVowel(rune(out[len(out)-1])) {
+				b.WriteByte(out[len(out)-1])
+			}
+			i++
+			continue
+		}
+
+		var romaji string
+		n := min(longestKana, len(runes)-i)
+		for ; n > 0; n-- {
+			if r, ok := kanaRomaji[string(runes[i:i+n])]; ok {
+				romaji = r
+				break
+			}
+		}
+		if romaji == "" {
+			b.WriteRune(runes[i])
+			double = false
+			i++
+			continue
+		}
+
+		if double {
+			// Hepburn doubles the ch of "cchi" as tch
+			if strings.HasPrefix(romaji, "ch") {
+				b.WriteByte('t')
+			} else if !isVowel(rune(romaji[0])) {
+				b.WriteByte(romaji[0])
+			}
+			double = false
+		}
+		b.WriteString(romaji)
+		i += n
+	}
+	return b.String()
+}
+
+func isVowel(r rune) bool {
+	return strings.ContainsRune("aiueo", r)
+}
+
+// halfWidth holds the hiragana, or the full-width punctuation and marks, of
+// the half-width katakana block from ｡ (U+FF61) to ﾟ (U+FF9F) in order.
+var halfWidth = []rune("。「」、・をぁぃぅぇぉゃゅょっーあいうえおかきくけこさしすせそたちつてとなにぬねのはひふへほまみむめもやゆよらりるれろわん゛゜")
+
+// voiced maps kana followed by a voiced sound mark, as half-width katakana
+// write them, to the voiced kana.
+var voiced = map[[2]rune]rune{}
+
+func init() {
+	for mark, kana := range map[rune][2]string{
+		'゛': {"かきくけこさしすせそたちつてとはひふへほう", "がぎぐげござじずぜぞだぢづでどばびぶべぼゔ"},
+		'゜': {"はひふへほ", "ぱぴぷぺぽ"},
+	} {
+		marked := []rune(kana[1])
+		for i, r := range []rune(kana[0]) {
+			voiced[[2]rune{r, mark}] = marked[i]
+		}
+	}
+}
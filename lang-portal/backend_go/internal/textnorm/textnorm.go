@@ -0,0 +1,284 @@
+// Package textnorm folds the different ways learners write the same word
+// into one search form. Katakana, full-width or half-width, is read as
+// hiragana, full-width Latin letters and digits as ASCII, upper case as lower
+// case and, in romaji, long vowels spelled ō, ô, oo or ou as a single o. Kana
+// in a query is also read as romaji, so that にほん finds the word whose
+// romaji is "nihon".
+//
+// The word search stores the Key of each field and compares it with the
+// Terms of a query. The store keeps the keys in a search index, so changing
+// how text is folded needs a migration that rebuilds the index.
+package textnorm
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Field is a searchable word field. Each field is folded its own way.
+type Field int
+
+const (
+	Japanese Field = iota
+	Romaji
+	English
+)
+
+// Fields lists every searchable field.
+var Fields = []Field{Japanese, Romaji, English}
+
+func (f Field) String() string {
+	switch f {
+	case Japanese:
+		return "japanese"
+	case Romaji:
+		return "romaji"
+	default:
+		return "english"
+	}
+}
+
+// Key returns the search form of a field's text: its folded tokens joined by
+// single spaces. Tokens are runs of ASCII letters and digits or non-ASCII
+// characters, like the tokens of SQLite's simple full-text tokenizer.
+// Japanese is written without spaces, so every character of it is a token
+// of its own and a phrase of them matches anywhere in the text.
+func (f Field) Key(s string) string {
+	runes := f.fold(s).runes
+	if f == Japanese {
+		chars := make([]string, len(runes))
+		for i, r := range runes {
+			chars[i] = string(r)
+		}
+		return strings.Join(chars, " ")
+	}
+	return strings.Join(strings.FieldsFunc(string(runes), isSpace), " ")
+}
+
+// Term is one word of a search query, folded for each field.
+type Term struct {
+	keys [3]string
+}
+
+// NewTerm folds s for every field. The romaji key spells kana in romaji.
+func NewTerm(s string) Term {
+	var t Term
+	for _, f := range Fields {
+		t.keys[f] = f.Key(s)
+	}
+	t.keys[Romaji] = Romaji.Key(Romanize(Fold(s)))
+	return t
+}
+
+// Terms splits a query into terms at white space. Words without a single
+// letter or digit are dropped, so a query of only punctuation has no terms.
+func Terms(query string) []Term {
+	terms := []Term{}
+	for _, word := range strings.Fields(query) {
+		if t := NewTerm(word); t.keys[Japanese] != "" {
+			terms = append(terms, t)
+		}
+	}
+	return terms
+}
+
+// Key returns the term's search form for field f.
+func (t Term) Key(f Field) string {
+	return t.keys[f]
+}
+
+// Matches reports whether a field with the search form key matches the
+// term. Japanese matches the term anywhere, romaji and English match it at
+// the start of a token, so "tabe" finds "taberu".
+func (t Term) Matches(f Field, key string) bool {
+	if t.keys[f] == "" {
+		return false
+	}
+	if f == Japanese {
+		return strings.Contains(" "+key+" ", " "+t.keys[f]+" ")
+	}
+	return strings.Contains(" "+key, " "+t.keys[f])
+}
+
+// Rank orders a field with the search form key against a query folded into
+// a single term: 0 when the field is the query, 1 when it starts with the
+// query and 2 otherwise.
+func (t Term) Rank(f Field, key string) int {
+	switch {
+	case t.keys[f] == "":
+		return 2
+	case key == t.keys[f]:
+		return 0
+	case strings.HasPrefix(key, t.keys[f]):
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Highlight returns text HTML-escaped, with the parts the terms match
+// wrapped in <mark> tags, and whether any part matched.
+func Highlight(f Field, text string, terms []Term) (string, bool) {
+	folded := f.fold(text)
+
+	var spans [][2]int
+	for _, term := range terms {
+		pattern := []rune(term.keys[f])
+		if f == Japanese {
+			// The folded text of a Japanese field has no separators
+			pattern = []rune(strings.ReplaceAll(term.keys[f], " ", ""))
+		}
+		if len(pattern) == 0 {
+			continue
+		}
+
+		for i := 0; i+len(pattern) <= len(folded.runes); i++ {
+			if f != Japanese && i > 0 && folded.runes[i-1] != ' ' {
+				continue
+			}
+			if string(folded.runes[i:i+len(pattern)]) == string(pattern) {
+				spans = append(spans, [2]int{folded.start[i], folded.end[i+len(pattern)-1]})
+			}
+		}
+	}
+	if len(spans) == 0 {
+		return html.EscapeString(text), false
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+
+	runes := []rune(text)
+	var b strings.Builder
+	pos := 0
+	for i := 0; i < len(spans); i++ {
+		start, end := spans[i][0], spans[i][1]
+		// Merge overlapping and adjacent matches into one mark
+		for i+1 < len(spans) && spans[i+1][0] <= end {
+			end = max(end, spans[i+1][1])
+			i++
+		}
+		b.WriteString(html.EscapeString(string(runes[pos:start])))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(string(runes[start:end])))
+		b.WriteString("</mark>")
+		pos = end
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:])))
+
+	return b.String(), true
+}
+
+// folded is text in search form before it is split into tokens. Each rune
+// remembers which runes of the original text it came from.
+type folded struct {
+	runes []rune
+	// start and end delimit the original runes, end exclusive
+	start, end []int
+}
+
+func (f Field) fold(s string) folded {
+	var out folded
+	for i, r := range []rune(s) {
+		r = foldRune(r)
+		n := len(out.runes)
+
+		if n > 0 && out.end[n-1] == i {
+			if kana, ok := voiced[[2]rune{out.runes[n-1], r}]; ok {
+				out.runes[n-1] = kana
+				out.end[n-1] = i + 1
+				continue
+			}
+		}
+
+		if f == Romaji {
+			if plain, ok := longVowels[r]; ok {
+				r = plain
+			}
+			if n > 0 && extendsVowel(out.runes[n-1], r) {
+				out.end[n-1] = i + 1
+				continue
+			}
+		}
+
+		if isSeparator(r) {
+			if f == Japanese {
+				continue
+			}
+			r = ' '
+			if n > 0 && out.runes[n-1] == ' ' {
+				out.end[n-1] = i + 1
+				continue
+			}
+		}
+
+		out.runes = append(out.runes, r)
+		out.start = append(out.start, i)
+		out.end = append(out.end, i+1)
+	}
+	return out
+}
+
+// Fold maps text rune by rune with foldRune and joins kana and the voiced
+// sound mark after them into the voiced kana, as every field does.
+func Fold(s string) string {
+	var out []rune
+	for _, r := range s {
+		r = foldRune(r)
+		if n := len(out); n > 0 {
+			if kana, ok := voiced[[2]rune{out[n-1], r}]; ok {
+				out[n-1] = kana
+				continue
+			}
+		}
+		out = append(out, r)
+	}
+	return string(out)
+}
+
+// foldRune maps full-width ASCII to ASCII, katakana, full-width or
+// half-width, to hiragana and upper case to lower case. The voiced sound
+// marks of half-width katakana become full-width ones, which Fold joins to
+// the kana before them.
+func foldRune(r rune) rune {
+	switch {
+	case r >= '！' && r <= '～':
+		r -= '！' - '!'
+	case r == '　': // ideographic space
+		r = ' '
+	case r >= 'ァ' && r <= 'ヶ', r == 'ヽ', r == 'ヾ':
+		r -= 'ァ' - 'ぁ'
+	case r >= '｡' && r <= 'ﾟ':
+		r = halfWidth[r-'｡']
+	}
+	return unicode.ToLower(r)
+}
+
+// longVowels maps vowels marked long in Hepburn and Kunrei romaji to the
+// plain vowel.
+var longVowels = map[rune]rune{
+	'ā': 'a', 'ī': 'i', 'ū': 'u', 'ē': 'e', 'ō': 'o',
+	'â': 'a', 'î': 'i', 'û': 'u', 'ê': 'e', 'ô': 'o',
+}
+
+// extendsVowel reports whether r after prev spells a long vowel, as in "aa",
+// "uu" or "ou". The "ei" of "sensei" is read as written.
+func extendsVowel(prev, r rune) bool {
+	switch r {
+	case 'a', 'i', 'u', 'e', 'o':
+		return prev == r || prev == 'o' && r == 'u'
+	default:
+		return false
+	}
+}
+
+// isSeparator reports whether r separates tokens: ASCII other than letters
+// and digits.
+func isSeparator(r rune) bool {
+	return r < 0x80 && !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+}
+
+func isSpace(r rune) bool {
+	return r == ' '
+}
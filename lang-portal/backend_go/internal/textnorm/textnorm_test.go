@@ -0,0 +1,162 @@
+package textnorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		field Field
+		text  string
+		want  string
+	}{
+		{Japanese, "食べる", "食 べ る"},
+		{Japanese, "コーヒー", "こ ー ひ ー"},
+		{Japanese, "ｶﾀｶﾅ", "か た か な"},
+		{Japanese, "ｺｰﾋｰ", "こ ー ひ ー"},
+		{Japanese, "ｶﾞｯｺｳ", "が っ こ う"},
+		{Japanese, "ﾊﾟﾝ｡", "ぱ ん 。"},
+		{Japanese, "ｳﾞｧｲｵﾘﾝ", "ゔ ぁ い お り ん"},
+		{Japanese, "ﾞｱﾞ", "゛ あ ゛"},
+		{Japanese, "Ｔシャツ", "t し ゃ つ"},
+		{Japanese, "お 早う、ございます", "お 早 う 、 ご ざ い ま す"},
+		{Romaji, "Tōkyō", "tokyo"},
+		{Romaji, "toukyou", "tokyo"},
+		{Romaji, "tookyoo", "tokyo"},
+		{Romaji, "TÔKYÔ", "tokyo"},
+		{Romaji, "kūki", "kuki"},
+		{Romaji, "oishii", "oishi"},
+		{Romaji, "sensei", "sensei"},
+		{Romaji, "ｋｏｎｎｉｃｈｉｗａ", "konnichiwa"},
+		{Romaji, "o-u", "o u"},
+		{Romaji, "ﾆﾎﾝ", "にほん"},
+		{English, "Thank  you!", "thank you"},
+		{English, "to eat; to have a meal", "to eat to have a meal"},
+		{English, "good", "good"},
+		{English, "...", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field.String()+"/"+tt.text, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.field.Key(tt.text))
+		})
+	}
+}
+
+func TestTerms(t *testing.T) {
+	terms := Terms("  タベ　tōkyō -- ")
+	if assert.Len(t, terms, 2) {
+		assert.Equal(t, "た べ", terms[0].Key(Japanese))
+		assert.Equal(t, "tokyo", terms[1].Key(Romaji))
+		assert.Equal(t, "tōkyō", terms[1].Key(English))
+	}
+
+	assert.Empty(t, Terms("!?"))
+
+	// Kana is read as romaji too
+	terms = Terms("ｺｰﾋｰ にほん 食べる")
+	if assert.Len(t, terms, 3) {
+		assert.Equal(t, "こ ー ひ ー", terms[0].Key(Japanese))
+		assert.Equal(t, "kohi", terms[0].Key(Romaji))
+		assert.Equal(t, "nihon", terms[1].Key(Romaji))
+		assert.Equal(t, "食beru", terms[2].Key(Romaji))
+	}
+}
+
+func TestRomanize(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"たべる", "taberu"},
+		{"にほん", "nihon"},
+		{"きって", "kitte"},
+		{"まっちゃ", "matcha"},
+		{"しゃしん", "shashin"},
+		{"こーひー", "koohii"},
+		{"食べる", "食beru"},
+		{"tokyo", "tokyo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			assert.Equal(t, tt.want, Romanize(tt.text))
+		})
+	}
+}
+
+func TestFold(t *testing.T) {
+	assert.Equal(t, "がっこう", Fold("ｶﾞｯｺｳ"))
+	assert.Equal(t, "ぱん", Fold("ハ゜ン"))
+	assert.Equal(t, "hon'ya", Fold("ＨＯＮ＇ｙａ"))
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		query string
+		field Field
+		text  string
+		want  bool
+	}{
+		{"べ物", Japanese, "食べ物を食べる", true},
+		{"ベル", Japanese, "食べる", true},
+		{"ﾍﾞﾙ", Japanese, "食べる", true},
+		{"たべる", Romaji, "taberu", true},
+		{"にほん", Romaji, "nihon", true},
+		{"ｺｰﾋｰ", Romaji, "kōhī", true},
+		{"べた", Japanese, "食べる", false},
+		{"tabe", Romaji, "taberu", true},
+		{"beru", Romaji, "taberu", false},
+		{"ou", Romaji, "ōkii", true},
+		{"toukyou", Romaji, "Tōkyō eki", true},
+		{"eki", Romaji, "Tōkyō eki", true},
+		{"to have", English, "to eat; to have a meal", true},
+		{"have to", English, "to eat; to have a meal", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query+"/"+tt.text, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewTerm(tt.query).Matches(tt.field, tt.field.Key(tt.text)))
+		})
+	}
+}
+
+func TestRank(t *testing.T) {
+	query := NewTerm("Taberu")
+	assert.Equal(t, 0, query.Rank(Romaji, Romaji.Key("taberu")))
+	assert.Equal(t, 1, query.Rank(Romaji, Romaji.Key("taberu mono")))
+	assert.Equal(t, 2, query.Rank(Romaji, Romaji.Key("tabemono wo taberu")))
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   Field
+		text    string
+		query   string
+		want    string
+		matched bool
+	}{
+		{"kana folding", Japanese, "コーヒーを飲む", "こーひー", "<mark>コーヒー</mark>を飲む", true},
+		{"half-width kana", Japanese, "ｶﾞｯｺｳへ行く", "がっこう", "<mark>ｶﾞｯｺｳ</mark>へ行く", true},
+		{"kana as romaji", Romaji, "nihon", "にほん", "<mark>nihon</mark>", true},
+		{"every match", Japanese, "食べ物を食べる", "食べ", "<mark>食べ</mark>物を<mark>食べ</mark>る", true},
+		{"long vowel", Romaji, "Tōkyō eki", "toukyou", "<mark>Tōkyō</mark> eki", true},
+		{"doubled vowel", Romaji, "toukyou", "tōkyō", "<mark>toukyou</mark>", true},
+		{"token start", English, "to eat; to have a meal", "ea", "to <mark>ea</mark>t; to have a meal", true},
+		{"several terms", English, "to eat; to have a meal", "meal eat", "to <mark>eat</mark>; to have a <mark>meal</mark>", true},
+		{"overlapping terms", English, "thank you", "than thank", "<mark>thank</mark> you", true},
+		{"escaped", English, "<b>&</b> fish", "fish", "&lt;b&gt;&amp;&lt;/b&gt; <mark>fish</mark>", true},
+		{"no match", English, "hello", "bye", "hello", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, matched := Highlight(tt.field, tt.text, Terms(tt.query))
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.matched, matched)
+		})
+	}
+}
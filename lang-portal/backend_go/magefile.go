@@ -2,24 +2,56 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"math/rand"
 	"os"
 	"path/filepath"
-	"sort"
-	"strings"
 	"time"
 
-	_ "github.com/mattn/go-sqlite3"
+	"lang-portal/backend_go/internal/models"
+	"lang-portal/backend_go/internal/repository"
+	"lang-portal/backend_go/internal/repository/sqlstore"
+	"lang-portal/backend_go/internal/service"
 )
 
 const dbName = "words.db"
 
-// InitDB initializes the SQLite database
+// openDB connects to the database selected by DB_DRIVER and DATABASE_URL,
+// defaulting to the local SQLite file.
+func openDB() (*sql.DB, sqlstore.Dialect, error) {
+	driver := os.Getenv("DB_DRIVER")
+	if driver == "" {
+		driver = sqlstore.SQLite.Name
+	}
+	dsn := os.Getenv("DATABASE_URL")
+	if dsn == "" {
+		dsn = dbName
+	}
+
+	dialect, err := sqlstore.DialectFor(driver)
+	if err != nil {
+		return nil, dialect, err
+	}
+
+	db, err := sql.Open(dialect.Driver, dsn)
+	if err != nil {
+		return nil, dialect, fmt.Errorf("error opening database: %v", err)
+	}
+	return db, dialect, nil
+}
+
+// InitDB initializes the SQLite database. PostgreSQL databases are created
+// by the server administrator.
 func InitDB() error {
 	fmt.Println("Initializing database...")
+
+	if driver := os.Getenv("DB_DRIVER"); driver != "" && driver != sqlstore.SQLite.Name {
+		fmt.Printf("Nothing to do for %s\n", driver)
+		return nil
+	}
 	
 	if _, err := os.Stat(dbName); err == nil {
 		fmt.Printf("Database %s already exists\n", dbName)
@@ -36,105 +68,70 @@ func InitDB() error {
 	return nil
 }
 
-// Migrate runs all database migrations
+// Migrate applies the pending database migrations. The server binary does
+// the same with "server migrate".
 func Migrate() error {
 	fmt.Println("Running migrations...")
 
-	db, err := sql.Open("sqlite3", dbName)
+	db, dialect, err := openDB()
 	if err != nil {
-		return fmt.Errorf("error opening database: %v", err)
+		return err
 	}
 	defer db.Close()
 
-	// Create migrations table if it doesn't exist
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS migrations (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			name TEXT NOT NULL UNIQUE,
-			applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		)
-	`)
-	if err != nil {
-		return fmt.Errorf("error creating migrations table: %v", err)
+	applied, err := sqlstore.New(db, dialect).Migrate(context.Background())
+	for _, name := range applied {
+		fmt.Printf("Applied %s\n", name)
 	}
+	return err
+}
 
-	// Get list of migration files
-	files, err := filepath.Glob("db/migrations/*.sql")
+// CreateUser adds an account that can log in, e.g.
+// mage createUser sato "correct horse" teacher
+func CreateUser(name, password, role string) error {
+	db, dialect, err := openDB()
 	if err != nil {
-		return fmt.Errorf("error finding migration files: %v", err)
+		return err
 	}
-	sort.Strings(files)
-
-	// Run each migration
-	for _, file := range files {
-		name := filepath.Base(file)
-		var exists bool
-		err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM migrations WHERE name = ?)", name).Scan(&exists)
-		if err != nil {
-			return fmt.Errorf("error checking migration status: %v", err)
-		}
-
-		if exists {
-			fmt.Printf("Skipping %s (already applied)\n", name)
-			continue
-		}
-
-		content, err := os.ReadFile(file)
-		if err != nil {
-			return fmt.Errorf("error reading migration file %s: %v", name, err)
-		}
-
-		tx, err := db.Begin()
-		if err != nil {
-			return fmt.Errorf("error starting transaction: %v", err)
-		}
-
-		// Split the content into individual statements
-		statements := strings.Split(string(content), ";")
-		for _, stmt := range statements {
-			stmt = strings.TrimSpace(stmt)
-			if stmt == "" {
-				continue
-			}
+	defer db.Close()
 
-			_, err = tx.Exec(stmt)
-			if err != nil {
-				tx.Rollback()
-				return fmt.Errorf("error executing migration %s: %v", name, err)
-			}
-		}
+	svc := service.New(sqlstore.New(db, dialect))
+	user, err := svc.Users.Create(context.Background(), name, password, role)
+	if err != nil {
+		return fmt.Errorf("error creating user: %v", err)
+	}
 
-		_, err = tx.Exec("INSERT INTO migrations (name) VALUES (?)", name)
-		if err != nil {
-			tx.Rollback()
-			return fmt.Errorf("error recording migration %s: %v", name, err)
-		}
+	fmt.Printf("Created %s %s with ID %d\n", user.Role, user.Name, user.ID)
+	return nil
+}
 
-		err = tx.Commit()
-		if err != nil {
-			return fmt.Errorf("error committing migration %s: %v", name, err)
-		}
+// SetPassword sets the password of an existing user and logs them out
+// everywhere. Use it to give the default admin a password after migrating.
+func SetPassword(name, password string) error {
+	db, dialect, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
 
-		fmt.Printf("Applied %s\n", name)
+	svc := service.New(sqlstore.New(db, dialect))
+	user, err := svc.Users.FindByName(context.Background(), name)
+	if err != nil {
+		return fmt.Errorf("error finding user %s: %v", name, err)
+	}
+	if err := svc.Users.SetPassword(context.Background(), user.ID, password); err != nil {
+		return fmt.Errorf("error setting password: %v", err)
 	}
 
+	fmt.Printf("Password set for %s\n", user.Name)
 	return nil
 }
 
 // SeedWord represents a word in our seed files
-type SeedWord struct {
-	Japanese string          `json:"japanese"`
-	Romaji   string         `json:"romaji"`
-	English  string         `json:"english"`
-	Parts    map[string]any `json:"parts"`
-}
+type SeedWord = models.SeedWord
 
-// SeedFile represents the structure of our seed files
-type StudyActivity struct {
-	Name         string `json:"name"`
-	ThumbnailURL string `json:"thumbnail_url"`
-	Description  string `json:"description"`
-}
+// StudyActivity is the manifest of an activity in config.json
+type StudyActivity = service.ActivityManifest
 
 type ConfigFile struct {
 	Groups          []struct {
@@ -144,18 +141,16 @@ type ConfigFile struct {
 	StudyActivities []StudyActivity `json:"study_activities"`
 }
 
-type SeedFile struct {
-	GroupName string     `json:"group_name"`
-	Words     []SeedWord `json:"words"`
-}
+// SeedFile represents the structure of our seed files
+type SeedFile = models.SeedFile
 
 // Seed loads initial data into the database
 func Seed() error {
 	fmt.Println("Seeding database...")
 
-	db, err := sql.Open("sqlite3", dbName)
+	db, dialect, err := openDB()
 	if err != nil {
-		return fmt.Errorf("error opening database: %v", err)
+		return err
 	}
 	defer db.Close()
 
@@ -170,37 +165,17 @@ func Seed() error {
 		return fmt.Errorf("error parsing config.json: %v", err)
 	}
 
-	// Begin transaction for study activities
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("error starting transaction: %v", err)
-	}
-
-	// Clear existing study activities
-	_, err = tx.Exec("DELETE FROM study_activities")
-	if err != nil {
-		tx.Rollback()
-		return fmt.Errorf("error clearing study activities: %v", err)
-	}
-
-	// Insert study activities
+	// Register the study activities, updating those registered before under
+	// the same slug. Activities added through the API are kept.
+	svc := service.New(sqlstore.New(db, dialect))
 	for _, activity := range config.StudyActivities {
-		_, err = tx.Exec(`
-			INSERT INTO study_activities (name, description, thumbnail_url)
-			VALUES (?, ?, ?)
-		`, activity.Name, activity.Description, activity.ThumbnailURL)
-		if err != nil {
-			tx.Rollback()
-			return fmt.Errorf("error inserting study activity: %v", err)
+		if _, err := svc.Activities.Register(context.Background(), activity); err != nil {
+			return fmt.Errorf("error registering study activity %s: %v", activity.Name, err)
 		}
 	}
 
-	if err = tx.Commit(); err != nil {
-		return fmt.Errorf("error committing study activities: %v", err)
-	}
-
 	// Add random study sessions and word review items
-	tx, err = db.Begin()
+	tx, err := db.Begin()
 	if err != nil {
 		return fmt.Errorf("error starting transaction for study sessions: %v", err)
 	}
@@ -283,11 +258,11 @@ func Seed() error {
 
 		// Insert study session
 		var sessionID int64
-		err = tx.QueryRow(`
+		err = tx.QueryRow(dialect.Rebind(`
 			INSERT INTO study_sessions (group_id, study_activity_id, created_at)
 			VALUES (?, ?, ?)
 			RETURNING id
-		`, groupID, activityID, createdAt).Scan(&sessionID)
+		`), groupID, activityID, createdAt).Scan(&sessionID)
 
 		if err != nil {
 			tx.Rollback()
@@ -300,10 +275,10 @@ func Seed() error {
 			wordID := wordIDs[rand.Intn(len(wordIDs))]
 			correct := rand.Float32() < 0.7 // 70% chance of correct answer
 
-			_, err = tx.Exec(`
-				INSERT INTO word_review_items (word_id, study_session_id, correct, created_at)
-				VALUES (?, ?, ?, ?)
-			`, wordID, sessionID, correct, createdAt)
+			_, err = tx.Exec(dialect.Rebind(`
+				INSERT INTO word_review_items (word_id, study_session_id, correct, quality, created_at)
+				VALUES (?, ?, ?, ?, ?)
+			`), wordID, sessionID, correct, models.QualityFromCorrect(correct), createdAt)
 
 			if err != nil {
 				tx.Rollback()
@@ -345,13 +320,13 @@ func Seed() error {
 		// Create or get group
 		var groupID int64
 		err = tx.QueryRow(
-			"INSERT OR IGNORE INTO groups (name) VALUES (?) RETURNING id",
+			dialect.Rebind("INSERT INTO groups (name) VALUES (?) ON CONFLICT DO NOTHING RETURNING id"),
 			seedFile.GroupName,
 		).Scan(&groupID)
 		
 		if err == sql.ErrNoRows {
 			err = tx.QueryRow(
-				"SELECT id FROM groups WHERE name = ?",
+				dialect.Rebind("SELECT id FROM groups WHERE name = ?"),
 				seedFile.GroupName,
 			).Scan(&groupID)
 		}
@@ -370,21 +345,22 @@ func Seed() error {
 			}
 
 			var wordID int64
-			err = tx.QueryRow(`
+			err = tx.QueryRow(dialect.Rebind(`
 				INSERT INTO words (japanese, romaji, english, parts)
 				VALUES (?, ?, ?, ?)
 				RETURNING id
-			`, word.Japanese, word.Romaji, word.English, partsJSON).Scan(&wordID)
+			`), word.Japanese, word.Romaji, word.English, string(partsJSON)).Scan(&wordID)
 			
 			if err != nil {
 				tx.Rollback()
 				return fmt.Errorf("error inserting word: %v", err)
 			}
 
-			_, err = tx.Exec(`
-				INSERT OR IGNORE INTO word_groups (word_id, group_id)
+			_, err = tx.Exec(dialect.Rebind(`
+				INSERT INTO word_groups (word_id, group_id)
 				VALUES (?, ?)
-			`, wordID, groupID)
+				ON CONFLICT DO NOTHING
+			`), wordID, groupID)
 			
 			if err != nil {
 				tx.Rollback()
@@ -394,8 +370,9 @@ func Seed() error {
 
 		// Create default study activity if it doesn't exist
 		_, err = tx.Exec(`
-			INSERT OR IGNORE INTO study_activities (name, description)
-			VALUES ('Vocabulary Quiz', 'Practice your vocabulary with flashcards')
+			INSERT INTO study_activities (slug, name, description)
+			VALUES ('vocabulary-quiz', 'Vocabulary Quiz', 'Practice your vocabulary with flashcards')
+			ON CONFLICT DO NOTHING
 		`)
 		if err != nil {
 			tx.Rollback()
@@ -409,5 +386,43 @@ func Seed() error {
 		fmt.Printf("Successfully processed %s\n", filepath.Base(file))
 	}
 
-	return nil
+	// Kanji last, so that they are linked to the words seeded above
+	return seedKanji(db, dialect)
+}
+
+// seedKanji stores the kanji of db/seeds/kanji/kanji.json that are missing.
+func seedKanji(db *sql.DB, dialect sqlstore.Dialect) error {
+	content, err := os.ReadFile("db/seeds/kanji/kanji.json")
+	if err != nil {
+		return fmt.Errorf("error reading kanji seed file: %v", err)
+	}
+
+	var file struct {
+		Kanji []models.Kanji `json:"kanji"`
+	}
+	if err := json.Unmarshal(content, &file); err != nil {
+		return fmt.Errorf("error parsing kanji seed file: %v", err)
+	}
+
+	ctx := context.Background()
+	return sqlstore.New(db, dialect).WithTx(ctx, func(tx repository.Store) error {
+		added := 0
+		for _, kanji := range file.Kanji {
+			_, err := tx.Kanji().Get(ctx, kanji.Character)
+			if err == nil {
+				continue
+			}
+			if err != models.ErrNotFound {
+				return err
+			}
+
+			if err := tx.Kanji().Create(ctx, &kanji); err != nil {
+				return fmt.Errorf("error inserting kanji %s: %v", kanji.Character, err)
+			}
+			added++
+		}
+
+		fmt.Printf("Added %d kanji\n", added)
+		return nil
+	})
 } 
\ No newline at end of file